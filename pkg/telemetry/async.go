@@ -0,0 +1,491 @@
+package telemetry
+
+import (
+	"bytes"
+	"io"
+	"maps"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultAsyncBufferSize is used by NewAsyncDriver if the provided buffer size is not positive
+const DefaultAsyncBufferSize = 1024
+
+// AsyncDriver wraps a driver and applies the operations of its transactions on a background goroutine.
+// Operations of a single transaction are applied in the order they were called.
+// Segment, attribute and log operations are queued and return immediately. They are dropped if the buffer is full.
+// Trace and process id methods as well as Flush wait for all previously queued operations and are never dropped.
+// Done drains the buffer before the transaction of the wrapped driver is finished
+type AsyncDriver struct {
+	inner      Driver
+	bufferSize int
+	dropped    atomic.Uint64
+}
+
+// asyncTransaction queues the operations for the transaction of the wrapped driver
+type asyncTransaction struct {
+	inner   Transaction
+	driver  *AsyncDriver
+	ops     chan func() error
+	drained chan struct{}
+
+	mu      sync.Mutex
+	closed  bool
+	senders sync.WaitGroup
+
+	errMu sync.Mutex
+	errs  ErrorWrapper
+}
+
+// NewAsyncDriver returns an *AsyncDriver which decouples the calls to inner from the caller
+func NewAsyncDriver(inner Driver, bufferSize int) Driver {
+	if bufferSize <= 0 {
+		bufferSize = DefaultAsyncBufferSize
+	}
+
+	return &AsyncDriver{
+		inner:      inner,
+		bufferSize: bufferSize,
+	}
+}
+
+// Dropped returns the number of operations dropped because the buffer was full
+func (d *AsyncDriver) Dropped() uint64 {
+	return d.dropped.Load()
+}
+
+// InitializeTransaction initializes the transaction of the wrapped driver and starts its background goroutine
+func (d *AsyncDriver) InitializeTransaction(name string) (Transaction, error) {
+	transaction, err := d.inner.InitializeTransaction(name)
+	if err != nil {
+		return nil, err
+	}
+
+	at := &asyncTransaction{
+		inner:   transaction,
+		driver:  d,
+		ops:     make(chan func() error, d.bufferSize),
+		drained: make(chan struct{}),
+	}
+
+	go at.run()
+
+	return at, nil
+}
+
+// run applies the queued operations until the queue is closed
+func (at *asyncTransaction) run() {
+	defer close(at.drained)
+
+	for op := range at.ops {
+		err := safeCall(op)
+		if err != nil {
+			at.errMu.Lock()
+			at.errs.Add(err)
+			at.errMu.Unlock()
+		}
+	}
+}
+
+// enqueue queues op without blocking and counts it as dropped if the buffer is full.
+// In synchronous mode it waits until op is applied instead
+func (at *asyncTransaction) enqueue(op func() error) {
+	if Synchronous() {
+		err := at.call(op)
+		if err != nil {
+			at.errMu.Lock()
+			at.errs.Add(err)
+			at.errMu.Unlock()
+		}
+
+		return
+	}
+
+	at.mu.Lock()
+	defer at.mu.Unlock()
+
+	if at.closed {
+		at.driver.dropped.Add(1)
+		return
+	}
+
+	select {
+	case at.ops <- op:
+	default:
+		at.driver.dropped.Add(1)
+	}
+}
+
+// call queues op behind all pending operations and waits for its result.
+// The lock is released before the blocking send, close waits for the pending sends before closing the queue
+func (at *asyncTransaction) call(op func() error) error {
+	result := make(chan error, 1)
+
+	at.mu.Lock()
+	if at.closed {
+		at.mu.Unlock()
+		return op()
+	}
+	at.senders.Add(1)
+	at.mu.Unlock()
+
+	at.ops <- func() error {
+		result <- safeCall(op)
+		return nil
+	}
+	at.senders.Done()
+
+	return <-result
+}
+
+// close stops accepting operations and waits until the queued ones are applied
+func (at *asyncTransaction) close() error {
+	at.mu.Lock()
+	closing := !at.closed
+	at.closed = true
+	at.mu.Unlock()
+
+	if closing {
+		at.senders.Wait()
+		close(at.ops)
+	}
+
+	<-at.drained
+
+	at.errMu.Lock()
+	defer at.errMu.Unlock()
+
+	return at.errs.Error()
+}
+
+// readAll drains rc on the calling goroutine so the caller may reuse the underlying data
+func readAll(rc io.ReadCloser) (io.ReadCloser, error) {
+	defer rc.Close()
+
+	content, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, err
+	}
+
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+// Start queues starting the transaction with the name
+func (at *asyncTransaction) Start(name string) {
+	at.enqueue(func() error {
+		at.inner.Start(name)
+		return nil
+	})
+}
+
+// SetName queues renaming the transaction, errors are returned by Done
+func (at *asyncTransaction) SetName(name string) error {
+	at.enqueue(func() error {
+		return at.inner.SetName(name)
+	})
+
+	return nil
+}
+
+// AddTransactionAttribute queues adding the attribute to the transaction, errors are returned by Done
+func (at *asyncTransaction) AddTransactionAttribute(key string, value any) error {
+	at.enqueue(func() error {
+		return at.inner.AddTransactionAttribute(key, value)
+	})
+
+	return nil
+}
+
+// SegmentStart queues starting the segment, errors are returned by Done
+func (at *asyncTransaction) SegmentStart(segmentID string, name string) error {
+	at.enqueue(func() error {
+		return at.inner.SegmentStart(segmentID, name)
+	})
+
+	return nil
+}
+
+// SegmentStartChild queues starting the segment as child of the parent segment, errors are returned by Done
+func (at *asyncTransaction) SegmentStartChild(parentID string, segmentID string, name string) error {
+	at.enqueue(func() error {
+		return at.inner.SegmentStartChild(parentID, segmentID, name)
+	})
+
+	return nil
+}
+
+// AddSegmentAttribute queues adding the attribute to the segment, errors are returned by Done
+func (at *asyncTransaction) AddSegmentAttribute(segmentID string, key string, value any) error {
+	at.enqueue(func() error {
+		return at.inner.AddSegmentAttribute(segmentID, key, value)
+	})
+
+	return nil
+}
+
+// AddSegmentAttributes queues adding a copy of the attributes to the segment, errors are returned by Done
+func (at *asyncTransaction) AddSegmentAttributes(segmentID string, attributes map[string]any) error {
+	attributes = maps.Clone(attributes)
+
+	at.enqueue(func() error {
+		return at.inner.AddSegmentAttributes(segmentID, attributes)
+	})
+
+	return nil
+}
+
+// AddSegmentEvent queues adding the event with a copy of the attributes to the segment, errors are returned by Done
+func (at *asyncTransaction) AddSegmentEvent(segmentID string, name string, attributes map[string]any) error {
+	attributes = maps.Clone(attributes)
+
+	at.enqueue(func() error {
+		return at.inner.AddSegmentEvent(segmentID, name, attributes)
+	})
+
+	return nil
+}
+
+// SegmentEnd queues ending the segment, errors are returned by Done
+func (at *asyncTransaction) SegmentEnd(segmentID string) error {
+	at.enqueue(func() error {
+		return at.inner.SegmentEnd(segmentID)
+	})
+
+	return nil
+}
+
+// SegmentEndWithStatus queues ending the segment with the status, errors are returned by Done
+func (at *asyncTransaction) SegmentEndWithStatus(segmentID string, status SegmentStatus) error {
+	at.enqueue(func() error {
+		return at.inner.SegmentEndWithStatus(segmentID, status)
+	})
+
+	return nil
+}
+
+// AddLink queues linking the trace with a copy of the attributes, errors are returned by Done
+func (at *asyncTransaction) AddLink(trace string, attributes map[string]any) error {
+	attributes = maps.Clone(attributes)
+
+	at.enqueue(func() error {
+		return at.inner.AddLink(trace, attributes)
+	})
+
+	return nil
+}
+
+// SetStatus queues setting the status of the transaction, errors are returned by Done
+func (at *asyncTransaction) SetStatus(status TransactionStatus) error {
+	at.enqueue(func() error {
+		return at.inner.SetStatus(status)
+	})
+
+	return nil
+}
+
+// AttachPayload queues attaching the payload to the segment, errors are returned by Done
+func (at *asyncTransaction) AttachPayload(segmentID string, payload Payload) error {
+	at.enqueue(func() error {
+		return attachPayload(at.inner, segmentID, payload)
+	})
+
+	return nil
+}
+
+// Flush waits for the queued operations and flushes the wrapped transaction
+func (at *asyncTransaction) Flush() error {
+	return at.call(at.inner.Flush)
+}
+
+// Done drains the queued operations and finishes the wrapped transaction.
+// It returns the errors of the queued operations joined with the error of the wrapped Done
+func (at *asyncTransaction) Done() error {
+	var ew ErrorWrapper
+
+	ew.Add(at.close())
+	ew.Add(at.inner.Done())
+
+	return ew.Error()
+}
+
+// Info reads the message and queues logging it, errors are returned by Done
+func (at *asyncTransaction) Info(segmentID string, rc io.ReadCloser) error {
+	rc, err := readAll(rc)
+	if err != nil {
+		return err
+	}
+
+	at.enqueue(func() error {
+		return at.inner.Info(segmentID, rc)
+	})
+
+	return nil
+}
+
+// Warn reads the message and queues logging it as warning, errors are returned by Done
+func (at *asyncTransaction) Warn(segmentID string, rc io.ReadCloser) error {
+	rc, err := readAll(rc)
+	if err != nil {
+		return err
+	}
+
+	at.enqueue(func() error {
+		return at.inner.Warn(segmentID, rc)
+	})
+
+	return nil
+}
+
+// Error reads the message and queues logging it as error, errors are returned by Done
+func (at *asyncTransaction) Error(segmentID string, rc io.ReadCloser) error {
+	rc, err := readAll(rc)
+	if err != nil {
+		return err
+	}
+
+	at.enqueue(func() error {
+		return at.inner.Error(segmentID, rc)
+	})
+
+	return nil
+}
+
+// Debug reads the message and queues logging it as debug message, errors are returned by Done
+func (at *asyncTransaction) Debug(segmentID string, rc io.ReadCloser) error {
+	rc, err := readAll(rc)
+	if err != nil {
+		return err
+	}
+
+	at.enqueue(func() error {
+		return at.inner.Debug(segmentID, rc)
+	})
+
+	return nil
+}
+
+// InfoFields queues logging a copy of the fields, errors are returned by Done
+func (at *asyncTransaction) InfoFields(segmentID string, fields map[string]any) error {
+	fields = maps.Clone(fields)
+
+	at.enqueue(func() error {
+		return at.inner.InfoFields(segmentID, fields)
+	})
+
+	return nil
+}
+
+// ErrorFields queues logging a copy of the fields as error, errors are returned by Done
+func (at *asyncTransaction) ErrorFields(segmentID string, fields map[string]any) error {
+	fields = maps.Clone(fields)
+
+	at.enqueue(func() error {
+		return at.inner.ErrorFields(segmentID, fields)
+	})
+
+	return nil
+}
+
+// CreateTrace waits for the queued operations and creates the trace of the wrapped transaction
+func (at *asyncTransaction) CreateTrace() (string, error) {
+	var trace string
+
+	err := at.call(func() (err error) {
+		trace, err = at.inner.CreateTrace()
+		return err
+	})
+
+	return trace, err
+}
+
+// SetTrace waits for the queued operations and sets the trace of the wrapped transaction
+func (at *asyncTransaction) SetTrace(trace string) error {
+	return at.call(func() error {
+		return at.inner.SetTrace(trace)
+	})
+}
+
+// Trace waits for the queued operations and returns the trace of the wrapped transaction
+func (at *asyncTransaction) Trace() (string, error) {
+	var trace string
+
+	err := at.call(func() (err error) {
+		trace, err = at.inner.Trace()
+		return err
+	})
+
+	return trace, err
+}
+
+// TraceID waits for the queued operations and returns the trace id of the wrapped transaction
+func (at *asyncTransaction) TraceID() (string, error) {
+	var traceID string
+
+	err := at.call(func() (err error) {
+		traceID, err = at.inner.TraceID()
+		return err
+	})
+
+	return traceID, err
+}
+
+// SetTraceID waits for the queued operations and sets the trace id of the wrapped transaction
+func (at *asyncTransaction) SetTraceID(traceID string) error {
+	return at.call(func() error {
+		return at.inner.SetTraceID(traceID)
+	})
+}
+
+// Erase drains the queued operations and erases the wrapped transaction
+func (at *asyncTransaction) Erase() {
+	at.close()
+	at.inner.Erase()
+}
+
+// CreateProcessID waits for the queued operations and creates the process id of the wrapped transaction
+func (at *asyncTransaction) CreateProcessID() (string, error) {
+	var processID string
+
+	err := at.call(func() (err error) {
+		processID, err = at.inner.CreateProcessID()
+		return err
+	})
+
+	return processID, err
+}
+
+// SetProcessID waits for the queued operations and sets the process id of the wrapped transaction
+func (at *asyncTransaction) SetProcessID(processID string) error {
+	return at.call(func() error {
+		return at.inner.SetProcessID(processID)
+	})
+}
+
+// ProcessID waits for the queued operations and returns the process id of the wrapped transaction
+func (at *asyncTransaction) ProcessID() (string, error) {
+	var processID string
+
+	err := at.call(func() (err error) {
+		processID, err = at.inner.ProcessID()
+		return err
+	})
+
+	return processID, err
+}
+
+// SpanID waits for the queued operations and returns the span id of the wrapped transaction, see SpanIDProvider
+func (at *asyncTransaction) SpanID(segmentID string) (string, error) {
+	provider, ok := at.inner.(SpanIDProvider)
+	if !ok {
+		return "", ErrNoSpanID
+	}
+
+	var spanID string
+
+	err := at.call(func() (err error) {
+		spanID, err = provider.SpanID(segmentID)
+		return err
+	})
+
+	return spanID, err
+}
@@ -0,0 +1,94 @@
+package telemetry_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/telemetrytest"
+)
+
+// blockingDriver is a recording driver whose SegmentStart blocks until release is closed
+type blockingDriver struct {
+	*telemetrytest.RecordingDriver
+	entered chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+// blockingTransaction blocks SegmentStart of the recording transaction
+type blockingTransaction struct {
+	telemetry.Transaction
+	driver *blockingDriver
+}
+
+// InitializeTransaction returns a blocking recording transaction
+func (d *blockingDriver) InitializeTransaction(name string) (telemetry.Transaction, error) {
+	transaction, err := d.RecordingDriver.InitializeTransaction(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &blockingTransaction{Transaction: transaction, driver: d}, nil
+}
+
+// SegmentStart signals entered once and blocks until release is closed
+func (bt *blockingTransaction) SegmentStart(segmentID string, name string) error {
+	bt.driver.once.Do(func() { close(bt.driver.entered) })
+	<-bt.driver.release
+
+	return bt.Transaction.SegmentStart(segmentID, name)
+}
+
+func TestAsyncDriverDoesNotBlockOnPendingCall(t *testing.T) {
+	inner := &blockingDriver{
+		RecordingDriver: telemetrytest.New(),
+		entered:         make(chan struct{}),
+		release:         make(chan struct{}),
+	}
+
+	tel := telemetry.New()
+	err := tel.RegisterDriver("async", telemetry.NewAsyncDriver(inner, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tel.SetDriver("async")
+	tel.SetTraceDriver("async")
+	transaction := start(t, tel, "async")
+
+	transaction.SegmentStart("blocking")
+	<-inner.entered
+	transaction.SegmentStart("queued")
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, _ = transaction.Trace()
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	dropped := make(chan struct{})
+	go func() {
+		transaction.SegmentStart("dropped")
+		close(dropped)
+	}()
+
+	select {
+	case <-dropped:
+	case <-time.After(time.Second):
+		t.Fatal("SegmentStart blocked behind a pending call")
+	}
+
+	close(inner.release)
+	wg.Wait()
+
+	// ending the dropped segment fails in the recording driver
+	_ = transaction.Done()
+
+	if stats := tel.Stats(); stats.DriverOperationsDropped == 0 {
+		t.Fatal("expected the dropped operation in the stats")
+	}
+}
@@ -0,0 +1,96 @@
+package telemetry
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// AllowedAttributeTypes holds the types accepted as attribute values in addition to
+// strings, booleans and all int, uint and float kinds. Additional types should only be added during initialization
+var AllowedAttributeTypes = map[reflect.Type]struct{}{
+	reflect.TypeOf(time.Time{}):      {},
+	reflect.TypeOf(time.Duration(0)): {},
+}
+
+// validateAttribute returns an error if the attribute value has an unsupported type
+func validateAttribute(key string, value any) error {
+	if value == nil {
+		return fmt.Errorf("attribute %q has unsupported type <nil>", key)
+	}
+
+	valueType := reflect.TypeOf(value)
+	if _, ok := AllowedAttributeTypes[valueType]; ok {
+		return nil
+	}
+
+	switch valueType.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return nil
+	}
+
+	return fmt.Errorf("attribute %q has unsupported type %s", key, valueType)
+}
+
+// validAttributes redacts the attributes and returns the ones with a supported type, see validAttributes.
+// Dropped attributes are counted in the stats
+func (tc *TransactionContainer) validAttributes(attributes map[string]any) (map[string]any, error) {
+	redacted := tc.telemetry.redactFields(attributes)
+
+	valid, err := validAttributes(redacted)
+	tc.telemetry.countDroppedAttributes(redacted, valid)
+
+	return valid, err
+}
+
+// validAttributes returns the attributes with a supported type and an error for every other attribute
+func validAttributes(attributes map[string]any) (map[string]any, error) {
+	var ew ErrorWrapper
+
+	valid := make(map[string]any, len(attributes))
+	for key, value := range attributes {
+		err := validateAttribute(key, value)
+		if err != nil {
+			ew.Add(err)
+			continue
+		}
+
+		valid[key] = value
+	}
+
+	return valid, ew.Error()
+}
+
+// AttributeValue is the constraint of the typed attribute functions
+type AttributeValue interface {
+	~string | ~int | ~int64 | ~float64 | ~bool
+}
+
+// AddTypedTransactionAttribute adds the attribute to the registered driver transactions.
+// Unlike AddTransactionAttribute the value type is checked at compile time
+func AddTypedTransactionAttribute[T AttributeValue](tc *TransactionContainer, name string, value T) {
+	tc.AddTransactionAttribute(name, value)
+}
+
+// AddTypedSegmentAttribute adds the attribute to a segment for all driver.
+// Unlike AddSegmentAttribute the value type is checked at compile time
+func AddTypedSegmentAttribute[T AttributeValue](tc *TransactionContainer, segmentID string, name string, value T) {
+	tc.AddSegmentAttribute(segmentID, name, value)
+}
+
+// GetTransactionAttribute returns the transaction attribute with the provided name as passed to the drivers,
+// i.e. redacted. Attributes dropped because of their type or the attribute limit are not found
+func (tc *TransactionContainer) GetTransactionAttribute(name string) (any, bool) {
+	return tc.record.attribute(name)
+}
+
+// GetSegmentAttribute returns the attribute with the provided name of the segment as passed to the drivers,
+// including the transaction attributes and baggage the segment inherited when it started.
+// Attributes of unknown segments, of segments dropped for ending faster than the minimum duration and of ended
+// segments without SetSnapshotEnabled are not found
+func (tc *TransactionContainer) GetSegmentAttribute(segmentID string, name string) (any, bool) {
+	return tc.segments.attribute(segmentID, name)
+}
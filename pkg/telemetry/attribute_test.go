@@ -0,0 +1,57 @@
+package telemetry_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/telemetrytest"
+)
+
+func TestGetAttributes(t *testing.T) {
+	tel, _ := telemetrytest.NewTelemetry(t)
+	tel.SetInheritTransactionAttributes(true)
+	tel.SetRedactor(telemetry.RegexRedactor(regexp.MustCompile(`secret-\w+`)))
+	transaction := start(t, tel, "attributes")
+
+	transaction.AddTransactionAttribute("tenant", "acme")
+	transaction.AddTransactionAttribute("token", "secret-abc")
+
+	segmentID := transaction.SegmentStart("segment")
+	transaction.AddSegmentAttribute(segmentID, "rows", 3)
+
+	tests := []struct {
+		name      string
+		segmentID string
+		key       string
+		expected  any
+		found     bool
+	}{
+		{name: "transaction", key: "tenant", expected: "acme", found: true},
+		{name: "transaction redacted", key: "token", expected: telemetry.RedactionMask, found: true},
+		{name: "transaction missing", key: "rows"},
+		{name: "segment", segmentID: segmentID, key: "rows", expected: 3, found: true},
+		{name: "segment inherited", segmentID: segmentID, key: "tenant", expected: "acme", found: true},
+		{name: "segment missing", segmentID: segmentID, key: "missing"},
+		{name: "segment unknown", segmentID: "unknown", key: "rows"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				value any
+				found bool
+			)
+
+			if tt.segmentID == "" {
+				value, found = transaction.GetTransactionAttribute(tt.key)
+			} else {
+				value, found = transaction.GetSegmentAttribute(tt.segmentID, tt.key)
+			}
+
+			if found != tt.found || value != tt.expected {
+				t.Errorf("expected %v, %t, got %v, %t", tt.expected, tt.found, value, found)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package telemetry
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// BaggageHeader is the W3C baggage header
+const BaggageHeader = "baggage"
+
+// baggageStore holds the baggage of a transaction container
+type baggageStore struct {
+	mu     sync.RWMutex
+	values map[string]string
+}
+
+// newBaggageStore returns an empty baggage store
+func newBaggageStore() *baggageStore {
+	return &baggageStore{
+		values: make(map[string]string),
+	}
+}
+
+// set stores the baggage entry
+func (bs *baggageStore) set(key string, value string) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	bs.values[key] = value
+}
+
+// attributes returns a copy of the baggage as attributes or nil if there is no baggage
+func (bs *baggageStore) attributes() map[string]any {
+	bs.mu.RLock()
+	defer bs.mu.RUnlock()
+
+	if len(bs.values) == 0 {
+		return nil
+	}
+
+	attributes := make(map[string]any, len(bs.values))
+	for key, value := range bs.values {
+		attributes[key] = value
+	}
+
+	return attributes
+}
+
+// SetBaggage sets an entry which is added as attribute to the transaction, to every segment started
+// afterwards and as field to every log message
+func (tc *TransactionContainer) SetBaggage(key string, value string) {
+	tc.baggage.set(key, value)
+	tc.AddTransactionAttribute(key, value)
+}
+
+// Baggage returns a copy of all baggage entries
+func (tc *TransactionContainer) Baggage() map[string]string {
+	tc.baggage.mu.RLock()
+	defer tc.baggage.mu.RUnlock()
+
+	baggage := make(map[string]string, len(tc.baggage.values))
+	for key, value := range tc.baggage.values {
+		baggage[key] = value
+	}
+
+	return baggage
+}
+
+// withBaggage returns the fields merged over the baggage
+func (tc *TransactionContainer) withBaggage(fields map[string]any) map[string]any {
+	attributes := tc.baggage.attributes()
+	if attributes == nil {
+		return fields
+	}
+
+	for key, value := range fields {
+		attributes[key] = value
+	}
+
+	return attributes
+}
+
+// injectBaggage writes the baggage as W3C baggage header
+func (tc *TransactionContainer) injectBaggage(h http.Header) {
+	baggage := tc.Baggage()
+	if len(baggage) == 0 {
+		return
+	}
+
+	members := make([]string, 0, len(baggage))
+	for key, value := range baggage {
+		members = append(members, url.PathEscape(key)+"="+url.PathEscape(value))
+	}
+
+	sort.Strings(members)
+	h.Set(BaggageHeader, strings.Join(members, ","))
+}
+
+// ExtractBaggage parses the W3C baggage header. Properties of the members are ignored
+func ExtractBaggage(h http.Header) (map[string]string, error) {
+	baggage := make(map[string]string)
+
+	header := strings.TrimSpace(h.Get(BaggageHeader))
+	if header == "" {
+		return baggage, nil
+	}
+
+	for _, member := range strings.Split(header, ",") {
+		member, _, _ = strings.Cut(member, ";")
+
+		key, value, ok := strings.Cut(member, "=")
+		if !ok {
+			return nil, fmt.Errorf("malformed baggage member %q", member)
+		}
+
+		key, err := url.PathUnescape(strings.TrimSpace(key))
+		if err != nil || key == "" {
+			return nil, fmt.Errorf("malformed baggage member %q", member)
+		}
+
+		value, err = url.PathUnescape(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("malformed baggage member %q", member)
+		}
+
+		baggage[key] = value
+	}
+
+	return baggage, nil
+}
@@ -0,0 +1,43 @@
+package telemetry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/telemetrytest"
+)
+
+func TestBaggageIsAddedToEveryLog(t *testing.T) {
+	tel, recorder := telemetrytest.NewTelemetry(t)
+	tel.SetLogLevel(telemetry.LevelDebug)
+	transaction := start(t, tel, "baggage")
+	transaction.SetBaggage("tenant_id", "42")
+
+	segmentID := transaction.SegmentStart("segment")
+	msg := "message"
+	err := errors.New("failure")
+	transaction.Info(segmentID, &msg)
+	transaction.Warn(segmentID, &msg)
+	transaction.Debug(segmentID, &msg)
+	transaction.Error(segmentID, &err)
+	transaction.InfoCtx(context.Background(), "", &msg)
+	transaction.ErrorCtx(context.Background(), "", &err)
+	transaction.SegmentEnd(segmentID)
+
+	logs := recorder.Logs()
+	if len(logs) != 6 {
+		t.Fatalf("expected 6 logs, got %d", len(logs))
+	}
+
+	for _, log := range logs {
+		if log.Fields["tenant_id"] != "42" {
+			t.Errorf("expected the baggage in the fields of %s log %q, got %v", log.Level, log.Message, log.Fields)
+		}
+	}
+
+	recorder.AssertLog(t, "info", msg)
+	recorder.AssertLog(t, "error", err.Error())
+	recorder.AssertAttribute(t, "tenant_id", "42")
+}
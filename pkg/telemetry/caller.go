@@ -0,0 +1,80 @@
+package telemetry
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// Fields added to every log message if the caller is captured
+const (
+	CallerAttribute         = "code.caller"
+	CallerFunctionAttribute = "code.function"
+)
+
+// maxCallerDepth is the maximum number of frames searched for the caller outside of the package
+const maxCallerDepth = 16
+
+// packagePrefix is the prefix of the function names of this package, e.g. to skip its frames
+var packagePrefix = func() string {
+	pc, _, _, _ := runtime.Caller(0)
+	name := runtime.FuncForPC(pc).Name()
+	slash := strings.LastIndex(name, "/")
+
+	return name[:slash+strings.Index(name[slash:], ".")+1]
+}()
+
+// SetCaptureCaller enables or disables capturing the caller of the log methods of the default instance
+func SetCaptureCaller(capture bool) {
+	defaultTelemetry.SetCaptureCaller(capture)
+}
+
+// SetCaptureCaller makes the log methods add the file:line and the function of their caller
+// as CallerAttribute and CallerFunctionAttribute fields to the log message, see InfoFields.
+// Frames of this package are skipped, so the call site in the application is reported.
+// Capturing the caller walks the stack on every call, so it is disabled by default
+func (t *Telemetry) SetCaptureCaller(capture bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.captureCaller = capture
+}
+
+// capturesCaller reports whether the log methods capture their caller
+func (t *Telemetry) capturesCaller() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.captureCaller
+}
+
+// callerAttributes returns the attributes of the first caller outside of this package.
+// Frames of test files of this package count as callers, they share the package prefix
+func callerAttributes() map[string]any {
+	pcs := make([]uintptr, maxCallerDepth)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, packagePrefix) || strings.HasSuffix(frame.File, "_test.go") {
+			return map[string]any{
+				CallerAttribute:         fmt.Sprintf("%s:%d", frame.File, frame.Line),
+				CallerFunctionAttribute: frame.Function,
+			}
+		}
+
+		if !more {
+			return nil
+		}
+	}
+}
+
+// callerFields returns the caller fields of a log message or nil if the caller is not captured
+func (tc *TransactionContainer) callerFields() map[string]any {
+	if !tc.telemetry.capturesCaller() {
+		return nil
+	}
+
+	return callerAttributes()
+}
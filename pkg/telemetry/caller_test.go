@@ -0,0 +1,48 @@
+package telemetry_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/telemetrytest"
+)
+
+func TestCaptureCallerAddsLogFields(t *testing.T) {
+	tel, recorder := telemetrytest.NewTelemetry(t)
+	tel.SetCaptureCaller(true)
+	transaction := start(t, tel, "caller")
+
+	segmentID := transaction.SegmentStart("segment")
+	msg := "message"
+	transaction.Info(segmentID, &msg)
+	err := errors.New("failure")
+	transaction.Error("", &err)
+	transaction.SegmentEnd(segmentID)
+
+	logs := recorder.Logs()
+	if len(logs) != 2 {
+		t.Fatalf("expected 2 logs, got %d", len(logs))
+	}
+
+	for _, log := range logs {
+		caller, _ := log.Fields[telemetry.CallerAttribute].(string)
+		if !strings.Contains(caller, "caller_test.go:") {
+			t.Errorf("expected %s in caller_test.go, got %q", telemetry.CallerAttribute, caller)
+		}
+
+		function, _ := log.Fields[telemetry.CallerFunctionAttribute].(string)
+		if !strings.HasSuffix(function, "TestCaptureCallerAddsLogFields") {
+			t.Errorf("expected %s of the test, got %q", telemetry.CallerFunctionAttribute, function)
+		}
+	}
+
+	recorder.AssertLog(t, "info", msg)
+
+	for _, attribute := range append(recorder.TransactionAttributes(), recorder.SegmentAttributes()...) {
+		if attribute.Key == telemetry.CallerAttribute {
+			t.Fatalf("unexpected %s attribute", telemetry.CallerAttribute)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package telemetry
+
+import "time"
+
+// SetClock sets the clock of the default instance
+func SetClock(clock func() time.Time) {
+	defaultTelemetry.SetClock(clock)
+}
+
+// SetClock replaces time.Now for all times and durations captured by the transaction containers, e.g. segment
+// durations, the transaction duration, the minimum segment duration and the log rate limit. It is meant for tests
+// advancing a fake clock, see telemetrytest.FakeClock. Drivers keep their own clock. A nil clock restores time.Now
+func (t *Telemetry) SetClock(clock func() time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.clock = clock
+}
+
+// now returns the current time of the clock
+func (t *Telemetry) now() time.Time {
+	t.mu.RLock()
+	clock := t.clock
+	t.mu.RUnlock()
+
+	return clockTime(clock)
+}
+
+// clockTime returns the current time of the clock or time.Now if clock is nil
+func clockTime(clock func() time.Time) time.Time {
+	if clock == nil {
+		return time.Now()
+	}
+
+	return clock()
+}
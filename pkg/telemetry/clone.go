@@ -0,0 +1,52 @@
+package telemetry
+
+import "fmt"
+
+// Clone starts a new transaction container on the same drivers which shares the process id, trace and baggage.
+// The clone tracks its own segments and has to be ended with its own Done. Its segments are not children of
+// segments of the original container, they are only correlated through the shared trace
+func (tc *TransactionContainer) Clone(name string) (TransactionContainer, error) {
+	processID, err := tc.rawProcessID()
+	if err != nil {
+		return tc.telemetry.emptyContainer(name), ErrorProcessID{
+			err: err,
+		}
+	}
+
+	trace, err := tc.Trace()
+	if err != nil {
+		trace = ""
+	}
+
+	tc.mu.RLock()
+	driverNames := tc.driverOrder()
+	tc.mu.RUnlock()
+
+	clone, err := tc.telemetry.initialize(name, driverNames, tc.traceDrivers, tc.sampled)
+	if err != nil {
+		return clone, fmt.Errorf("could not clone transaction: %w", err)
+	}
+
+	err = clone.SetProcessID(processID)
+	if err != nil {
+		return clone, ErrorProcessID{
+			err: err,
+		}
+	}
+
+	if trace != "" {
+		err = clone.SetTrace(trace)
+		if err != nil {
+			return clone, err
+		}
+	}
+
+	clone.begin(name)
+	clone.addResourceAttributes()
+
+	for key, value := range tc.Baggage() {
+		clone.SetBaggage(key, value)
+	}
+
+	return clone, nil
+}
@@ -0,0 +1,118 @@
+package telemetry
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+)
+
+// DefaultCompressionThreshold is the default size in bytes above which payloads are compressed
+const DefaultCompressionThreshold = 1024
+
+// ContentEncodingGzip is the content encoding of gzip compressed payloads
+const ContentEncodingGzip = "gzip"
+
+// LogCompression is the compression of payloads handed to the drivers
+type LogCompression int
+
+// Available log compressions
+const (
+	CompressionNone LogCompression = iota
+	CompressionGzip
+)
+
+// SetLogCompression sets the payload compression of the default instance
+func SetLogCompression(compression LogCompression) {
+	defaultTelemetry.SetLogCompression(compression)
+}
+
+// SetLogCompressionThreshold sets the compression threshold of the default instance
+func SetLogCompressionThreshold(n int) {
+	defaultTelemetry.SetLogCompressionThreshold(n)
+}
+
+// SetLogCompression compresses log messages and payloads attached with AttachPayload above the compression threshold
+// before they are handed to the drivers, to save bandwidth in high volume environments. Compressed payloads carry
+// their ContentEncoding. Compressed log messages are base64 encoded and logged with InfoFields or ErrorFields,
+// with the encoding under FieldContentEncoding. Drivers decompress them or store them compressed with the encoding.
+// Messages and payloads which do not shrink are passed uncompressed. Snapshots keep the uncompressed messages.
+// The default is CompressionNone
+func (t *Telemetry) SetLogCompression(compression LogCompression) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.logCompression = compression
+}
+
+// SetLogCompressionThreshold sets the size in bytes a payload needs to exceed to be compressed, as the overhead of
+// compressing small payloads exceeds the benefit. Values below 1 restore DefaultCompressionThreshold
+func (t *Telemetry) SetLogCompressionThreshold(n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.compressionThreshold = n
+}
+
+// compression returns the payload compression and the compression threshold
+func (t *Telemetry) compression() (LogCompression, int) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.compressionThreshold < 1 {
+		return t.logCompression, DefaultCompressionThreshold
+	}
+
+	return t.logCompression, t.compressionThreshold
+}
+
+// compressMessage returns the base64 encoded gzip compressed message and ContentEncodingGzip if compression is enabled,
+// the message exceeds the threshold and the encoded message is smaller. Otherwise it returns the message unchanged
+func (t *Telemetry) compressMessage(message string) (string, string) {
+	compression, threshold := t.compression()
+	if compression != CompressionGzip || len(message) <= threshold {
+		return message, ""
+	}
+
+	compressed, ok := gzipData([]byte(message))
+	if !ok {
+		return message, ""
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(compressed)
+	if len(encoded) >= len(message) {
+		return message, ""
+	}
+
+	return encoded, ContentEncodingGzip
+}
+
+// compressPayload returns the payload with gzip compressed data if compression is enabled,
+// the payload exceeds the threshold and the compressed data is smaller
+func (t *Telemetry) compressPayload(payload Payload) Payload {
+	compression, threshold := t.compression()
+	if compression != CompressionGzip || len(payload.Data) <= threshold {
+		return payload
+	}
+
+	compressed, ok := gzipData(payload.Data)
+	if !ok || len(compressed) >= len(payload.Data) {
+		return payload
+	}
+
+	payload.Data = compressed
+	payload.ContentEncoding = ContentEncodingGzip
+
+	return payload
+}
+
+// gzipData returns the gzip compressed data and reports whether the compression succeeded
+func gzipData(data []byte) ([]byte, bool) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	_, err := writer.Write(data)
+	if err == nil {
+		err = writer.Close()
+	}
+
+	return buf.Bytes(), err == nil
+}
@@ -0,0 +1,138 @@
+package telemetry_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/telemetrytest"
+)
+
+// compressiblePayload is a JSON like payload above the default compression threshold
+var compressiblePayload = []byte(strings.Repeat(`{"id":42,"name":"item","tags":["a","b","c"]},`, 100))
+
+func TestPayloadCompression(t *testing.T) {
+	tel, recorder := telemetrytest.NewTelemetry(t)
+	tel.SetLogCompression(telemetry.CompressionGzip)
+	tel.SetLogCompressionThreshold(64)
+	transaction := start(t, tel, "compression")
+
+	err := transaction.AttachPayload("", "small", "application/json", strings.NewReader(`{"id":42}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = transaction.AttachPayload("", "large", "application/json", bytes.NewReader(compressiblePayload))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payloads := recorder.Payloads()
+	if len(payloads) != 2 {
+		t.Fatalf("expected 2 payloads, got %d", len(payloads))
+	}
+
+	if payloads[0].ContentEncoding != "" {
+		t.Errorf("expected the payload below the threshold to stay uncompressed, got %q", payloads[0].ContentEncoding)
+	}
+
+	large := payloads[1]
+	if large.ContentEncoding != telemetry.ContentEncodingGzip || len(large.Data) >= len(compressiblePayload) {
+		t.Fatalf("expected a smaller gzip payload, got %q with %d bytes", large.ContentEncoding, len(large.Data))
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(large.Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(data, compressiblePayload) {
+		t.Error("expected the decompressed payload to match the attached payload")
+	}
+}
+
+func TestLogMessageCompression(t *testing.T) {
+	tel, recorder := telemetrytest.NewTelemetry(t)
+	tel.SetLogCompression(telemetry.CompressionGzip)
+	tel.SetLogCompressionThreshold(64)
+	transaction := start(t, tel, "compression")
+
+	short, message := "short", string(compressiblePayload)
+	transaction.Info("", &short)
+	transaction.Info("", &message)
+
+	logs := recorder.Logs()
+	if len(logs) != 2 {
+		t.Fatalf("expected 2 logs, got %d", len(logs))
+	}
+
+	if logs[0].Message != "short" || logs[0].Fields[telemetry.FieldContentEncoding] != nil {
+		t.Errorf("expected the message below the threshold to stay uncompressed, got %+v", logs[0])
+	}
+
+	fields := logs[1].Fields
+	if fields[telemetry.FieldContentEncoding] != telemetry.ContentEncodingGzip {
+		t.Fatalf("expected the gzip content encoding, got %v", fields[telemetry.FieldContentEncoding])
+	}
+
+	encoded, ok := fields[telemetry.FieldMessage].(string)
+	if !ok || len(encoded) >= len(message) {
+		t.Fatalf("expected a smaller encoded message, got %v", fields[telemetry.FieldMessage])
+	}
+
+	compressed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != message {
+		t.Error("expected the decompressed message to match the logged message")
+	}
+}
+
+func BenchmarkAttachPayload(b *testing.B) {
+	for _, bm := range []struct {
+		name        string
+		compression telemetry.LogCompression
+	}{
+		{name: "none", compression: telemetry.CompressionNone},
+		{name: "gzip", compression: telemetry.CompressionGzip},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			tel := newNoopTelemetry()
+			tel.SetLogCompression(bm.compression)
+			transaction := start(b, tel, "benchmark")
+			defer transaction.Done()
+
+			b.ReportAllocs()
+			b.SetBytes(int64(len(compressiblePayload)))
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				err := transaction.AttachPayload("", "body", "application/json", bytes.NewReader(compressiblePayload))
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
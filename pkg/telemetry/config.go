@@ -0,0 +1,257 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Configuration keys read by ConfigureFromConfig
+const (
+	ConfigKeyDrivers     = "telemetry.drivers"
+	ConfigKeyDriver      = "telemetry.driver"
+	ConfigKeyTraceDriver = "telemetry.traceDriver"
+	ConfigKeyLogLevel    = "telemetry.logLevel"
+)
+
+// Per driver configuration keys, read below telemetry.<driver>, e.g. telemetry.zipkin.endpoint
+const (
+	ConfigKeyEndpoint    = "endpoint"
+	ConfigKeyServiceName = "serviceName"
+	ConfigKeySampleRate  = "sampleRate"
+)
+
+// Config is the part of a configuration reader used by ConfigureFromConfig, e.g. a viper instance
+type Config interface {
+	GetString(key string) string
+	GetStringSlice(key string) []string
+}
+
+// DriverSettings are the per driver settings read by ConfigureFromConfig
+type DriverSettings struct {
+	// Endpoint is the address the driver sends its data to, e.g. a collector URL
+	Endpoint string
+	// ServiceName is the name of the service the data is reported for
+	ServiceName string
+	// SampleRate is the fraction of traces to sample between 0 and 1, 1 if not configured
+	SampleRate float64
+}
+
+// DriverFactory creates a driver from its configured settings
+type DriverFactory func(settings DriverSettings) (Driver, error)
+
+// RegisterDriverFactory adds a factory to the default instance, see the method
+func RegisterDriverFactory(name string, factory DriverFactory) error {
+	return defaultTelemetry.RegisterDriverFactory(name, factory)
+}
+
+// RegisterDriverFactory adds a factory ConfigureFromConfig creates the driver name with if it is configured
+// but not registered yet. It returns an error if a factory is already registered under the name
+func (t *Telemetry) RegisterDriverFactory(name string, factory DriverFactory) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.driverFactories == nil {
+		t.driverFactories = make(map[string]DriverFactory)
+	}
+
+	if _, ok := t.driverFactories[name]; ok {
+		return fmt.Errorf("telemetry driver factory %q already registered", name)
+	}
+
+	t.driverFactories[name] = factory
+
+	return nil
+}
+
+// ConfigureFromConfig activates the drivers of the default instance from the configuration
+func ConfigureFromConfig(c Config) error {
+	return defaultTelemetry.ConfigureFromConfig(c)
+}
+
+// ConfigureFromConfig activates the drivers from the configuration.
+// The drivers are read from ConfigKeyDrivers, or the comma separated ConfigKeyDriver if it is empty,
+// and the trace drivers from the comma separated ConfigKeyTraceDriver. A set ConfigKeyLogLevel sets the log level.
+// A configured driver which is not registered is created by its DriverFactory with the settings below
+// telemetry.<driver> and registered, a registered driver keeps the settings it was created with.
+// Settings of a driver without factory are rejected with ErrUnsupportedDriverSettings,
+// as a driver registered in code already ran its constructor.
+// If a configured driver is neither registered nor has a factory, a setting is invalid or a factory fails,
+// an error is returned and nothing is changed
+func (t *Telemetry) ConfigureFromConfig(c Config) error {
+	drivers := splitConfigList(c.GetStringSlice(ConfigKeyDrivers)...)
+	if len(drivers) == 0 {
+		drivers = splitConfigList(c.GetString(ConfigKeyDriver))
+	}
+
+	traceDrivers := splitConfigList(c.GetString(ConfigKeyTraceDriver))
+
+	levelName := c.GetString(ConfigKeyLogLevel)
+
+	var level Level
+	if levelName != "" {
+		var err error
+		level, err = ParseLevel(levelName)
+		if err != nil {
+			return err
+		}
+	}
+
+	created, err := t.createConfiguredDrivers(c, append(append([]string(nil), drivers...), traceDrivers...))
+	if err != nil {
+		return err
+	}
+
+	err = t.registerDrivers(created)
+	if err != nil {
+		shutdownDrivers(created)
+		return err
+	}
+
+	if len(drivers) > 0 {
+		t.SetDriver(drivers...)
+	}
+
+	if len(traceDrivers) > 0 {
+		t.SetTraceDrivers(traceDrivers...)
+	}
+
+	if levelName != "" {
+		t.SetLogLevel(level)
+	}
+
+	return nil
+}
+
+// registerDrivers registers all drivers or none of them
+func (t *Telemetry) registerDrivers(drivers map[string]Driver) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.registeredDriver == nil {
+		t.registeredDriver = make(map[string]Driver)
+	}
+
+	for name := range drivers {
+		if _, ok := t.registeredDriver[name]; ok {
+			return fmt.Errorf("telemetry driver %q already registered", name)
+		}
+	}
+
+	for name, driver := range drivers {
+		t.registeredDriver[name] = driver
+	}
+
+	return nil
+}
+
+// createConfiguredDrivers checks that every name is registered or has a factory and returns the drivers
+// created by the factories of the names which are not registered yet
+func (t *Telemetry) createConfiguredDrivers(c Config, names []string) (map[string]Driver, error) {
+	t.mu.RLock()
+	factories := make(map[string]DriverFactory)
+	for _, name := range names {
+		if factory, ok := t.driverFactories[name]; ok {
+			factories[name] = factory
+		}
+	}
+	t.mu.RUnlock()
+
+	settings := make(map[string]DriverSettings)
+	for _, name := range names {
+		if _, ok := settings[name]; ok {
+			continue
+		}
+
+		s, keys, err := readDriverSettings(c, name)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = t.getDriver(name)
+		registered := err == nil
+
+		if _, ok := factories[name]; !ok {
+			if len(keys) > 0 {
+				return nil, ErrUnsupportedDriverSettings{Name: name, Keys: keys}
+			}
+
+			if !registered {
+				return nil, err
+			}
+		}
+
+		if registered {
+			delete(factories, name)
+		}
+
+		settings[name] = s
+	}
+
+	created := make(map[string]Driver, len(factories))
+	for name, factory := range factories {
+		driver, err := factory(settings[name])
+		if err != nil {
+			shutdownDrivers(created)
+			return nil, fmt.Errorf("telemetry driver %q: %w", name, err)
+		}
+
+		created[name] = driver
+	}
+
+	return created, nil
+}
+
+// shutdownDrivers shuts down the drivers created for a configuration which is not applied
+func shutdownDrivers(drivers map[string]Driver) {
+	for _, driver := range drivers {
+		_ = safeCall(func() error { return shutdownDriver(context.Background(), driver) })
+	}
+}
+
+// readDriverSettings returns the settings below telemetry.<name> and the keys which are set
+func readDriverSettings(c Config, name string) (DriverSettings, []string, error) {
+	prefix := "telemetry." + name + "."
+	settings := DriverSettings{
+		Endpoint:    c.GetString(prefix + ConfigKeyEndpoint),
+		ServiceName: c.GetString(prefix + ConfigKeyServiceName),
+		SampleRate:  1,
+	}
+
+	var keys []string
+	if settings.Endpoint != "" {
+		keys = append(keys, prefix+ConfigKeyEndpoint)
+	}
+
+	if settings.ServiceName != "" {
+		keys = append(keys, prefix+ConfigKeyServiceName)
+	}
+
+	if rate := c.GetString(prefix + ConfigKeySampleRate); rate != "" {
+		keys = append(keys, prefix+ConfigKeySampleRate)
+
+		var err error
+		settings.SampleRate, err = strconv.ParseFloat(rate, 64)
+		if err != nil || settings.SampleRate < 0 || settings.SampleRate > 1 {
+			return DriverSettings{}, nil, fmt.Errorf("invalid %s%s %q: expected a number between 0 and 1", prefix, ConfigKeySampleRate, rate)
+		}
+	}
+
+	return settings, keys, nil
+}
+
+// splitConfigList splits the values at commas and returns the trimmed non empty names
+func splitConfigList(values ...string) []string {
+	var names []string
+	for _, value := range values {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names
+}
@@ -0,0 +1,212 @@
+package telemetry_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/telemetrytest"
+)
+
+// mapConfig is a telemetry.Config reading comma separated string slices from a map
+type mapConfig map[string]string
+
+// GetString returns the value of the key
+func (c mapConfig) GetString(key string) string {
+	return c[key]
+}
+
+// GetStringSlice returns the comma separated values of the key
+func (c mapConfig) GetStringSlice(key string) []string {
+	if c[key] == "" {
+		return nil
+	}
+
+	return strings.Split(c[key], ",")
+}
+
+// closingDriver is a recording driver reporting whether it was closed
+type closingDriver struct {
+	*telemetrytest.RecordingDriver
+	closed bool
+}
+
+// Close marks the driver as closed
+func (d *closingDriver) Close() error {
+	d.closed = true
+
+	return nil
+}
+
+func TestConfigureFromConfigActivatesDrivers(t *testing.T) {
+	tel, _ := telemetrytest.NewTelemetry(t)
+	tel.MustRegisterDriver("second", telemetrytest.New())
+
+	err := tel.ConfigureFromConfig(mapConfig{
+		telemetry.ConfigKeyDrivers:     "second, " + telemetrytest.RecorderName,
+		telemetry.ConfigKeyTraceDriver: "second",
+		telemetry.ConfigKeyLogLevel:    "info",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"second", telemetrytest.RecorderName}
+	if !reflect.DeepEqual(tel.ActiveDrivers(), expected) {
+		t.Errorf("expected the drivers %v, got %v", expected, tel.ActiveDrivers())
+	}
+}
+
+func TestConfigureFromConfigCreatesDriversWithFactories(t *testing.T) {
+	tel := telemetry.New()
+
+	var settings []telemetry.DriverSettings
+	err := tel.RegisterDriverFactory("collector", func(s telemetry.DriverSettings) (telemetry.Driver, error) {
+		settings = append(settings, s)
+
+		return telemetrytest.New(), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := mapConfig{
+		telemetry.ConfigKeyDriver:           "collector",
+		telemetry.ConfigKeyTraceDriver:      "collector",
+		"telemetry.collector.endpoint":      "http://collector:4318",
+		"telemetry.collector.serviceName":   "checkout",
+		"telemetry.collector.sampleRate":    "0.25",
+		"telemetry.unconfigured.sampleRate": "1",
+	}
+
+	err = tel.ConfigureFromConfig(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []telemetry.DriverSettings{{Endpoint: "http://collector:4318", ServiceName: "checkout", SampleRate: 0.25}}
+	if !reflect.DeepEqual(settings, expected) {
+		t.Errorf("expected the factory to be called with %v, got %v", expected, settings)
+	}
+
+	if !reflect.DeepEqual(tel.ActiveDrivers(), []string{"collector"}) {
+		t.Errorf("expected the created driver to be active, got %v", tel.ActiveDrivers())
+	}
+
+	err = tel.ConfigureFromConfig(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(settings) != 1 {
+		t.Errorf("expected a registered driver not to be created again, got %d calls", len(settings))
+	}
+}
+
+func TestConfigureFromConfigDefaultSampleRate(t *testing.T) {
+	tel := telemetry.New()
+
+	var sampleRate float64
+	tel.RegisterDriverFactory("collector", func(s telemetry.DriverSettings) (telemetry.Driver, error) {
+		sampleRate = s.SampleRate
+
+		return telemetrytest.New(), nil
+	})
+
+	err := tel.ConfigureFromConfig(mapConfig{telemetry.ConfigKeyDriver: "collector"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sampleRate != 1 {
+		t.Errorf("expected the sample rate 1 without configuration, got %v", sampleRate)
+	}
+}
+
+func TestConfigureFromConfigRejectsInvalidConfiguration(t *testing.T) {
+	tests := []struct {
+		name   string
+		config mapConfig
+		check  func(error) bool
+	}{
+		{
+			name:   "unregistered driver",
+			config: mapConfig{telemetry.ConfigKeyDriver: "missing"},
+			check: func(err error) bool {
+				var notRegistered telemetry.ErrDriverNotRegistered
+				return errors.As(err, &notRegistered) && notRegistered.Name == "missing"
+			},
+		},
+		{
+			name: "settings of a driver without factory",
+			config: mapConfig{
+				telemetry.ConfigKeyDriver:     telemetrytest.RecorderName,
+				"telemetry.recorder.endpoint": "http://collector:4318",
+			},
+			check: func(err error) bool {
+				var unsupported telemetry.ErrUnsupportedDriverSettings
+				return errors.As(err, &unsupported) && unsupported.Name == telemetrytest.RecorderName &&
+					reflect.DeepEqual(unsupported.Keys, []string{"telemetry.recorder.endpoint"})
+			},
+		},
+		{
+			name: "sample rate out of range",
+			config: mapConfig{
+				telemetry.ConfigKeyDriver:        "collector",
+				"telemetry.collector.sampleRate": "1.5",
+			},
+			check: func(err error) bool { return err != nil && strings.Contains(err.Error(), "sampleRate") },
+		},
+		{
+			name: "failing factory",
+			config: mapConfig{
+				telemetry.ConfigKeyDriver:    "collector,failing",
+				"telemetry.failing.endpoint": "unreachable",
+			},
+			check: func(err error) bool { return err != nil && strings.Contains(err.Error(), "unreachable") },
+		},
+		{
+			name:   "unknown log level",
+			config: mapConfig{telemetry.ConfigKeyDriver: "collector", telemetry.ConfigKeyLogLevel: "verbose"},
+			check:  func(err error) bool { return err != nil },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tel, _ := telemetrytest.NewTelemetry(t)
+
+			var created []*closingDriver
+			tel.RegisterDriverFactory("collector", func(telemetry.DriverSettings) (telemetry.Driver, error) {
+				driver := &closingDriver{RecordingDriver: telemetrytest.New()}
+				created = append(created, driver)
+
+				return driver, nil
+			})
+			tel.RegisterDriverFactory("failing", func(s telemetry.DriverSettings) (telemetry.Driver, error) {
+				return nil, errors.New(s.Endpoint)
+			})
+
+			err := tel.ConfigureFromConfig(tt.config)
+			if !tt.check(err) {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			if !reflect.DeepEqual(tel.ActiveDrivers(), []string{telemetrytest.RecorderName}) {
+				t.Errorf("expected the drivers to be unchanged, got %v", tel.ActiveDrivers())
+			}
+
+			if !reflect.DeepEqual(tel.RegisteredDrivers(), []string{telemetry.NoopDriverName, telemetrytest.RecorderName, telemetry.StdoutDriverName}) {
+				t.Errorf("expected no driver to be registered, got %v", tel.RegisteredDrivers())
+			}
+
+			for _, driver := range created {
+				if !driver.closed {
+					t.Error("expected the created driver to be closed")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+)
+
+// containerKey is the context key for the active transaction container
+type containerKey struct{}
+
+// segmentKey is the context key for the active segment id
+type segmentKey struct{}
+
+// StartContext starts a transaction container of the default instance and stores it in the returned context.
+// A nil context is treated as context.Background()
+func StartContext(ctx context.Context, name string, opts ...StartOption) (context.Context, TransactionContainer, error) {
+	return defaultTelemetry.StartContext(ctx, name, opts...)
+}
+
+// StartContext starts a transaction container like Start and stores it in the returned context.
+// A nil context is treated as context.Background()
+func (t *Telemetry) StartContext(ctx context.Context, name string, opts ...StartOption) (context.Context, TransactionContainer, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	transactionContainer, err := t.start(name, opts)
+	if err != nil {
+		return ctx, transactionContainer, err
+	}
+
+	return NewContext(ctx, &transactionContainer), transactionContainer, nil
+}
+
+// NewContext returns a copy of ctx which carries the provided transaction container
+func NewContext(ctx context.Context, tc *TransactionContainer) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return context.WithValue(ctx, containerKey{}, tc)
+}
+
+// FromContext returns the transaction container stored in ctx by StartContext
+func FromContext(ctx context.Context) (*TransactionContainer, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+
+	tc, ok := ctx.Value(containerKey{}).(*TransactionContainer)
+	if !ok || tc == nil {
+		return nil, false
+	}
+
+	return tc, true
+}
+
+// Segment starts a segment in the transaction container stored in ctx and returns a context carrying the segment id
+// and a function ending the segment. If ctx already carries a segment, the new segment is started as its child.
+// Without a transaction container in ctx, ctx and a no-op function are returned
+//
+//	ctx, end := telemetry.Segment(ctx, "db.query")
+//	defer end()
+func Segment(ctx context.Context, name string) (context.Context, func()) {
+	tc, ok := FromContext(ctx)
+	if !ok {
+		return ctx, func() {}
+	}
+
+	var (
+		segmentID string
+		err       error
+	)
+
+	parentSegmentID, ok := SegmentIDFromContext(ctx)
+	if ok {
+		segmentID, err = tc.SegmentStartChild(parentSegmentID, name)
+	} else {
+		segmentID, err = tc.SegmentStartE(name)
+	}
+	if err != nil {
+		log.Print(err)
+	}
+
+	if segmentID == "" {
+		return ctx, func() {}
+	}
+
+	return context.WithValue(ctx, segmentKey{}, segmentID), func() {
+		tc.SegmentEnd(segmentID)
+	}
+}
+
+// SegmentIDFromContext returns the id of the segment stored in ctx by Segment
+func SegmentIDFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+
+	segmentID, ok := ctx.Value(segmentKey{}).(string)
+	if !ok || segmentID == "" {
+		return "", false
+	}
+
+	return segmentID, true
+}
+
+// ContextTransaction can be implemented by a driver transaction to abort blocking calls when the context is cancelled.
+// The context variants of the transaction container use these methods if available
+type ContextTransaction interface {
+	SegmentStartContext(context.Context, string, string) error
+	InfoContext(context.Context, string, io.ReadCloser) error
+	ErrorContext(context.Context, string, io.ReadCloser) error
+}
+
+// SegmentStartCtx starts a segment in the registered driver transactions.
+// Drivers implementing ContextTransaction receive ctx, every other driver is skipped once ctx is done
+func (tc *TransactionContainer) SegmentStartCtx(ctx context.Context, name string) (string, error) {
+	var ew ErrorWrapper
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	segmentID := tc.telemetry.newID()
+	err := tc.startSegment(segmentID, "", name)
+	if err != nil {
+		return "", err
+	}
+
+	defaults := tc.segmentDefaults()
+	tc.segments.inherit(segmentID, defaults)
+
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+
+	for _, driverName := range tc.driverOrder() {
+		transaction := tc.transactions[driverName]
+		err := ctx.Err()
+		if err == nil {
+			if ct, ok := transaction.(ContextTransaction); ok {
+				err = safeCall(func() error { return ct.SegmentStartContext(ctx, segmentID, name) })
+			} else {
+				err = safeCall(func() error { return transaction.SegmentStart(segmentID, name) })
+			}
+		}
+
+		if err != nil {
+			ew.Add(tc.driverError(driverName, "SegmentStartCtx", err))
+			continue
+		}
+
+		err = tc.applySegmentDefaults(driverName, transaction, segmentID, defaults)
+		if err != nil {
+			ew.Add(err)
+		}
+	}
+
+	return segmentID, ew.Error()
+}
+
+// InfoCtx logs informations in the registered driver transactions.
+// Drivers implementing ContextTransaction receive ctx, every other driver is skipped once ctx is done
+// If segmentID is empty, the info will be logged directly on the transaction
+func (tc *TransactionContainer) InfoCtx(ctx context.Context, segmentID string, msg *string) error {
+	if !tc.telemetry.logEnabled(LevelInfo) {
+		return nil
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	message := tc.telemetry.truncateInfo(tc.telemetry.redactMessage(FieldMessage, messageString(msg)))
+
+	return tc.logMessage(ctx, "InfoCtx", segmentID, LevelInfo, message)
+}
+
+// ErrorCtx logs errors in the registered driver transactions.
+// Drivers implementing ContextTransaction receive ctx, every other driver is skipped once ctx is done
+// If segmentID is empty, the error will be logged directly on the transaction
+func (tc *TransactionContainer) ErrorCtx(ctx context.Context, segmentID string, err *error) error {
+	if !tc.telemetry.logEnabled(LevelError) {
+		return nil
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	message := tc.telemetry.truncateError(tc.telemetry.redactMessage(FieldError, errorString(err)))
+
+	return tc.logMessage(ctx, "ErrorCtx", segmentID, LevelError, message)
+}
+
+// SegmentStartWithContext starts a segment which ends with StatusCancelled once ctx is done before the segment
+// is ended otherwise, e.g. when the client disconnects. If ctx carries a segment of Segment, the new segment is
+// started as its child. The goroutine watching ctx exits when the segment ends
+func (tc *TransactionContainer) SegmentStartWithContext(ctx context.Context, name string) string {
+	var (
+		segmentID string
+		err       error
+	)
+
+	parentSegmentID, ok := SegmentIDFromContext(ctx)
+	if ok {
+		segmentID, err = tc.SegmentStartChild(parentSegmentID, name)
+	} else {
+		segmentID, err = tc.SegmentStartE(name)
+	}
+	if err != nil {
+		log.Print(err)
+	}
+
+	if ctx == nil || ctx.Done() == nil {
+		return segmentID
+	}
+
+	stop := tc.segments.watch(segmentID)
+	if stop == nil {
+		return segmentID
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			select {
+			case <-stop:
+				return
+			default:
+			}
+
+			err := tc.SegmentEndWithStatus(segmentID, StatusCancelled)
+			if err != nil && !errors.Is(err, errSegmentEnded) {
+				log.Printf("telemetry Function: SegmentStartWithContext | Error: %v", err)
+			}
+		case <-stop:
+		}
+	}()
+
+	return segmentID
+}
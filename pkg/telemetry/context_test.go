@@ -0,0 +1,111 @@
+package telemetry_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/telemetrytest"
+)
+
+// segmentEnds returns how often a segment was ended on the recorder
+func segmentEnds(recorder *telemetrytest.RecordingDriver) int {
+	var ends int
+	for _, call := range recorder.Calls() {
+		if call == "SegmentEnd" || call == "SegmentEndWithStatus" {
+			ends++
+		}
+	}
+
+	return ends
+}
+
+// waitForSegmentEnds waits until the recorder saw n segment ends, as the watcher ends cancelled segments asynchronously
+func waitForSegmentEnds(t *testing.T, recorder *telemetrytest.RecordingDriver, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for segmentEnds(recorder) < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d segment ends, got %d", n, segmentEnds(recorder))
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSegmentStartWithContextCancelFirst(t *testing.T) {
+	tel, recorder := telemetrytest.NewTelemetry(t)
+	transaction := start(t, tel, "cancel-first")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	segmentID := transaction.SegmentStartWithContext(ctx, "segment")
+	cancel()
+
+	waitForSegmentEnds(t, recorder, 1)
+	transaction.SegmentEnd(segmentID)
+
+	if n := segmentEnds(recorder); n != 1 {
+		t.Fatalf("expected the segment to be ended once, got %d", n)
+	}
+
+	if status := recorder.Segments()[0].Status; status != telemetry.StatusCancelled {
+		t.Fatalf("expected status %s, got %s", telemetry.StatusCancelled, status)
+	}
+}
+
+func TestSegmentStartWithContextEndFirst(t *testing.T) {
+	tel, recorder := telemetrytest.NewTelemetry(t)
+	transaction := start(t, tel, "end-first")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	segmentID := transaction.SegmentStartWithContext(ctx, "segment")
+	transaction.SegmentEnd(segmentID)
+	cancel()
+
+	time.Sleep(10 * time.Millisecond)
+
+	if n := segmentEnds(recorder); n != 1 {
+		t.Fatalf("expected the segment to be ended once, got %d", n)
+	}
+
+	if status := recorder.Segments()[0].Status; status != telemetry.StatusOK {
+		t.Fatalf("expected status %s, got %s", telemetry.StatusOK, status)
+	}
+}
+
+func TestSegmentStartWithContextConcurrentEndAndCancel(t *testing.T) {
+	tel, recorder := telemetrytest.NewTelemetry(t)
+	transaction := start(t, tel, "race")
+
+	const segments = 100
+
+	var wg sync.WaitGroup
+	for i := 0; i < segments; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		segmentID := transaction.SegmentStartWithContext(ctx, "segment")
+
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			cancel()
+		}()
+		go func() {
+			defer wg.Done()
+			transaction.SegmentEnd(segmentID)
+		}()
+	}
+	wg.Wait()
+	waitForSegmentEnds(t, recorder, segments)
+
+	err := transaction.Done()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := segmentEnds(recorder); n != segments {
+		t.Fatalf("expected %d segment ends, got %d", segments, n)
+	}
+}
@@ -0,0 +1,466 @@
+// Package datadogdriver provides a telemetry driver sending traces to the Datadog APM
+package datadogdriver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// Span tags set by the driver
+const (
+	TagProcessID  = "mc.process_id"
+	TagTrace      = "mc.trace"
+	TagTraceID    = "mc.trace_id"
+	TagLogPrefix  = "log."
+	TagLinkPrefix = "link."
+)
+
+// Driver creates Datadog spans for transactions and segments
+type Driver struct{}
+
+// transaction maps a telemetry transaction to a root span and its segments to child spans
+type transaction struct {
+	mu        sync.Mutex
+	span      ddtrace.Span
+	segments  map[string]ddtrace.Span
+	logs      int
+	links     int
+	trace     string
+	traceID   string
+	processID string
+}
+
+// New starts the global Datadog tracer for the service and environment and returns a *Driver using it.
+// Further tracer options, e.g. the agent address, can be provided with opts
+func New(serviceName string, env string, opts ...tracer.StartOption) telemetry.Driver {
+	opts = append([]tracer.StartOption{
+		tracer.WithService(serviceName),
+		tracer.WithEnv(env),
+	}, opts...)
+
+	tracer.Start(opts...)
+
+	return &Driver{}
+}
+
+// Factory starts the global Datadog tracer for telemetry.RegisterDriverFactory with the configured service name,
+// the configured agent address, e.g. localhost:8126, as endpoint and the configured sample rate
+func Factory(settings telemetry.DriverSettings) (telemetry.Driver, error) {
+	var opts []tracer.StartOption
+	if settings.Endpoint != "" {
+		opts = append(opts, tracer.WithAgentAddr(settings.Endpoint))
+	}
+
+	if settings.SampleRate < 1 {
+		opts = append(opts, tracer.WithSampler(tracer.NewRateSampler(settings.SampleRate)))
+	}
+
+	return New(settings.ServiceName, "", opts...), nil
+}
+
+// Stop flushes all finished spans and stops the global Datadog tracer
+func (d *Driver) Stop() {
+	tracer.Stop()
+}
+
+// Shutdown stops the global Datadog tracer like Stop, so the driver can be shut down with telemetry.Shutdown
+func (d *Driver) Shutdown(context.Context) error {
+	d.Stop()
+
+	return nil
+}
+
+// InitializeTransaction starts the root span of the transaction
+func (d *Driver) InitializeTransaction(name string) (telemetry.Transaction, error) {
+	return &transaction{
+		span:     tracer.StartSpan(name, tracer.ResourceName(name)),
+		segments: make(map[string]ddtrace.Span),
+	}, nil
+}
+
+// Start renames the root span
+func (t *transaction) Start(name string) {
+	t.span.SetOperationName(name)
+	t.span.SetTag(ext.ResourceName, name)
+}
+
+// SetName renames the root span
+func (t *transaction) SetName(name string) error {
+	t.Start(name)
+
+	return nil
+}
+
+// AddTransactionAttribute sets the attribute as tag of the root span
+func (t *transaction) AddTransactionAttribute(key string, value any) error {
+	t.span.SetTag(key, tagValue(value))
+
+	return nil
+}
+
+// SegmentStart starts a child span of the root span
+func (t *transaction) SegmentStart(segmentID string, name string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.segments[segmentID] = tracer.StartSpan(name, tracer.ResourceName(name), tracer.ChildOf(t.span.Context()))
+
+	return nil
+}
+
+// SegmentStartChild starts a child span of the parent segment span
+func (t *transaction) SegmentStartChild(parentID string, segmentID string, name string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	parent, ok := t.segments[parentID]
+	if !ok {
+		return fmt.Errorf("segment %s not found", parentID)
+	}
+
+	t.segments[segmentID] = tracer.StartSpan(name, tracer.ResourceName(name), tracer.ChildOf(parent.Context()))
+
+	return nil
+}
+
+// AddSegmentAttribute sets the attribute as tag of the segment span
+func (t *transaction) AddSegmentAttribute(segmentID string, key string, value any) error {
+	span, err := t.segmentSpan(segmentID)
+	if err != nil {
+		return err
+	}
+
+	span.SetTag(key, tagValue(value))
+
+	return nil
+}
+
+// AddSegmentAttributes sets all attributes as tags of the segment span
+func (t *transaction) AddSegmentAttributes(segmentID string, attributes map[string]any) error {
+	span, err := t.segmentSpan(segmentID)
+	if err != nil {
+		return err
+	}
+
+	for key, value := range attributes {
+		span.SetTag(key, tagValue(value))
+	}
+
+	return nil
+}
+
+// AddSegmentEvent sets the event as log tag of the segment span, Datadog spans have no events.
+// The attributes are set as tags below the log tag
+func (t *transaction) AddSegmentEvent(segmentID string, name string, attributes map[string]any) error {
+	span, err := t.segmentSpan(segmentID)
+	if err != nil {
+		return err
+	}
+
+	key := t.logKey("event")
+	span.SetTag(key, name)
+	for attributeKey, value := range attributes {
+		span.SetTag(key+"."+attributeKey, tagValue(value))
+	}
+
+	return nil
+}
+
+// SegmentEnd finishes the segment span
+func (t *transaction) SegmentEnd(segmentID string) error {
+	return t.SegmentEndWithStatus(segmentID, telemetry.StatusOK)
+}
+
+// SegmentEndWithStatus finishes the segment span and marks it as error if the status is neither StatusOK nor StatusDropped.
+// The Datadog tracer only sends traces whose spans are all finished, so spans with StatusDropped are finished as well
+func (t *transaction) SegmentEndWithStatus(segmentID string, status telemetry.SegmentStatus) error {
+	t.mu.Lock()
+	span, ok := t.segments[segmentID]
+	delete(t.segments, segmentID)
+	t.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("segment %s not found", segmentID)
+	}
+
+	if status != telemetry.StatusOK && status != telemetry.StatusDropped {
+		span.Finish(tracer.WithError(errors.New(status.String())))
+		return nil
+	}
+
+	span.Finish()
+
+	return nil
+}
+
+// AddLink sets the linked trace and its attributes as tags of the root span, e.g. link.1.trace.
+// The Datadog tracer does not support span links
+func (t *transaction) AddLink(trace string, attributes map[string]any) error {
+	t.mu.Lock()
+	t.links++
+	prefix := fmt.Sprintf("%s%d.", TagLinkPrefix, t.links)
+	t.mu.Unlock()
+
+	t.span.SetTag(prefix+"trace", trace)
+	for key, value := range attributes {
+		t.span.SetTag(prefix+key, tagValue(value))
+	}
+
+	return nil
+}
+
+// SetStatus marks the root span as error for TransactionStatusError and clears the error otherwise
+func (t *transaction) SetStatus(status telemetry.TransactionStatus) error {
+	t.span.SetTag(ext.Error, status != telemetry.TransactionStatusOK)
+
+	return nil
+}
+
+// Flush sends the finished spans of the global Datadog tracer to the agent and waits until they are sent
+func (t *transaction) Flush() error {
+	tracer.Flush()
+
+	return nil
+}
+
+// Done finishes all open segment spans and the root span.
+// The finished trace is sent by the tracer in the background, Flush or Stop of the driver sends it immediately
+func (t *transaction) Done() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, span := range t.segments {
+		span.Finish()
+	}
+
+	t.span.Finish()
+
+	return nil
+}
+
+// Info sets the message as log tag of the span
+func (t *transaction) Info(segmentID string, rc io.ReadCloser) error {
+	return t.log("info", segmentID, rc)
+}
+
+// Warn sets the message as log tag of the span
+func (t *transaction) Warn(segmentID string, rc io.ReadCloser) error {
+	return t.log("warn", segmentID, rc)
+}
+
+// Debug sets the message as log tag of the span
+func (t *transaction) Debug(segmentID string, rc io.ReadCloser) error {
+	return t.log("debug", segmentID, rc)
+}
+
+// Error sets the message as log tag of the span and marks the span as error
+func (t *transaction) Error(segmentID string, rc io.ReadCloser) error {
+	defer rc.Close()
+
+	msg, err := io.ReadAll(rc)
+	if err != nil {
+		return err
+	}
+
+	span, err := t.logSpan(segmentID)
+	if err != nil {
+		return err
+	}
+
+	span.SetTag(t.logKey("error"), string(msg))
+	span.SetTag(ext.Error, errors.New(string(msg)))
+
+	return nil
+}
+
+// InfoFields sets the fields as log tags of the span
+func (t *transaction) InfoFields(segmentID string, fields map[string]any) error {
+	span, err := t.logSpan(segmentID)
+	if err != nil {
+		return err
+	}
+
+	prefix := t.logKey("info")
+	for key, value := range fields {
+		span.SetTag(prefix+"."+key, tagValue(value))
+	}
+
+	return nil
+}
+
+// ErrorFields sets the fields as log tags of the span and marks the span as error
+func (t *transaction) ErrorFields(segmentID string, fields map[string]any) error {
+	span, err := t.logSpan(segmentID)
+	if err != nil {
+		return err
+	}
+
+	prefix := t.logKey("error")
+	for key, value := range fields {
+		span.SetTag(prefix+"."+key, tagValue(value))
+	}
+
+	span.SetTag(ext.Error, fmt.Errorf("%v", fields[telemetry.FieldError]))
+
+	return nil
+}
+
+// CreateTrace returns the Datadog trace id of the root span
+func (t *transaction) CreateTrace() (string, error) {
+	return strconv.FormatUint(t.span.Context().TraceID(), 10), nil
+}
+
+// SetTrace sets the trace. A trace which differs from the Datadog trace id is added as tag
+func (t *transaction) SetTrace(trace string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.trace = trace
+	t.traceID = trace
+	if trace != strconv.FormatUint(t.span.Context().TraceID(), 10) {
+		t.span.SetTag(TagTrace, trace)
+	}
+
+	return nil
+}
+
+// Trace returns the trace set with SetTrace or the Datadog trace id
+func (t *transaction) Trace() (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.trace == "" {
+		return strconv.FormatUint(t.span.Context().TraceID(), 10), nil
+	}
+
+	return t.trace, nil
+}
+
+// TraceID returns the trace id set with SetTrace or SetTraceID or the Datadog trace id
+func (t *transaction) TraceID() (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.traceID == "" {
+		return strconv.FormatUint(t.span.Context().TraceID(), 10), nil
+	}
+
+	return t.traceID, nil
+}
+
+// SetTraceID sets the trace id of the trace driver as tag of the root span
+func (t *transaction) SetTraceID(traceID string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.traceID = traceID
+	t.span.SetTag(TagTraceID, traceID)
+
+	return nil
+}
+
+// Erase removes all segments
+func (t *transaction) Erase() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	clear(t.segments)
+}
+
+// CreateProcessID creates a new random process id
+func (t *transaction) CreateProcessID() (string, error) {
+	return uuid.NewString(), nil
+}
+
+// SetProcessID sets the process id as tag of the root span
+func (t *transaction) SetProcessID(processID string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.processID = processID
+	t.span.SetTag(TagProcessID, processID)
+
+	return nil
+}
+
+// ProcessID returns the process id
+func (t *transaction) ProcessID() (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.processID, nil
+}
+
+// log drains the message, already limited by the container, and sets it as log tag of the span
+func (t *transaction) log(level string, segmentID string, rc io.ReadCloser) error {
+	defer rc.Close()
+
+	msg, err := io.ReadAll(rc)
+	if err != nil {
+		return err
+	}
+
+	span, err := t.logSpan(segmentID)
+	if err != nil {
+		return err
+	}
+
+	span.SetTag(t.logKey(level), string(msg))
+
+	return nil
+}
+
+// logKey returns a unique tag key for the next log message, e.g. log.3.info
+func (t *transaction) logKey(level string) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.logs++
+
+	return fmt.Sprintf("%s%d.%s", TagLogPrefix, t.logs, level)
+}
+
+// segmentSpan returns the span of the segment
+func (t *transaction) segmentSpan(segmentID string) (ddtrace.Span, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	span, ok := t.segments[segmentID]
+	if !ok {
+		return nil, fmt.Errorf("segment %s not found", segmentID)
+	}
+
+	return span, nil
+}
+
+// logSpan returns the root span for an empty segmentID and the segment span otherwise
+func (t *transaction) logSpan(segmentID string) (ddtrace.Span, error) {
+	if segmentID == "" {
+		return t.span, nil
+	}
+
+	return t.segmentSpan(segmentID)
+}
+
+// tagValue converts values the Datadog tracer does not support natively into strings
+func tagValue(value any) any {
+	switch v := value.(type) {
+	case time.Duration:
+		return v.String()
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+
+	return value
+}
@@ -0,0 +1,145 @@
+package datadogdriver_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/datadogdriver"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+)
+
+// newTelemetry replaces the global Datadog tracer with a mock tracer and returns a telemetry instance
+// with the driver as driver and trace driver. The mock tracer is stopped at the end of the test
+func newTelemetry(t *testing.T) (*telemetry.Telemetry, mocktracer.Tracer) {
+	t.Helper()
+
+	mt := mocktracer.Start()
+	t.Cleanup(mt.Stop)
+
+	tel := telemetry.New()
+	err := tel.RegisterDriver("datadog", &datadogdriver.Driver{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tel.SetDriver("datadog")
+	tel.SetTraceDriver("datadog")
+
+	return tel, mt
+}
+
+// finishedSpan returns the finished span with the operation name
+func finishedSpan(t *testing.T, mt mocktracer.Tracer, name string) mocktracer.Span {
+	t.Helper()
+
+	for _, span := range mt.FinishedSpans() {
+		if span.OperationName() == name {
+			return span
+		}
+	}
+
+	t.Fatalf("expected a finished span %s, got %v", name, mt.FinishedSpans())
+
+	return nil
+}
+
+func TestSegmentsBecomeSpans(t *testing.T) {
+	tel, mt := newTelemetry(t)
+
+	transaction, err := tel.Start("checkout")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transaction.AddTransactionAttribute("customer.id", 7)
+	err = transaction.SetProcessID("process")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	segmentID := transaction.SegmentStart("load cart")
+	transaction.AddSegmentAttribute(segmentID, "cart.items", 3)
+
+	childID, err := transaction.SegmentStartChild(segmentID, "query")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transaction.SegmentEnd(childID)
+	transaction.SegmentEnd(segmentID)
+
+	err = transaction.Done()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(mt.FinishedSpans()) != 3 {
+		t.Fatalf("expected 3 finished spans, got %d", len(mt.FinishedSpans()))
+	}
+
+	root := finishedSpan(t, mt, "checkout")
+	segment := finishedSpan(t, mt, "load cart")
+	child := finishedSpan(t, mt, "query")
+
+	if segment.ParentID() != root.SpanID() || child.ParentID() != segment.SpanID() {
+		t.Error("expected the segment spans to be children of their parents")
+	}
+
+	if root.Tag("customer.id") != 7 || root.Tag(datadogdriver.TagProcessID) != "process" {
+		t.Errorf("expected the transaction attributes as root span tags, got %v", root.Tags())
+	}
+
+	if segment.Tag("cart.items") != 3 || segment.Tag(ext.ResourceName) != "load cart" {
+		t.Errorf("expected the segment attributes as span tags, got %v", segment.Tags())
+	}
+
+	if segment.Tag(ext.Error) != nil {
+		t.Errorf("expected no error tag, got %v", segment.Tag(ext.Error))
+	}
+}
+
+func TestErrorsSetTag(t *testing.T) {
+	tel, mt := newTelemetry(t)
+
+	transaction, err := tel.Start("checkout")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failedID := transaction.SegmentStart("payment")
+	err = transaction.SegmentEndWithStatus(failedID, telemetry.StatusError)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	droppedID := transaction.SegmentStart("cache")
+	err = transaction.SegmentEndWithStatus(droppedID, telemetry.StatusDropped)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loggedID := transaction.SegmentStart("shipping")
+	segmentErr := errors.New("carrier unavailable")
+	transaction.Error(loggedID, &segmentErr)
+	transaction.SegmentEnd(loggedID)
+
+	err = transaction.Done()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := finishedSpan(t, mt, "payment").Tag(ext.Error).(error); !ok {
+		t.Error("expected StatusError to set the error tag")
+	}
+
+	if tag := finishedSpan(t, mt, "cache").Tag(ext.Error); tag != nil {
+		t.Errorf("expected a dropped segment to be finished without error, got %v", tag)
+	}
+
+	tag, ok := finishedSpan(t, mt, "shipping").Tag(ext.Error).(error)
+	if !ok || tag.Error() != "carrier unavailable" {
+		t.Errorf("expected the logged error as error tag, got %v", tag)
+	}
+}
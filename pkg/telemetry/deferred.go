@@ -0,0 +1,67 @@
+package telemetry
+
+import "maps"
+
+// SetDeferredSegmentAttributes makes AddSegmentAttribute and AddSegmentAttributes of this container collect the
+// attributes of a segment and pass them to the drivers with a single AddSegmentAttributes call when the segment ends.
+// It reduces the calls for drivers doing a network round-trip per attribute, the attributes are not visible in the
+// backend until the segment ends. Attributes of segments ending with StatusDropped are discarded
+func (tc *TransactionContainer) SetDeferredSegmentAttributes(deferred bool) {
+	tc.record.mu.Lock()
+	defer tc.record.mu.Unlock()
+
+	tc.record.deferSegmentAttributes = deferred
+}
+
+// defersSegmentAttributes reports whether segment attributes are collected until the segment ends
+func (tc *TransactionContainer) defersSegmentAttributes() bool {
+	tc.record.mu.Lock()
+	defer tc.record.mu.Unlock()
+
+	return tc.record.deferSegmentAttributes
+}
+
+// deferSegmentAttributes collects the attributes for the end of the segment if deferred segment attributes are enabled.
+// It reports whether the attributes were collected, attributes of unknown or ended segments are not
+func (tc *TransactionContainer) deferSegmentAttributes(segmentID string, attributes map[string]any) bool {
+	if !tc.defersSegmentAttributes() {
+		return false
+	}
+
+	return tc.segments.deferAttributes(segmentID, attributes)
+}
+
+// deferAttributes adds the attributes to the pending attributes of an active segment and reports whether it did
+func (sr *segmentRegistry) deferAttributes(segmentID string, attributes map[string]any) bool {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	segment, ok := sr.segments[segmentID]
+	if !ok || segment.ended {
+		return false
+	}
+
+	if segment.pending == nil {
+		segment.pending = make(map[string]any, len(attributes))
+	}
+
+	maps.Copy(segment.pending, attributes)
+
+	return true
+}
+
+// takePending removes and returns the pending attributes of a segment
+func (sr *segmentRegistry) takePending(segmentID string) map[string]any {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	segment, ok := sr.segments[segmentID]
+	if !ok {
+		return nil
+	}
+
+	pending := segment.pending
+	segment.pending = nil
+
+	return pending
+}
@@ -0,0 +1,81 @@
+package telemetry_test
+
+import (
+	"testing"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/telemetrytest"
+)
+
+// attributeCalls returns the number of AddSegmentAttribute and AddSegmentAttributes calls of the recorder
+func attributeCalls(recorder *telemetrytest.RecordingDriver) int {
+	var calls int
+	for _, call := range recorder.Calls() {
+		if call == "AddSegmentAttribute" || call == "AddSegmentAttributes" {
+			calls++
+		}
+	}
+
+	return calls
+}
+
+func TestDeferredSegmentAttributes(t *testing.T) {
+	tel, recorder := telemetrytest.NewTelemetry(t)
+	transaction := start(t, tel, "deferred")
+	transaction.SetDeferredSegmentAttributes(true)
+
+	segmentID := transaction.SegmentStart("segment")
+	for key, value := range benchmarkAttributes {
+		transaction.AddSegmentAttribute(segmentID, key, value)
+	}
+
+	if calls := attributeCalls(recorder); calls != 0 {
+		t.Fatalf("expected no attribute calls before the segment ends, got %d", calls)
+	}
+
+	transaction.SegmentEnd(segmentID)
+
+	if calls := attributeCalls(recorder); calls != 1 {
+		t.Fatalf("expected a single attribute call when the segment ends, got %d", calls)
+	}
+
+	for key, value := range benchmarkAttributes {
+		recorder.AssertAttribute(t, key, value)
+	}
+}
+
+func BenchmarkDeferredSegmentAttributes(b *testing.B) {
+	for _, bm := range []struct {
+		name     string
+		deferred bool
+	}{
+		{name: "immediate"},
+		{name: "deferred", deferred: true},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			tel, recorder := telemetrytest.NewTelemetry(b)
+			transaction := start(b, tel, "benchmark")
+			defer transaction.Done()
+			transaction.SetDeferredSegmentAttributes(bm.deferred)
+
+			var calls int
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				segmentID := transaction.SegmentStart("segment")
+				for key, value := range benchmarkAttributes {
+					transaction.AddSegmentAttribute(segmentID, key, value)
+				}
+				transaction.SegmentEnd(segmentID)
+
+				b.StopTimer()
+				calls += attributeCalls(recorder)
+				recorder.Reset()
+				b.StartTimer()
+			}
+
+			b.ReportMetric(float64(calls)/float64(b.N), "driver-calls/op")
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package telemetry
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrTraceDriverNotSet is returned if no trace driver is configured
+var ErrTraceDriverNotSet = errors.New("no telemetry trace driver configured")
+
+// ErrNoDriverConfigured is returned by Start in strict mode if no driver is set
+var ErrNoDriverConfigured = errors.New("no telemetry driver configured")
+
+// ErrNilSampler is returned by Validate if SetSampler was called with a nil sampler
+var ErrNilSampler = errors.New("telemetry sampler is nil")
+
+// ErrDriverNotRegistered is returned if a configured driver is not registered
+type ErrDriverNotRegistered struct {
+	Name string
+}
+
+// Error returns the message with the driver name
+func (e ErrDriverNotRegistered) Error() string {
+	return fmt.Sprintf("telemetry driver %q not registered", e.Name)
+}
+
+// ErrUnsupportedDriverSettings is returned by ConfigureFromConfig if settings are configured for a driver
+// which was not created by a DriverFactory and therefore cannot apply them
+type ErrUnsupportedDriverSettings struct {
+	Name string
+	Keys []string
+}
+
+// Error returns the message with the driver name and the configured keys
+func (e ErrUnsupportedDriverSettings) Error() string {
+	return fmt.Sprintf("telemetry driver %q has no factory to apply %s", e.Name, strings.Join(e.Keys, ", "))
+}
+
+// ErrTraceDriverNotLoaded is returned by Validate if a trace driver is not among the loaded drivers
+type ErrTraceDriverNotLoaded struct {
+	Name string
+}
+
+// Error returns the message with the driver name
+func (e ErrTraceDriverNotLoaded) Error() string {
+	return fmt.Sprintf("telemetry trace driver %q not loaded", e.Name)
+}
+
+// ErrDriverPanic is returned instead of the result of a driver method which panicked
+type ErrDriverPanic struct {
+	Value any
+	Stack []byte
+}
+
+// Error returns the panic value
+func (e ErrDriverPanic) Error() string {
+	return fmt.Sprintf("driver panicked: %v", e.Value)
+}
+
+// ErrDriverMethod wraps an error returned by a driver method
+type ErrDriverMethod struct {
+	Driver   string
+	Function string
+	Err      error
+}
+
+// Error returns the message with driver and function name
+func (e ErrDriverMethod) Error() string {
+	return fmt.Sprintf("%s%s Function: %s | Error: %v", TelemetryDriverError, e.Driver, e.Function, e.Err)
+}
+
+// Unwrap returns the error of the driver
+func (e ErrDriverMethod) Unwrap() error {
+	return e.Err
+}
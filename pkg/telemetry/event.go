@@ -0,0 +1,43 @@
+package telemetry
+
+import "fmt"
+
+// AddSegmentEvent adds a point in time event with attributes to the segment in the registered driver transactions,
+// e.g. a cache miss or a retry. Unlike attributes, which describe the whole segment, events mark a moment in it.
+// Attributes with an unsupported type are dropped. Drivers without event support log the event instead
+func (tc *TransactionContainer) AddSegmentEvent(segmentID string, name string, attributes map[string]any) error {
+	var ew ErrorWrapper
+
+	if name == "" {
+		return fmt.Errorf("event name must not be empty")
+	}
+
+	err := tc.segments.active(segmentID)
+	if err != nil {
+		return err
+	}
+
+	attributes, err = tc.validAttributes(attributes)
+	if err != nil {
+		ew.Add(err)
+	}
+
+	tc.segments.addEvent(segmentID, EventSnapshot{
+		Name:       name,
+		Attributes: attributes,
+		Time:       tc.telemetry.now(),
+	})
+
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+
+	for _, driverName := range tc.driverOrder() {
+		transaction := tc.transactions[driverName]
+		err := safeCall(func() error { return transaction.AddSegmentEvent(segmentID, name, attributes) })
+		if err != nil {
+			ew.Add(tc.driverError(driverName, "AddSegmentEvent", err))
+		}
+	}
+
+	return ew.Error()
+}
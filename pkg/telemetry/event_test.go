@@ -0,0 +1,56 @@
+package telemetry_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/telemetrytest"
+)
+
+func TestSegmentEventsKeepTheirOrder(t *testing.T) {
+	tel, recorder := telemetrytest.NewTelemetry(t)
+	clock := telemetrytest.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	tel.SetClock(clock.Now)
+	tel.SetSnapshotEnabled(true)
+	transaction := start(t, tel, "events")
+
+	segmentID := transaction.SegmentStart("segment")
+	names := []string{"cache miss", "retry 1", "retry 2"}
+	for i, name := range names {
+		clock.Advance(time.Millisecond)
+		err := transaction.AddSegmentEvent(segmentID, name, map[string]any{"attempt": i})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	events := recorder.Events()
+	if len(events) != len(names) {
+		t.Fatalf("expected %d events, got %d", len(names), len(events))
+	}
+
+	for i, event := range events {
+		if event.Name != names[i] || event.SegmentID != segmentID || event.Attributes["attempt"] != i {
+			t.Errorf("event %d: unexpected %+v", i, event)
+		}
+	}
+
+	snapshotEvents := transaction.Snapshot().Segments[0].Events
+	for i := 1; i < len(snapshotEvents); i++ {
+		if !snapshotEvents[i].Time.After(snapshotEvents[i-1].Time) {
+			t.Errorf("expected event %d after event %d", i, i-1)
+		}
+	}
+
+	transaction.SegmentEnd(segmentID)
+
+	err := transaction.AddSegmentEvent(segmentID, "late", nil)
+	if err == nil {
+		t.Fatal("expected an error for an event on an ended segment")
+	}
+
+	err = transaction.AddSegmentEvent(segmentID, "", nil)
+	if err == nil {
+		t.Fatal("expected an error for an empty event name")
+	}
+}
@@ -0,0 +1,153 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+)
+
+// Reserved field keys. Values provided by the caller under these keys are moved to FieldPrefix + key
+const (
+	FieldMessage         = "msg"
+	FieldError           = "error"
+	FieldCode            = "code"
+	FieldSeverity        = "severity"
+	FieldContentEncoding = "content_encoding"
+	FieldPrefix          = "field."
+)
+
+// InfoFields logs structured fields as info in the registered driver transactions
+// If segmentID is empty, the info will be logged directly on the transaction
+func (tc *TransactionContainer) InfoFields(segmentID string, fields map[string]any) error {
+	return tc.infoFields(segmentID, LevelInfo, fields, nil)
+}
+
+// infoFields logs the normalized fields and the reserved fields with InfoFields in the registered driver transactions.
+// The level decides whether the fields are logged and is retained in the snapshot
+func (tc *TransactionContainer) infoFields(segmentID string, level Level, fields map[string]any, reserved map[string]any) error {
+	var ew ErrorWrapper
+
+	if !tc.telemetry.logEnabled(level) {
+		return nil
+	}
+
+	fields = normalizeFields(tc.withBaggage(fields))
+	maps.Copy(fields, reserved)
+	fields = tc.telemetry.redactFields(fields)
+	tc.recordLog(segmentID, level, "", fields)
+
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+
+	for _, driverName := range tc.driverOrder() {
+		transaction := tc.transactions[driverName]
+		err := safeCall(func() error { return transaction.InfoFields(segmentID, fields) })
+		if err != nil {
+			ew.Add(tc.driverError(driverName, "InfoFields", err))
+		}
+	}
+
+	return ew.Error()
+}
+
+// ErrorFields logs the error with structured fields in the registered driver transactions.
+// The error message is added under the FieldError key
+// If segmentID is empty, the error will be logged directly on the transaction
+func (tc *TransactionContainer) ErrorFields(segmentID string, err error, fields map[string]any) error {
+	return tc.errorFields(segmentID, err, fields, nil)
+}
+
+// ErrorWithCode logs the error with a code in the registered driver transactions, so alerting rules can match
+// the code instead of the message. The code is added under the FieldCode key and the OTel severity number
+// of LevelError under the FieldSeverity key
+// If segmentID is empty, the error will be logged directly on the transaction
+func (tc *TransactionContainer) ErrorWithCode(segmentID string, code string, err error) error {
+	return tc.errorFields(segmentID, err, nil, map[string]any{
+		FieldCode:     code,
+		FieldSeverity: LevelError.OTelSeverity(),
+	})
+}
+
+// errorFields logs the error with the normalized fields and the reserved fields in the registered driver transactions
+func (tc *TransactionContainer) errorFields(segmentID string, err error, fields map[string]any, reserved map[string]any) error {
+	var ew ErrorWrapper
+
+	if !tc.telemetry.logEnabled(LevelError) {
+		return nil
+	}
+
+	fields = normalizeFields(tc.withBaggage(fields))
+	if err != nil {
+		fields[FieldError] = err.Error()
+	}
+	maps.Copy(fields, reserved)
+	fields = tc.telemetry.redactFields(fields)
+	tc.recordLog(segmentID, LevelError, "", fields)
+
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+
+	for _, driverName := range tc.driverOrder() {
+		transaction := tc.transactions[driverName]
+		err := safeCall(func() error { return transaction.ErrorFields(segmentID, fields) })
+		if err != nil {
+			ew.Add(tc.driverError(driverName, "ErrorFields", err))
+		}
+	}
+
+	return ew.Error()
+}
+
+// normalizeFields returns a flat copy of the fields.
+// Nested maps are flattened with dot separated keys, values which are no supported attribute types
+// are JSON encoded and reserved keys are prefixed with FieldPrefix
+func normalizeFields(fields map[string]any) map[string]any {
+	normalized := make(map[string]any, len(fields))
+	flattenFields(normalized, "", fields)
+
+	for _, key := range []string{FieldMessage, FieldError, FieldCode, FieldSeverity, FieldContentEncoding} {
+		value, ok := normalized[key]
+		if !ok {
+			continue
+		}
+
+		delete(normalized, key)
+		normalized[FieldPrefix+key] = value
+	}
+
+	return normalized
+}
+
+// flattenFields adds the fields to dst with prefix prepended to every key
+func flattenFields(dst map[string]any, prefix string, fields map[string]any) {
+	for key, value := range fields {
+		if nested, ok := value.(map[string]any); ok {
+			flattenFields(dst, prefix+key+".", nested)
+			continue
+		}
+
+		dst[prefix+key] = fieldValue(value)
+	}
+}
+
+// fieldValue returns the value if it is a valid attribute and its JSON encoding otherwise
+func fieldValue(value any) any {
+	if value == nil {
+		return nil
+	}
+
+	if err, ok := value.(error); ok {
+		return err.Error()
+	}
+
+	if validateAttribute("", value) == nil {
+		return value
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(encoded)
+}
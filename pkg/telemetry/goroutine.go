@@ -0,0 +1,78 @@
+package telemetry
+
+import (
+	"fmt"
+	"log"
+	"runtime/debug"
+)
+
+// Go starts a child segment of the parent segment and runs fn with it on a new goroutine.
+// fn receives a copy of the container whose segment stack holds the new segment, so segments begun with
+// BeginSegment inside fn are its children. An empty parentSegmentID starts a top level segment.
+// A panic of fn is recovered and logged on the segment with its stack, the segment then ends with StatusError.
+// Otherwise the segment ends with StatusOK once fn returns
+func (tc *TransactionContainer) Go(parentSegmentID string, name string, fn func(tc *TransactionContainer)) {
+	var (
+		segmentID string
+		err       error
+	)
+
+	if parentSegmentID != "" {
+		segmentID, err = tc.SegmentStartChild(parentSegmentID, name)
+	} else {
+		segmentID, err = tc.SegmentStartE(name)
+	}
+	if err != nil {
+		log.Printf("telemetry Function: Go | Error: %v", err)
+	}
+
+	scoped := *tc
+	scoped.stack = &segmentStack{}
+	if segmentID != "" {
+		scoped.stack.push(segmentID)
+	}
+
+	go func() {
+		status := StatusOK
+
+		defer func() {
+			r := recover()
+			if r != nil {
+				tc.recordSegmentPanic(segmentID, r)
+				status = StatusError
+			}
+
+			if segmentID == "" {
+				return
+			}
+
+			err := tc.SegmentEndWithStatus(segmentID, status)
+			if err != nil {
+				log.Printf("telemetry Function: Go | Error: %v", err)
+			}
+		}()
+
+		fn(&scoped)
+	}()
+}
+
+// recordSegmentPanic logs the panic value and stack on the segment without ending the transaction
+func (tc *TransactionContainer) recordSegmentPanic(segmentID string, r any) {
+	stack := debug.Stack()
+
+	var err error
+	if rErr, ok := r.(error); ok {
+		err = fmt.Errorf("panic: %w", rErr)
+	} else {
+		err = fmt.Errorf("panic: %v", r)
+	}
+
+	if segmentID != "" {
+		tc.AddSegmentAttributes(segmentID, map[string]any{
+			PanicValueAttribute: fmt.Sprint(r),
+			PanicStackAttribute: string(stack),
+		})
+	}
+
+	tc.Error(segmentID, &err)
+}
@@ -0,0 +1,70 @@
+package telemetry_test
+
+import (
+	"testing"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/telemetrytest"
+)
+
+func TestGoConcurrentSegments(t *testing.T) {
+	const goroutines = 50
+
+	tel, recorder := telemetrytest.NewTelemetry(t)
+	transaction := start(t, tel, "go")
+	parentID := transaction.SegmentStart("parent")
+
+	for i := 0; i < goroutines; i++ {
+		transaction.Go(parentID, "worker", func(tc *telemetry.TransactionContainer) {
+			childID := tc.BeginSegment("child")
+			tc.AddSegmentAttribute(childID, "worker", true)
+
+			msg := "working"
+			tc.Info(childID, &msg)
+			tc.EndSegment()
+		})
+	}
+
+	waitForSegmentEnds(t, recorder, 2*goroutines)
+	transaction.SegmentEnd(parentID)
+
+	segments := recorder.Segments()
+	workers := make(map[string]bool)
+	for _, segment := range segments {
+		if segment.Name == "worker" {
+			if segment.ParentID != parentID || segment.Status != telemetry.StatusOK {
+				t.Errorf("unexpected worker segment %+v", segment)
+			}
+
+			workers[segment.ID] = true
+		}
+	}
+
+	if len(workers) != goroutines {
+		t.Fatalf("expected %d worker segments, got %d", goroutines, len(workers))
+	}
+
+	for _, segment := range segments {
+		if segment.Name == "child" && !workers[segment.ParentID] {
+			t.Errorf("expected child segment %s below a worker, got parent %s", segment.ID, segment.ParentID)
+		}
+	}
+}
+
+func TestGoRecoversPanic(t *testing.T) {
+	tel, recorder := telemetrytest.NewTelemetry(t)
+	transaction := start(t, tel, "go")
+
+	transaction.Go("", "worker", func(*telemetry.TransactionContainer) {
+		panic("worker failed")
+	})
+
+	waitForSegmentEnds(t, recorder, 1)
+
+	segment := recorder.Segments()[0]
+	if segment.Status != telemetry.StatusError {
+		t.Errorf("expected StatusError, got %v", segment.Status)
+	}
+
+	recorder.AssertAttribute(t, telemetry.PanicValueAttribute, "worker failed")
+}
@@ -0,0 +1,257 @@
+// Package grpcmw provides gRPC interceptors which wrap every RPC in a telemetry transaction
+package grpcmw
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Attribute names added to every transaction
+const (
+	AttributeMethod = "rpc.method"
+	AttributeStatus = "rpc.grpc.status_code"
+)
+
+// Option configures the interceptors
+type Option func(*config)
+
+// config holds the interceptor configuration
+type config struct {
+	telemetry       *telemetry.Telemetry
+	messageSegments bool
+}
+
+// WithTelemetry uses the provided telemetry instance instead of the default one
+func WithTelemetry(t *telemetry.Telemetry) Option {
+	return func(c *config) {
+		c.telemetry = t
+	}
+}
+
+// WithMessageSegments records every received and sent stream message as segment
+func WithMessageSegments() Option {
+	return func(c *config) {
+		c.messageSegments = true
+	}
+}
+
+// newConfig returns the configuration with all options applied
+func newConfig(opts []Option) config {
+	cfg := config{
+		telemetry: telemetry.Default(),
+	}
+
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return cfg
+}
+
+// UnaryServerInterceptor starts a transaction for every unary RPC and stores it in the context.
+// An incoming W3C traceparent in the metadata is used as trace of the transaction
+func UnaryServerInterceptor(name string, opts ...Option) grpc.UnaryServerInterceptor {
+	cfg := newConfig(opts)
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, transaction, err := cfg.telemetry.StartContext(ctx, name)
+		if err != nil {
+			log.Printf("telemetry interceptor could not start transaction: %v", err)
+			return handler(ctx, req)
+		}
+		defer transaction.Done()
+
+		segmentID := startRPC(ctx, &transaction, info.FullMethod)
+
+		resp, err := handler(ctx, req)
+		endRPC(&transaction, segmentID, err)
+
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor starts a transaction for every streaming RPC and stores it in the stream context.
+// An incoming W3C traceparent in the metadata is used as trace of the transaction
+func StreamServerInterceptor(name string, opts ...Option) grpc.StreamServerInterceptor {
+	cfg := newConfig(opts)
+
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, transaction, err := cfg.telemetry.StartContext(ss.Context(), name)
+		if err != nil {
+			log.Printf("telemetry interceptor could not start transaction: %v", err)
+			return handler(srv, ss)
+		}
+		defer transaction.Done()
+
+		segmentID := startRPC(ctx, &transaction, info.FullMethod)
+
+		err = handler(srv, &serverStream{
+			ServerStream:    ss,
+			ctx:             ctx,
+			transaction:     &transaction,
+			messageSegments: cfg.messageSegments,
+		})
+		endRPC(&transaction, segmentID, err)
+
+		return err
+	}
+}
+
+// UnaryClientInterceptor injects the trace of the transaction in the context into the outgoing metadata
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(inject(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor injects the trace of the transaction in the context into the outgoing metadata
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(inject(ctx), desc, cc, method, opts...)
+	}
+}
+
+// startRPC sets the incoming trace, adds the method attribute and starts the RPC segment
+func startRPC(ctx context.Context, transaction *telemetry.TransactionContainer, method string) string {
+	h := incomingTraceContext(ctx)
+
+	trace, err := telemetry.ExtractHTTP(h)
+	if err == nil {
+		err = transaction.SetTrace(trace)
+		transaction.SetTraceState(telemetry.ExtractTraceState(h))
+	}
+	if err != nil && !errors.Is(err, telemetry.ErrNoTraceContext) {
+		transaction.ErrorString("", err)
+	}
+
+	transaction.AddTransactionAttribute(AttributeMethod, method)
+
+	return transaction.SegmentStart(method)
+}
+
+// endRPC records the status code and error and ends the RPC segment
+func endRPC(transaction *telemetry.TransactionContainer, segmentID string, err error) {
+	code := status.Code(err)
+	transaction.AddTransactionAttribute(AttributeStatus, code.String())
+
+	if err != nil {
+		transaction.ErrorString(segmentID, err)
+	}
+
+	err = transaction.SegmentEndWithStatus(segmentID, segmentStatus(code))
+	if err != nil {
+		log.Printf("telemetry interceptor could not end segment: %v", err)
+	}
+}
+
+// segmentStatus maps the gRPC status code to a segment status
+func segmentStatus(code codes.Code) telemetry.SegmentStatus {
+	switch code {
+	case codes.OK:
+		return telemetry.StatusOK
+	case codes.Canceled:
+		return telemetry.StatusCancelled
+	}
+
+	return telemetry.StatusError
+}
+
+// incomingTraceContext returns the W3C traceparent and tracestate of the incoming metadata as header
+func incomingTraceContext(ctx context.Context) http.Header {
+	h := http.Header{}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return h
+	}
+
+	for _, key := range []string{telemetry.TraceparentHeader, telemetry.TracestateHeader} {
+		for _, value := range md.Get(key) {
+			h.Add(key, value)
+		}
+	}
+
+	return h
+}
+
+// inject adds the W3C trace context of the transaction in ctx to the outgoing metadata
+func inject(ctx context.Context) context.Context {
+	transaction, ok := telemetry.FromContext(ctx)
+	if !ok {
+		return ctx
+	}
+
+	h := http.Header{}
+	err := transaction.InjectHTTP(h)
+	if err != nil {
+		return ctx
+	}
+
+	pairs := make([]string, 0, 2*len(h))
+	for key, values := range h {
+		for _, value := range values {
+			pairs = append(pairs, strings.ToLower(key), value)
+		}
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, pairs...)
+}
+
+// serverStream carries the transaction context and optionally records every message as segment
+type serverStream struct {
+	grpc.ServerStream
+	ctx             context.Context
+	transaction     *telemetry.TransactionContainer
+	messageSegments bool
+}
+
+// Context returns the context holding the transaction
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// RecvMsg receives a message and records it as segment if enabled
+func (s *serverStream) RecvMsg(m any) error {
+	if !s.messageSegments {
+		return s.ServerStream.RecvMsg(m)
+	}
+
+	segmentID := s.transaction.SegmentStart("RecvMsg")
+	err := s.ServerStream.RecvMsg(m)
+	s.endMessage(segmentID, err)
+
+	return err
+}
+
+// SendMsg sends a message and records it as segment if enabled
+func (s *serverStream) SendMsg(m any) error {
+	if !s.messageSegments {
+		return s.ServerStream.SendMsg(m)
+	}
+
+	segmentID := s.transaction.SegmentStart("SendMsg")
+	err := s.ServerStream.SendMsg(m)
+	s.endMessage(segmentID, err)
+
+	return err
+}
+
+// endMessage ends the message segment
+func (s *serverStream) endMessage(segmentID string, err error) {
+	segmentStatus := telemetry.StatusOK
+	if err != nil && !errors.Is(err, io.EOF) {
+		segmentStatus = telemetry.StatusError
+	}
+
+	s.transaction.SegmentEndWithStatus(segmentID, segmentStatus)
+}
@@ -0,0 +1,194 @@
+package grpcmw_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/grpcmw"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/telemetrytest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const (
+	checkMethod = "/grpc.health.v1.Health/Check"
+	watchMethod = "/grpc.health.v1.Health/Watch"
+)
+
+// serve starts an in-process health server with the server interceptors and returns it with a client connection
+// using the client interceptors. The server is stopped at the end of the test
+func serve(t *testing.T, opts []grpc.ServerOption) (*grpc.Server, healthpb.HealthClient) {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("orders", healthpb.HealthCheckResponse_SERVING)
+
+	server := grpc.NewServer(opts...)
+	healthpb.RegisterHealthServer(server, healthServer)
+
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(grpcmw.UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(grpcmw.StreamClientInterceptor()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return server, healthpb.NewHealthClient(conn)
+}
+
+// methodSegment returns the recorded segment of the RPC method
+func methodSegment(t *testing.T, recorder *telemetrytest.RecordingDriver, method string) telemetrytest.Segment {
+	t.Helper()
+
+	for _, segment := range recorder.Segments() {
+		if segment.Name == method {
+			return segment
+		}
+	}
+
+	t.Fatalf("expected a segment %s, got %v", method, recorder.Segments())
+
+	return telemetrytest.Segment{}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	tel, recorder := telemetrytest.NewTelemetry(t)
+	server, client := serve(t, []grpc.ServerOption{
+		grpc.UnaryInterceptor(grpcmw.UnaryServerInterceptor("grpc", grpcmw.WithTelemetry(tel))),
+	})
+
+	_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "orders"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.GracefulStop()
+
+	recorder.AssertAttribute(t, grpcmw.AttributeMethod, checkMethod)
+	recorder.AssertAttribute(t, grpcmw.AttributeStatus, codes.OK.String())
+
+	segment := methodSegment(t, recorder, checkMethod)
+	if !segment.Ended || segment.Status != telemetry.StatusOK {
+		t.Errorf("expected the segment to end with StatusOK, got %+v", segment)
+	}
+}
+
+func TestUnaryServerInterceptorRecordsErrors(t *testing.T) {
+	tel, recorder := telemetrytest.NewTelemetry(t)
+	server, client := serve(t, []grpc.ServerOption{
+		grpc.UnaryInterceptor(grpcmw.UnaryServerInterceptor("grpc", grpcmw.WithTelemetry(tel))),
+	})
+
+	_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "unknown"})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound, got %v", err)
+	}
+	server.GracefulStop()
+
+	recorder.AssertAttribute(t, grpcmw.AttributeStatus, codes.NotFound.String())
+
+	segment := methodSegment(t, recorder, checkMethod)
+	if segment.Status != telemetry.StatusError {
+		t.Errorf("expected the segment to end with StatusError, got %s", segment.Status)
+	}
+
+	if len(recorder.Logs()) == 0 {
+		t.Error("expected the error to be logged")
+	}
+}
+
+func TestClientInterceptorPropagatesTraceContext(t *testing.T) {
+	clientTel, _ := telemetrytest.NewTelemetry(t)
+	serverTel, serverRecorder := telemetrytest.NewTelemetry(t)
+
+	var traceState string
+	server, client := serve(t, []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(
+			grpcmw.UnaryServerInterceptor("grpc", grpcmw.WithTelemetry(serverTel)),
+			func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+				if transaction, ok := telemetry.FromContext(ctx); ok {
+					traceState = transaction.TraceState()
+				}
+
+				return handler(ctx, req)
+			},
+		),
+	})
+
+	ctx, transaction, err := clientTel.StartContext(context.Background(), "client")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer transaction.Done()
+
+	trace, err := transaction.StartTracing()
+	if err != nil {
+		t.Fatal(err)
+	}
+	transaction.SetTraceState("vendor=value")
+
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{Service: "orders"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.GracefulStop()
+
+	traces := serverRecorder.Traces()
+	if len(traces) != 1 || traces[0] != trace {
+		t.Errorf("expected the server transaction to continue the trace %s, got %v", trace, traces)
+	}
+
+	if traceState != "vendor=value" {
+		t.Errorf("expected the tracestate to be propagated, got %q", traceState)
+	}
+}
+
+func TestStreamServerInterceptorRecordsCancellation(t *testing.T) {
+	tel, recorder := telemetrytest.NewTelemetry(t)
+	server, client := serve(t, []grpc.ServerOption{
+		grpc.StreamInterceptor(grpcmw.StreamServerInterceptor("grpc", grpcmw.WithTelemetry(tel), grpcmw.WithMessageSegments())),
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{Service: "orders"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cancel()
+	server.GracefulStop()
+
+	recorder.AssertAttribute(t, grpcmw.AttributeMethod, watchMethod)
+	recorder.AssertAttribute(t, grpcmw.AttributeStatus, codes.Canceled.String())
+	recorder.AssertSegment(t, "RecvMsg")
+	recorder.AssertSegment(t, "SendMsg")
+
+	segment := methodSegment(t, recorder, watchMethod)
+	if !segment.Ended || segment.Status != telemetry.StatusCancelled {
+		t.Errorf("expected the segment to end with StatusCancelled, got %+v", segment)
+	}
+}
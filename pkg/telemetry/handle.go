@@ -0,0 +1,79 @@
+package telemetry
+
+import (
+	"log"
+	"sync/atomic"
+)
+
+// SegmentHandle is a handle on a started segment which passes its segment id to the methods of the container.
+// A segment which failed to start has no id, all its methods do nothing then
+type SegmentHandle struct {
+	tc    *TransactionContainer
+	id    string
+	ended atomic.Bool
+}
+
+// StartSegment starts a segment in the registered driver transactions and returns its handle
+func (tc *TransactionContainer) StartSegment(name string) *SegmentHandle {
+	return &SegmentHandle{
+		tc: tc,
+		id: tc.SegmentStart(name),
+	}
+}
+
+// ID returns the segment id for the ID based methods of the container
+func (s *SegmentHandle) ID() string {
+	return s.id
+}
+
+// SetAttribute adds the attribute to the segment
+func (s *SegmentHandle) SetAttribute(key string, value any) {
+	if s.id == "" {
+		return
+	}
+
+	s.tc.AddSegmentAttribute(s.id, key, value)
+}
+
+// Info logs the info message on the segment
+func (s *SegmentHandle) Info(msg string) {
+	if s.id == "" {
+		return
+	}
+
+	s.tc.Info(s.id, &msg)
+}
+
+// Error logs the error on the segment
+func (s *SegmentHandle) Error(err error) {
+	if s.id == "" {
+		return
+	}
+
+	s.tc.Error(s.id, &err)
+}
+
+// Child starts a child segment of the segment and returns its handle
+func (s *SegmentHandle) Child(name string) *SegmentHandle {
+	child := &SegmentHandle{tc: s.tc}
+	if s.id == "" {
+		return child
+	}
+
+	segmentID, err := s.tc.SegmentStartChild(s.id, name)
+	if err != nil {
+		log.Print(err)
+	}
+	child.id = segmentID
+
+	return child
+}
+
+// End ends the segment. Ending it again does nothing
+func (s *SegmentHandle) End() {
+	if s.id == "" || !s.ended.CompareAndSwap(false, true) {
+		return
+	}
+
+	s.tc.SegmentEnd(s.id)
+}
@@ -0,0 +1,58 @@
+package telemetry_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/telemetrytest"
+)
+
+func ExampleTransactionContainer_StartSegment() {
+	recorder := telemetrytest.New()
+	tel := telemetry.New()
+	_ = tel.RegisterDriver("recorder", recorder)
+	tel.SetDriver("recorder")
+	tel.SetTraceDriver("recorder")
+
+	transaction, _ := tel.Start("import")
+	defer transaction.Done()
+
+	segment := transaction.StartSegment("load")
+	segment.SetAttribute("rows", 3)
+
+	child := segment.Child("parse")
+	child.Error(errors.New("invalid line"))
+	child.End()
+
+	segment.Info("loaded")
+	segment.End()
+
+	for _, s := range recorder.Segments() {
+		fmt.Println(s.Name, s.Ended, s.Status)
+	}
+
+	for _, log := range recorder.Logs() {
+		fmt.Println(log.Level, log.Message)
+	}
+
+	// Output:
+	// load true ok
+	// parse true ok
+	// error invalid line
+	// info loaded
+}
+
+func TestSegmentHandleEndTwice(t *testing.T) {
+	tel, recorder := telemetrytest.NewTelemetry(t)
+	transaction := start(t, tel, "handle")
+
+	segment := transaction.StartSegment("segment")
+	segment.End()
+	segment.End()
+
+	if n := segmentEnds(recorder); n != 1 {
+		t.Fatalf("expected the segment to be ended once, got %d", n)
+	}
+}
@@ -0,0 +1,84 @@
+package telemetry
+
+import "context"
+
+// HealthChecker can be implemented by a driver to report whether its backend is reachable
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// HealthCheckAll checks the active drivers of the default instance
+func HealthCheckAll(ctx context.Context) map[string]error {
+	return defaultTelemetry.HealthCheck(ctx)
+}
+
+// HealthCheck checks the active drivers concurrently and returns the result per driver name.
+// Drivers which do not implement HealthChecker are reported healthy with a nil error.
+// Checks which did not finish before ctx is done report the context error
+func (t *Telemetry) HealthCheck(ctx context.Context) map[string]error {
+	return t.healthCheck(ctx, t.drivers())
+}
+
+// HealthCheck checks the drivers of the transaction container like Telemetry.HealthCheck
+func (tc *TransactionContainer) HealthCheck(ctx context.Context) map[string]error {
+	tc.mu.RLock()
+	driverNames := tc.driverOrder()
+	tc.mu.RUnlock()
+
+	return tc.telemetry.healthCheck(ctx, driverNames)
+}
+
+// healthCheck checks the drivers with the provided names concurrently
+func (t *Telemetry) healthCheck(ctx context.Context, driverNames []string) map[string]error {
+	type healthResult struct {
+		driverName string
+		err        error
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	results := make(chan healthResult, len(driverNames))
+	health := make(map[string]error, len(driverNames))
+	pending := make(map[string]struct{}, len(driverNames))
+
+	for _, driverName := range driverNames {
+		driver, err := t.getDriver(driverName)
+		if err != nil {
+			health[driverName] = err
+			continue
+		}
+
+		checker, ok := driver.(HealthChecker)
+		if !ok {
+			health[driverName] = nil
+			continue
+		}
+
+		pending[driverName] = struct{}{}
+
+		go func(driverName string, checker HealthChecker) {
+			results <- healthResult{
+				driverName: driverName,
+				err:        safeCall(func() error { return checker.HealthCheck(ctx) }),
+			}
+		}(driverName, checker)
+	}
+
+	for len(pending) > 0 {
+		select {
+		case result := <-results:
+			health[result.driverName] = result.err
+			delete(pending, result.driverName)
+		case <-ctx.Done():
+			for driverName := range pending {
+				health[driverName] = ctx.Err()
+			}
+
+			clear(pending)
+		}
+	}
+
+	return health
+}
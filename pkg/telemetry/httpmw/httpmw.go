@@ -0,0 +1,143 @@
+// Package httpmw provides net/http middleware which wraps every request in a telemetry transaction
+package httpmw
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+)
+
+// Attribute names added to every transaction
+const (
+	AttributeMethod = "http.method"
+	AttributeRoute  = "http.route"
+	AttributeStatus = "http.status_code"
+)
+
+// Option configures the middleware
+type Option func(*config)
+
+// config holds the middleware configuration
+type config struct {
+	telemetry   *telemetry.Telemetry
+	segmentName func(*http.Request) string
+}
+
+// WithTelemetry uses the provided telemetry instance instead of the default one
+func WithTelemetry(t *telemetry.Telemetry) Option {
+	return func(c *config) {
+		c.telemetry = t
+	}
+}
+
+// WithSegmentName overrides the segment name, which defaults to the request path
+func WithSegmentName(fn func(*http.Request) string) Option {
+	return func(c *config) {
+		c.segmentName = fn
+	}
+}
+
+// Middleware starts a transaction for every request and stores it in the request context.
+// An incoming W3C traceparent header is used as trace of the transaction
+func Middleware(name string, opts ...Option) func(http.Handler) http.Handler {
+	cfg := config{
+		telemetry: telemetry.Default(),
+		segmentName: func(r *http.Request) string {
+			return r.URL.Path
+		},
+	}
+
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, transaction, err := cfg.telemetry.StartContext(r.Context(), name)
+			if err != nil {
+				log.Printf("telemetry middleware could not start transaction: %v", err)
+				next.ServeHTTP(w, r)
+
+				return
+			}
+			defer transaction.Done()
+
+			trace, err := telemetry.ExtractHTTP(r.Header)
+			if err == nil {
+				err = transaction.SetTrace(trace)
+				transaction.SetTraceState(telemetry.ExtractTraceState(r.Header))
+			}
+			if err != nil && !errors.Is(err, telemetry.ErrNoTraceContext) {
+				transaction.ErrorString("", err)
+			}
+
+			transaction.AddTransactionAttribute(AttributeMethod, r.Method)
+			transaction.AddTransactionAttribute(AttributeRoute, r.URL.Path)
+
+			segmentID := transaction.SegmentStart(cfg.segmentName(r))
+
+			recorder := &statusRecorder{ResponseWriter: w}
+			next.ServeHTTP(recorder, r.WithContext(ctx))
+
+			transaction.SegmentEnd(segmentID)
+
+			status := recorder.statusCode()
+			transaction.AddTransactionAttribute(AttributeStatus, status)
+			if status >= http.StatusInternalServerError {
+				transaction.ErrorString("", fmt.Errorf("%s %s responded with status %d", r.Method, r.URL.Path, status))
+			}
+		})
+	}
+}
+
+// statusRecorder observes the status code written by the handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader records the status code and passes it on
+func (sr *statusRecorder) WriteHeader(status int) {
+	if sr.status == 0 {
+		sr.status = status
+	}
+
+	sr.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the implicit status code and passes the body on
+func (sr *statusRecorder) Write(b []byte) (int, error) {
+	if sr.status == 0 {
+		sr.status = http.StatusOK
+	}
+
+	return sr.ResponseWriter.Write(b)
+}
+
+// Flush passes the flush on if the wrapped writer supports it
+func (sr *statusRecorder) Flush() {
+	if sr.status == 0 {
+		sr.status = http.StatusOK
+	}
+
+	if flusher, ok := sr.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the wrapped writer for http.ResponseController
+func (sr *statusRecorder) Unwrap() http.ResponseWriter {
+	return sr.ResponseWriter
+}
+
+// statusCode returns the recorded status code, which defaults to 200 if nothing was written
+func (sr *statusRecorder) statusCode() int {
+	if sr.status == 0 {
+		return http.StatusOK
+	}
+
+	return sr.status
+}
@@ -0,0 +1,60 @@
+package httpmw_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/httpmw"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/telemetrytest"
+)
+
+func TestMiddlewareRecordsStatusCode(t *testing.T) {
+	tel, recorder := telemetrytest.NewTelemetry(t)
+
+	handler := httpmw.Middleware("http", httpmw.WithTelemetry(tel))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, ok := telemetry.FromContext(r.Context())
+		if !ok {
+			t.Error("expected the transaction in the request context")
+		}
+
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/orders", nil))
+
+	recorder.AssertAttribute(t, httpmw.AttributeStatus, http.StatusCreated)
+	recorder.AssertAttribute(t, httpmw.AttributeMethod, http.MethodPost)
+	recorder.AssertAttribute(t, httpmw.AttributeRoute, "/orders")
+	recorder.AssertSegment(t, "/orders")
+}
+
+func TestMiddlewareDefaultsToStatusOK(t *testing.T) {
+	tel, recorder := telemetrytest.NewTelemetry(t)
+
+	handler := httpmw.Middleware("http", httpmw.WithTelemetry(tel))(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	recorder.AssertAttribute(t, httpmw.AttributeStatus, http.StatusOK)
+}
+
+func TestMiddlewareLogsServerErrors(t *testing.T) {
+	tel, recorder := telemetrytest.NewTelemetry(t)
+
+	handler := httpmw.Middleware("http",
+		httpmw.WithTelemetry(tel),
+		httpmw.WithSegmentName(func(r *http.Request) string { return r.Method + " orders" }),
+	)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "failure", http.StatusInternalServerError)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders/1", nil))
+
+	recorder.AssertAttribute(t, httpmw.AttributeStatus, http.StatusInternalServerError)
+	recorder.AssertSegment(t, "GET orders")
+	recorder.AssertLog(t, "error", "GET /orders/1 responded with status 500")
+}
@@ -0,0 +1,33 @@
+package telemetry
+
+import "github.com/google/uuid"
+
+// IDGenerator returns a new unique id
+type IDGenerator func() string
+
+// SetIDGenerator sets the id generator of the default instance
+func SetIDGenerator(generator IDGenerator) {
+	defaultTelemetry.SetIDGenerator(generator)
+}
+
+// SetIDGenerator sets the generator used for the segment ids created by the transaction containers.
+// Process ids and traces are created by the trace drivers and are not affected. A nil generator restores random UUIDs
+func (t *Telemetry) SetIDGenerator(generator IDGenerator) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.idGenerator = generator
+}
+
+// newID returns a new id of the configured generator
+func (t *Telemetry) newID() string {
+	t.mu.RLock()
+	generator := t.idGenerator
+	t.mu.RUnlock()
+
+	if generator == nil {
+		return uuid.NewString()
+	}
+
+	return generator()
+}
@@ -0,0 +1,86 @@
+package telemetry
+
+import "maps"
+
+// SetInheritTransactionAttributes enables or disables copying the transaction attributes onto new segments
+// of the default instance
+func SetInheritTransactionAttributes(inherit bool) {
+	defaultTelemetry.SetInheritTransactionAttributes(inherit)
+}
+
+// SetInheritTransactionAttributes makes every segment start with a copy of the current transaction attributes,
+// for backends which do not propagate transaction attributes to the spans in queries.
+// Attributes added to the transaction later are not applied to segments which are already started.
+// It trades data volume for queryability and can be overridden per container
+func (t *Telemetry) SetInheritTransactionAttributes(inherit bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.inheritTransactionAttributes = inherit
+}
+
+// inheritsTransactionAttributes reports whether new segments inherit the transaction attributes by default
+func (t *Telemetry) inheritsTransactionAttributes() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.inheritTransactionAttributes
+}
+
+// SetInheritTransactionAttributes overrides the setting of the telemetry instance for the segments of this container
+func (tc *TransactionContainer) SetInheritTransactionAttributes(inherit bool) {
+	tc.record.mu.Lock()
+	defer tc.record.mu.Unlock()
+
+	tc.record.inheritAttributes = &inherit
+}
+
+// inheritedAttributes returns a copy of the current transaction attributes if segments inherit them and nil otherwise
+func (tc *TransactionContainer) inheritedAttributes() map[string]any {
+	inherit := tc.telemetry.inheritsTransactionAttributes()
+
+	tc.record.mu.Lock()
+	defer tc.record.mu.Unlock()
+
+	if tc.record.inheritAttributes != nil {
+		inherit = *tc.record.inheritAttributes
+	}
+
+	if !inherit || len(tc.record.attributes) == 0 {
+		return nil
+	}
+
+	attributes := maps.Clone(tc.record.attributes)
+	delete(attributes, AttributesDroppedAttribute)
+
+	return attributes
+}
+
+// segmentDefaults returns the attributes every new segment starts with, the baggage over the inherited
+// transaction attributes, or nil if there are none
+func (tc *TransactionContainer) segmentDefaults() map[string]any {
+	attributes := tc.inheritedAttributes()
+
+	baggage := tc.baggage.attributes()
+	if attributes == nil {
+		return baggage
+	}
+
+	maps.Copy(attributes, baggage)
+
+	return attributes
+}
+
+// applySegmentDefaults adds the default attributes to the segment of the transaction
+func (tc *TransactionContainer) applySegmentDefaults(driverName string, transaction Transaction, segmentID string, defaults map[string]any) error {
+	if len(defaults) == 0 {
+		return nil
+	}
+
+	err := safeCall(func() error { return transaction.AddSegmentAttributes(segmentID, defaults) })
+	if err != nil {
+		return tc.driverError(driverName, "AddSegmentAttributes", err)
+	}
+
+	return nil
+}
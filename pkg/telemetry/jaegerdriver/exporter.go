@@ -0,0 +1,182 @@
+package jaegerdriver
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ContentType is the content type of the Thrift encoded batches posted to the collector
+const ContentType = "application/vnd.apache.thrift.binary"
+
+// serviceNameKey is the resource attribute holding the service name, it is sent as process name instead of a tag
+const serviceNameKey = "service.name"
+
+// exporter posts the spans as Thrift encoded Jaeger batches to the /api/traces endpoint of a collector
+type exporter struct {
+	endpoint    string
+	serviceName string
+	client      *http.Client
+}
+
+// ExportSpans posts the spans as one batch and returns an error if the collector does not accept it
+func (e *exporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	if len(spans) == 0 {
+		return nil
+	}
+
+	b := batch{
+		serviceName: e.serviceName,
+		processTags: processTags(spans[0]),
+		spans:       make([]span, 0, len(spans)),
+	}
+
+	for _, s := range spans {
+		b.spans = append(b.spans, jaegerSpan(s))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(b.encode()))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", ContentType)
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("jaeger collector returned %s", resp.Status)
+	}
+
+	return nil
+}
+
+// Shutdown closes the idle connections to the collector
+func (e *exporter) Shutdown(context.Context) error {
+	e.client.CloseIdleConnections()
+
+	return nil
+}
+
+// processTags returns the resource attributes of the span without the service name
+func processTags(s sdktrace.ReadOnlySpan) []tag {
+	var tags []tag
+	for _, kv := range s.Resource().Attributes() {
+		if kv.Key == serviceNameKey {
+			continue
+		}
+
+		tags = append(tags, jaegerTag(kv))
+	}
+
+	return tags
+}
+
+// jaegerSpan converts the span. The parent becomes a CHILD_OF reference and the links FOLLOWS_FROM references,
+// the status is added as error tag and the events as logs
+func jaegerSpan(s sdktrace.ReadOnlySpan) span {
+	spanContext := s.SpanContext()
+	traceIDLow, traceIDHigh := splitTraceID(spanContext.TraceID())
+
+	js := span{
+		traceIDLow:    traceIDLow,
+		traceIDHigh:   traceIDHigh,
+		spanID:        spanIDValue(spanContext.SpanID()),
+		operationName: s.Name(),
+		startTime:     s.StartTime().UnixMicro(),
+		duration:      s.EndTime().Sub(s.StartTime()).Microseconds(),
+	}
+
+	if spanContext.IsSampled() {
+		js.flags = 1
+	}
+
+	if parent := s.Parent(); parent.IsValid() {
+		js.parentSpanID = spanIDValue(parent.SpanID())
+		js.references = append(js.references, reference(refChildOf, parent))
+	}
+
+	for _, link := range s.Links() {
+		js.references = append(js.references, reference(refFollowsFrom, link.SpanContext))
+	}
+
+	for _, kv := range s.Attributes() {
+		js.tags = append(js.tags, jaegerTag(kv))
+	}
+
+	if kind := s.SpanKind(); kind != trace.SpanKindInternal && kind != trace.SpanKindUnspecified {
+		js.tags = append(js.tags, tag{key: "span.kind", vType: tagString, str: kind.String()})
+	}
+
+	if status := s.Status(); status.Code == codes.Error {
+		js.tags = append(js.tags, tag{key: "error", vType: tagBool, flag: true})
+		if status.Description != "" {
+			js.tags = append(js.tags, tag{key: "otel.status_description", vType: tagString, str: status.Description})
+		}
+	}
+
+	for _, event := range s.Events() {
+		fields := []tag{{key: "event", vType: tagString, str: event.Name}}
+		for _, kv := range event.Attributes {
+			fields = append(fields, jaegerTag(kv))
+		}
+
+		js.logs = append(js.logs, spanLog{timestamp: event.Time.UnixMicro(), fields: fields})
+	}
+
+	return js
+}
+
+// reference returns the reference of the type to the span
+func reference(refType int32, spanContext trace.SpanContext) spanRef {
+	traceIDLow, traceIDHigh := splitTraceID(spanContext.TraceID())
+
+	return spanRef{
+		refType:     refType,
+		traceIDLow:  traceIDLow,
+		traceIDHigh: traceIDHigh,
+		spanID:      spanIDValue(spanContext.SpanID()),
+	}
+}
+
+// jaegerTag converts the attribute, slices are sent as string
+func jaegerTag(kv attribute.KeyValue) tag {
+	t := tag{key: string(kv.Key)}
+
+	switch kv.Value.Type() {
+	case attribute.BOOL:
+		t.vType, t.flag = tagBool, kv.Value.AsBool()
+	case attribute.INT64:
+		t.vType, t.long = tagLong, kv.Value.AsInt64()
+	case attribute.FLOAT64:
+		t.vType, t.num = tagDouble, kv.Value.AsFloat64()
+	default:
+		t.vType, t.str = tagString, kv.Value.Emit()
+	}
+
+	return t
+}
+
+// splitTraceID returns the low and the high 64 bits of the trace id
+func splitTraceID(traceID trace.TraceID) (int64, int64) {
+	return int64(binary.BigEndian.Uint64(traceID[8:])), int64(binary.BigEndian.Uint64(traceID[:8]))
+}
+
+// spanIDValue returns the span id as integer
+func spanIDValue(spanID trace.SpanID) int64 {
+	return int64(binary.BigEndian.Uint64(spanID[:]))
+}
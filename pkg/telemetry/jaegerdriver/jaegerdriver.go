@@ -0,0 +1,252 @@
+// Package jaegerdriver provides a telemetry driver exporting spans directly to a Jaeger collector
+// with the Jaeger Thrift/HTTP API. Traces are represented in the Jaeger uber-trace-id format
+package jaegerdriver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/oteldriver"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// TraceHeader is the header used by Jaeger instrumented services to propagate the trace
+const TraceHeader = "uber-trace-id"
+
+// ExportTimeout is the maximum duration of a batch export to the collector
+const ExportTimeout = 10 * time.Second
+
+// ShutdownTimeout is the maximum time Close waits for the pending spans to be exported
+const ShutdownTimeout = 5 * time.Second
+
+// ErrInvalidTrace is returned if a trace is not in the uber-trace-id format
+var ErrInvalidTrace = errors.New("invalid uber-trace-id")
+
+// Driver exports the spans of its transactions to a Jaeger collector
+type Driver struct {
+	inner telemetry.Driver
+}
+
+// transaction builds Jaeger spans with the OpenTelemetry driver and translates its trace to the uber-trace-id format
+type transaction struct {
+	telemetry.Transaction
+	trace string
+}
+
+// New returns a *Driver posting the spans of serviceName as Thrift encoded batches to the collector endpoint,
+// e.g. http://localhost:14268/api/traces. The spans are exported in batches in the background,
+// Done of a transaction exports the pending spans before it returns
+func New(endpoint string, serviceName string) (telemetry.Driver, error) {
+	return newDriver(endpoint, serviceName)
+}
+
+// Factory creates a driver for telemetry.RegisterDriverFactory posting the spans of the configured service name
+// to the configured collector endpoint. A sample rate below 1 samples the fraction of new traces,
+// the sampling decision of an extracted trace is kept
+func Factory(settings telemetry.DriverSettings) (telemetry.Driver, error) {
+	if settings.SampleRate >= 1 {
+		return newDriver(settings.Endpoint, settings.ServiceName)
+	}
+
+	return newDriver(settings.Endpoint, settings.ServiceName, oteldriver.WithTracerProviderOptions(
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(settings.SampleRate))),
+	))
+}
+
+// newDriver returns a *Driver posting the spans to the collector endpoint with the additional driver options
+func newDriver(endpoint string, serviceName string, opts ...oteldriver.Option) (telemetry.Driver, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid jaeger collector endpoint: %w", err)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid jaeger collector endpoint %s: scheme must be http or https", endpoint)
+	}
+
+	e := &exporter{
+		endpoint:    endpoint,
+		serviceName: serviceName,
+		client:      &http.Client{Timeout: ExportTimeout},
+	}
+
+	return &Driver{
+		inner: oteldriver.NewWithExporter(e, append(opts, oteldriver.WithBatching(0, 0, 0))...),
+	}, nil
+}
+
+// Close exports the pending spans within the ShutdownTimeout
+func (d *Driver) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+
+	return d.Shutdown(ctx)
+}
+
+// Shutdown exports all pending spans and stops the exporter
+func (d *Driver) Shutdown(ctx context.Context) error {
+	return d.inner.(telemetry.Shutdowner).Shutdown(ctx)
+}
+
+// InitializeTransaction starts the root span of the transaction
+func (d *Driver) InitializeTransaction(name string) (telemetry.Transaction, error) {
+	inner, err := d.inner.InitializeTransaction(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &transaction{
+		Transaction: inner,
+	}, nil
+}
+
+// CreateTrace returns the uber-trace-id of the root span
+func (t *transaction) CreateTrace() (string, error) {
+	spanContext, ok := oteldriver.SpanContext(t.Transaction)
+	if !ok {
+		return "", errors.New("transaction without root span")
+	}
+
+	var flags byte
+	if spanContext.IsSampled() {
+		flags = 1
+	}
+
+	return FormatTrace(spanContext.TraceID().String(), spanContext.SpanID().String(), "0", flags), nil
+}
+
+// SetTrace sets the trace in the uber-trace-id format. Its trace id is passed on to the other drivers
+func (t *transaction) SetTrace(trace string) error {
+	traceID, _, _, _, err := ParseTrace(trace)
+	if err != nil {
+		return err
+	}
+
+	err = t.Transaction.SetTrace(traceID)
+	if err != nil {
+		return err
+	}
+
+	t.trace = trace
+
+	return nil
+}
+
+// Trace returns the trace set with SetTrace or the uber-trace-id of the root span
+func (t *transaction) Trace() (string, error) {
+	if t.trace == "" {
+		return t.CreateTrace()
+	}
+
+	return t.trace, nil
+}
+
+// SpanID returns the span id of the segment span, or of the root span for an empty segmentID
+func (t *transaction) SpanID(segmentID string) (string, error) {
+	provider, ok := t.Transaction.(telemetry.SpanIDProvider)
+	if !ok {
+		return "", telemetry.ErrNoSpanID
+	}
+
+	return provider.SpanID(segmentID)
+}
+
+// AddLink adds a span link to the root span. The trace can be in the uber-trace-id format or any format supported by the OpenTelemetry driver
+func (t *transaction) AddLink(trace string, attributes map[string]any) error {
+	traceID, spanID, _, _, err := ParseTrace(trace)
+	if err != nil {
+		return t.Transaction.AddLink(trace, attributes)
+	}
+
+	spanID = strings.Repeat("0", 16-len(spanID)) + spanID
+
+	return t.Transaction.AddLink(fmt.Sprintf("00-%s-%s-01", traceID, spanID), attributes)
+}
+
+// FormatTrace returns the trace in the uber-trace-id format {trace-id}:{span-id}:{parent-span-id}:{flags}
+func FormatTrace(traceID string, spanID string, parentSpanID string, flags byte) string {
+	return fmt.Sprintf("%s:%s:%s:%d", traceID, spanID, parentSpanID, flags)
+}
+
+// ParseTrace splits a trace in the uber-trace-id format.
+// The trace id is returned as 32 hex characters, padded with leading zeros
+func ParseTrace(trace string) (traceID string, spanID string, parentSpanID string, flags byte, err error) {
+	trace, err = url.QueryUnescape(trace)
+	if err != nil {
+		return "", "", "", 0, fmt.Errorf("%w: %v", ErrInvalidTrace, err)
+	}
+
+	parts := strings.Split(trace, ":")
+	if len(parts) != 4 {
+		return "", "", "", 0, ErrInvalidTrace
+	}
+
+	traceID, spanID, parentSpanID = parts[0], parts[1], parts[2]
+	if !isHexID(traceID, 32) || !isHexID(spanID, 16) || !isHexID(parentSpanID, 16) {
+		return "", "", "", 0, ErrInvalidTrace
+	}
+
+	f, err := strconv.ParseUint(parts[3], 16, 8)
+	if err != nil {
+		return "", "", "", 0, fmt.Errorf("%w: %v", ErrInvalidTrace, err)
+	}
+
+	return strings.Repeat("0", 32-len(traceID)) + traceID, spanID, parentSpanID, byte(f), nil
+}
+
+// InjectHTTP sets the trace of the transaction as uber-trace-id header
+func InjectHTTP(h http.Header, tc *telemetry.TransactionContainer) error {
+	trace, err := tc.Trace()
+	if err != nil {
+		return err
+	}
+
+	_, _, _, _, err = ParseTrace(trace)
+	if err != nil {
+		return err
+	}
+
+	h.Set(TraceHeader, trace)
+
+	return nil
+}
+
+// ExtractHTTP returns the uber-trace-id header. It returns telemetry.ErrNoTraceContext if the header is missing
+func ExtractHTTP(h http.Header) (string, error) {
+	trace := h.Get(TraceHeader)
+	if trace == "" {
+		return "", telemetry.ErrNoTraceContext
+	}
+
+	_, _, _, _, err := ParseTrace(trace)
+	if err != nil {
+		return "", err
+	}
+
+	return trace, nil
+}
+
+// isHexID reports whether s consists of 1 to size lower or upper case hex characters
+func isHexID(s string, size int) bool {
+	if len(s) == 0 || len(s) > size {
+		return false
+	}
+
+	_, err := strconv.ParseUint(s[max(0, len(s)-16):], 16, 64)
+	if err != nil {
+		return false
+	}
+
+	if len(s) > 16 {
+		_, err = strconv.ParseUint(s[:len(s)-16], 16, 64)
+	}
+
+	return err == nil
+}
@@ -0,0 +1,185 @@
+package jaegerdriver_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/jaegerdriver"
+)
+
+func TestParseTrace(t *testing.T) {
+	tests := []struct {
+		name         string
+		trace        string
+		traceID      string
+		spanID       string
+		parentSpanID string
+		flags        byte
+		err          bool
+	}{
+		{
+			name:         "valid",
+			trace:        "4bf92f3577b34da6a3ce929d0e0e4736:00f067aa0ba902b7:0:1",
+			traceID:      "4bf92f3577b34da6a3ce929d0e0e4736",
+			spanID:       "00f067aa0ba902b7",
+			parentSpanID: "0",
+			flags:        1,
+		},
+		{
+			name:         "short trace id is padded",
+			trace:        "a3ce929d0e0e4736:f067aa0ba902b7:53ce929d0e0e4736:3",
+			traceID:      "0000000000000000a3ce929d0e0e4736",
+			spanID:       "f067aa0ba902b7",
+			parentSpanID: "53ce929d0e0e4736",
+			flags:        3,
+		},
+		{
+			name:         "url encoded",
+			trace:        "a3ce929d0e0e4736%3Af067aa0ba902b7%3A0%3A0",
+			traceID:      "0000000000000000a3ce929d0e0e4736",
+			spanID:       "f067aa0ba902b7",
+			parentSpanID: "0",
+		},
+		{name: "empty", trace: "", err: true},
+		{name: "missing part", trace: "a3ce929d0e0e4736:f067aa0ba902b7:1", err: true},
+		{name: "extra part", trace: "a3ce929d0e0e4736:f067aa0ba902b7:0:1:1", err: true},
+		{name: "empty span id", trace: "a3ce929d0e0e4736::0:1", err: true},
+		{name: "trace id too long", trace: "14bf92f3577b34da6a3ce929d0e0e4736:f067aa0ba902b7:0:1", err: true},
+		{name: "span id too long", trace: "a3ce929d0e0e4736:1f067aa0ba902b7aa:0:1", err: true},
+		{name: "non-hex trace id", trace: "a3ce929d0e0e473g:f067aa0ba902b7:0:1", err: true},
+		{name: "non-hex high trace id", trace: "zz3ce929d0e0e4736a3ce929d0e0e4736:f067aa0ba902b7:0:1", err: true},
+		{name: "non-hex span id", trace: "a3ce929d0e0e4736:f067aa0ba902bx:0:1", err: true},
+		{name: "non-hex flags", trace: "a3ce929d0e0e4736:f067aa0ba902b7:0:x", err: true},
+		{name: "invalid escape", trace: "a3ce929d0e0e4736%zz:f067aa0ba902b7:0:1", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			traceID, spanID, parentSpanID, flags, err := jaegerdriver.ParseTrace(tt.trace)
+			if tt.err {
+				if !errors.Is(err, jaegerdriver.ErrInvalidTrace) {
+					t.Fatalf("expected ErrInvalidTrace, got %v", err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if traceID != tt.traceID || spanID != tt.spanID || parentSpanID != tt.parentSpanID || flags != tt.flags {
+				t.Errorf("expected %s %s %s %d, got %s %s %s %d",
+					tt.traceID, tt.spanID, tt.parentSpanID, tt.flags, traceID, spanID, parentSpanID, flags)
+			}
+		})
+	}
+}
+
+func TestFormatTraceRoundTrip(t *testing.T) {
+	trace := jaegerdriver.FormatTrace("4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", "0", 1)
+
+	header := http.Header{}
+	header.Set(jaegerdriver.TraceHeader, trace)
+
+	extracted, err := jaegerdriver.ExtractHTTP(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if extracted != trace {
+		t.Errorf("expected %s, got %s", trace, extracted)
+	}
+
+	_, err = jaegerdriver.ExtractHTTP(http.Header{})
+	if !errors.Is(err, telemetry.ErrNoTraceContext) {
+		t.Errorf("expected ErrNoTraceContext, got %v", err)
+	}
+}
+
+func TestExportsThriftBatches(t *testing.T) {
+	var (
+		mu          sync.Mutex
+		contentType string
+		body        []byte
+	)
+
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+
+		mu.Lock()
+		contentType = r.Header.Get("Content-Type")
+		body = append(body, data...)
+		mu.Unlock()
+
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer collector.Close()
+
+	driver, err := jaegerdriver.New(collector.URL+"/api/traces", "checkout")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tel := telemetry.New()
+	err = tel.RegisterDriver("jaeger", driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tel.SetDriver("jaeger")
+	tel.SetTraceDriver("jaeger")
+
+	transaction, err := tel.Start("order")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	trace, err := transaction.Trace()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, _, _, err = jaegerdriver.ParseTrace(trace)
+	if err != nil {
+		t.Fatalf("expected an uber-trace-id, got %s: %v", trace, err)
+	}
+
+	segmentID := transaction.SegmentStart("load cart")
+	transaction.AddSegmentAttribute(segmentID, "cart.items", 3)
+
+	segmentErr := errors.New("cart not found")
+	transaction.Error(segmentID, &segmentErr)
+	transaction.SegmentEnd(segmentID)
+
+	err = transaction.Done()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if contentType != jaegerdriver.ContentType {
+		t.Errorf("expected content type %s, got %s", jaegerdriver.ContentType, contentType)
+	}
+
+	for _, expected := range []string{"checkout", "order", "load cart", "cart.items", "cart not found", "error"} {
+		if !bytes.Contains(body, []byte(expected)) {
+			t.Errorf("expected the batch to contain %q", expected)
+		}
+	}
+}
+
+func TestNewRejectsInvalidEndpoint(t *testing.T) {
+	_, err := jaegerdriver.New("localhost:14268", "checkout")
+	if err == nil {
+		t.Fatal("expected an error for an endpoint without scheme")
+	}
+}
@@ -0,0 +1,227 @@
+package jaegerdriver
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+)
+
+// Thrift binary protocol field types used by the Jaeger model
+const (
+	thriftBool   byte = 2
+	thriftDouble byte = 4
+	thriftI32    byte = 8
+	thriftI64    byte = 10
+	thriftString byte = 11
+	thriftStruct byte = 12
+	thriftList   byte = 15
+)
+
+// Jaeger tag value types
+const (
+	tagString int32 = iota
+	tagDouble
+	tagBool
+	tagLong
+)
+
+// Jaeger span reference types
+const (
+	refChildOf int32 = iota
+	refFollowsFrom
+)
+
+// tag is a typed key value pair of a Jaeger span, log or process
+type tag struct {
+	key   string
+	vType int32
+	str   string
+	num   float64
+	long  int64
+	flag  bool
+}
+
+// spanLog is a timestamped Jaeger span log, the timestamp in microseconds since the epoch
+type spanLog struct {
+	timestamp int64
+	fields    []tag
+}
+
+// spanRef is a reference of a Jaeger span to another span
+type spanRef struct {
+	refType     int32
+	traceIDLow  int64
+	traceIDHigh int64
+	spanID      int64
+}
+
+// span is a Jaeger span, the times in microseconds
+type span struct {
+	traceIDLow    int64
+	traceIDHigh   int64
+	spanID        int64
+	parentSpanID  int64
+	operationName string
+	references    []spanRef
+	flags         int32
+	startTime     int64
+	duration      int64
+	tags          []tag
+	logs          []spanLog
+}
+
+// batch is the Jaeger batch of spans of a process sent to the collector
+type batch struct {
+	serviceName string
+	processTags []tag
+	spans       []span
+}
+
+// thriftWriter writes structs in the Thrift binary protocol
+type thriftWriter struct {
+	buf bytes.Buffer
+}
+
+// encode returns the batch in the Thrift binary protocol accepted by the /api/traces endpoint of the collector
+func (b batch) encode() []byte {
+	var w thriftWriter
+
+	w.fieldBegin(thriftStruct, 1)
+	w.stringField(1, b.serviceName)
+	if len(b.processTags) > 0 {
+		w.tags(2, b.processTags)
+	}
+	w.stop()
+
+	w.fieldBegin(thriftList, 2)
+	w.listBegin(thriftStruct, len(b.spans))
+	for _, s := range b.spans {
+		w.span(s)
+	}
+
+	w.stop()
+
+	return w.buf.Bytes()
+}
+
+// span writes the span struct
+func (w *thriftWriter) span(s span) {
+	w.i64Field(1, s.traceIDLow)
+	w.i64Field(2, s.traceIDHigh)
+	w.i64Field(3, s.spanID)
+	w.i64Field(4, s.parentSpanID)
+	w.stringField(5, s.operationName)
+
+	if len(s.references) > 0 {
+		w.fieldBegin(thriftList, 6)
+		w.listBegin(thriftStruct, len(s.references))
+		for _, ref := range s.references {
+			w.i32Field(1, ref.refType)
+			w.i64Field(2, ref.traceIDLow)
+			w.i64Field(3, ref.traceIDHigh)
+			w.i64Field(4, ref.spanID)
+			w.stop()
+		}
+	}
+
+	w.i32Field(7, s.flags)
+	w.i64Field(8, s.startTime)
+	w.i64Field(9, s.duration)
+
+	if len(s.tags) > 0 {
+		w.tags(10, s.tags)
+	}
+
+	if len(s.logs) > 0 {
+		w.fieldBegin(thriftList, 11)
+		w.listBegin(thriftStruct, len(s.logs))
+		for _, log := range s.logs {
+			w.i64Field(1, log.timestamp)
+			w.tags(2, log.fields)
+			w.stop()
+		}
+	}
+
+	w.stop()
+}
+
+// tags writes the tags as list field with the id
+func (w *thriftWriter) tags(id int16, tags []tag) {
+	w.fieldBegin(thriftList, id)
+	w.listBegin(thriftStruct, len(tags))
+	for _, t := range tags {
+		w.stringField(1, t.key)
+		w.i32Field(2, t.vType)
+
+		switch t.vType {
+		case tagDouble:
+			w.fieldBegin(thriftDouble, 4)
+			w.i64(int64(math.Float64bits(t.num)))
+		case tagBool:
+			w.fieldBegin(thriftBool, 5)
+			w.bool(t.flag)
+		case tagLong:
+			w.i64Field(6, t.long)
+		default:
+			w.stringField(3, t.str)
+		}
+
+		w.stop()
+	}
+}
+
+// fieldBegin writes the header of a field
+func (w *thriftWriter) fieldBegin(typ byte, id int16) {
+	w.buf.WriteByte(typ)
+	w.buf.Write(binary.BigEndian.AppendUint16(nil, uint16(id)))
+}
+
+// listBegin writes the header of a list
+func (w *thriftWriter) listBegin(elemType byte, size int) {
+	w.buf.WriteByte(elemType)
+	w.i32(int32(size))
+}
+
+// stop ends a struct
+func (w *thriftWriter) stop() {
+	w.buf.WriteByte(0)
+}
+
+// i32Field writes the field with the id and the i32 value
+func (w *thriftWriter) i32Field(id int16, v int32) {
+	w.fieldBegin(thriftI32, id)
+	w.i32(v)
+}
+
+// i64Field writes the field with the id and the i64 value
+func (w *thriftWriter) i64Field(id int16, v int64) {
+	w.fieldBegin(thriftI64, id)
+	w.i64(v)
+}
+
+// stringField writes the field with the id and the string value
+func (w *thriftWriter) stringField(id int16, v string) {
+	w.fieldBegin(thriftString, id)
+	w.i32(int32(len(v)))
+	w.buf.WriteString(v)
+}
+
+// i32 writes a big endian i32
+func (w *thriftWriter) i32(v int32) {
+	w.buf.Write(binary.BigEndian.AppendUint32(nil, uint32(v)))
+}
+
+// i64 writes a big endian i64
+func (w *thriftWriter) i64(v int64) {
+	w.buf.Write(binary.BigEndian.AppendUint64(nil, uint64(v)))
+}
+
+// bool writes a bool as single byte
+func (w *thriftWriter) bool(v bool) {
+	if v {
+		w.buf.WriteByte(1)
+		return
+	}
+
+	w.buf.WriteByte(0)
+}
@@ -0,0 +1,43 @@
+package telemetry_test
+
+import (
+	"testing"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/telemetrytest"
+)
+
+func TestLeakedSegmentsInLongTransactions(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		tel, recorder := telemetrytest.NewTelemetry(t)
+		tel.SetSnapshotEnabled(enabled)
+		transaction := start(t, tel, "leak")
+
+		leakedID := transaction.SegmentStart("leaked")
+		for i := 0; i < 1000; i++ {
+			transaction.SegmentEnd(transaction.SegmentStart("iteration"))
+		}
+
+		open := transaction.OpenSegments()
+		if len(open) != 1 || open[0] != leakedID {
+			t.Fatalf("enabled %t: expected only %s to be open, got %v", enabled, leakedID, open)
+		}
+
+		err := transaction.Done()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		recorder.AssertAttribute(t, telemetry.OpenSegmentsAttribute, leakedID)
+
+		segments := transaction.Snapshot().Segments
+		expected := 0
+		if enabled {
+			expected = 1001
+		}
+
+		if len(segments) != expected {
+			t.Errorf("enabled %t: expected %d segments in the snapshot, got %d", enabled, expected, len(segments))
+		}
+	}
+}
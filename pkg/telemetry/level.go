@@ -0,0 +1,98 @@
+package telemetry
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Level is the priority of a log message
+type Level int
+
+// Available log levels, ordered from lowest to highest priority
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// SetLogLevel sets the minimum level a message needs to be passed to the drivers of the default instance
+func SetLogLevel(level Level) {
+	defaultTelemetry.SetLogLevel(level)
+}
+
+// SetLogLevel sets the minimum level a message needs to be passed to the drivers
+func (t *Telemetry) SetLogLevel(level Level) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.logLevel = level
+}
+
+// ParseLevel returns the level for the provided name (debug, info, warn or error)
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	}
+
+	return LevelDebug, fmt.Errorf("unknown telemetry log level %q", name)
+}
+
+// String returns the name of the level
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	}
+
+	return fmt.Sprintf("level(%d)", int(l))
+}
+
+// SyslogSeverity returns the syslog severity of the level (RFC 5424), e.g. 3 for LevelError
+func (l Level) SyslogSeverity() int {
+	switch l {
+	case LevelDebug:
+		return 7
+	case LevelInfo:
+		return 6
+	case LevelWarn:
+		return 4
+	}
+
+	return 3
+}
+
+// OTelSeverity returns the OpenTelemetry log severity number of the level, e.g. 17 for LevelError
+func (l Level) OTelSeverity() int {
+	switch l {
+	case LevelDebug:
+		return 5
+	case LevelInfo:
+		return 9
+	case LevelWarn:
+		return 13
+	}
+
+	return 17
+}
+
+// logEnabled reports whether messages of the level are passed to the drivers
+func (t *Telemetry) logEnabled(level Level) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return level >= t.logLevel
+}
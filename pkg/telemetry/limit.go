@@ -0,0 +1,159 @@
+package telemetry
+
+import "unicode/utf8"
+
+// TruncationMarker is appended to log payloads which exceed the configured size
+const TruncationMarker = "…(truncated)"
+
+// DefaultMaxAttributes is the default maximum number of attributes per segment and transaction
+const DefaultMaxAttributes = 128
+
+// AttributesDroppedAttribute holds the number of attributes dropped because of the attribute limit
+const AttributesDroppedAttribute = "attributes_dropped"
+
+// SetErrorBytesSize sets the maximum bytes of an error payload of the default instance
+func SetErrorBytesSize(n int) {
+	defaultTelemetry.SetErrorBytesSize(n)
+}
+
+// SetInfoBytesSize sets the maximum bytes of an info, warn and debug payload of the default instance
+func SetInfoBytesSize(n int) {
+	defaultTelemetry.SetInfoBytesSize(n)
+}
+
+// SetMaxAttributesPerSegment sets the maximum number of attributes per segment of the default instance
+func SetMaxAttributesPerSegment(n int) {
+	defaultTelemetry.SetMaxAttributesPerSegment(n)
+}
+
+// SetMaxAttributesPerTransaction sets the maximum number of attributes per transaction of the default instance
+func SetMaxAttributesPerTransaction(n int) {
+	defaultTelemetry.SetMaxAttributesPerTransaction(n)
+}
+
+// SetMaxAttributesPerSegment sets the maximum number of attributes per segment.
+// Further attributes are dropped and counted in the AttributesDroppedAttribute. Values below 1 disable the limit
+func (t *Telemetry) SetMaxAttributesPerSegment(n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.maxSegmentAttributes = n
+}
+
+// SetMaxAttributesPerTransaction sets the maximum number of attributes per transaction.
+// Further attributes are dropped and counted in the AttributesDroppedAttribute. Values below 1 disable the limit
+func (t *Telemetry) SetMaxAttributesPerTransaction(n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.maxTransactionAttributes = n
+}
+
+// attributeLimits returns the maximum number of attributes per segment and transaction
+func (t *Telemetry) attributeLimits() (int, int) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.maxSegmentAttributes, t.maxTransactionAttributes
+}
+
+// limitAttributes adds the attributes to stored until it holds limit attributes and returns the added ones.
+// Attributes with new keys beyond the limit increase dropped instead, whose value is then added
+// as AttributesDroppedAttribute to stored and the returned attributes.
+// It reports whether attributes were dropped for the first time. A limit below 1 disables the limit
+func limitAttributes(stored map[string]any, dropped *int, attributes map[string]any, limit int) (map[string]any, bool) {
+	admitted := make(map[string]any, len(attributes))
+	droppedBefore := *dropped
+
+	for key, value := range attributes {
+		count := len(stored)
+		if *dropped > 0 {
+			count--
+		}
+
+		if _, ok := stored[key]; !ok && limit > 0 && count >= limit {
+			*dropped++
+			continue
+		}
+
+		stored[key] = value
+		admitted[key] = value
+	}
+
+	if *dropped == droppedBefore {
+		return admitted, false
+	}
+
+	stored[AttributesDroppedAttribute] = *dropped
+	admitted[AttributesDroppedAttribute] = *dropped
+
+	return admitted, droppedBefore == 0
+}
+
+// SetErrorBytesSize sets the maximum bytes of an error payload. Values below 1 restore ErrorBytesSize
+func (t *Telemetry) SetErrorBytesSize(n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.errorBytesSize = n
+}
+
+// SetInfoBytesSize sets the maximum bytes of an info, warn and debug payload. Values below 1 restore DebugByteSize
+func (t *Telemetry) SetInfoBytesSize(n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.infoBytesSize = n
+}
+
+// truncateError shortens the error payload to the configured size
+func (t *Telemetry) truncateError(msg string) string {
+	t.mu.RLock()
+	size := t.errorBytesSize
+	t.mu.RUnlock()
+
+	if size < 1 {
+		size = ErrorBytesSize
+	}
+
+	if len(msg) > size {
+		t.counters.logsTruncated.Add(1)
+	}
+
+	return truncate(msg, size)
+}
+
+// truncateInfo shortens the info, warn and debug payload to the configured size
+func (t *Telemetry) truncateInfo(msg string) string {
+	size := t.infoSize()
+	if len(msg) > size {
+		t.counters.logsTruncated.Add(1)
+	}
+
+	return truncate(msg, size)
+}
+
+// infoSize returns the maximum bytes of an info, warn and debug payload
+func (t *Telemetry) infoSize() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.infoBytesSize < 1 {
+		return DebugByteSize
+	}
+
+	return t.infoBytesSize
+}
+
+// truncate cuts msg after size bytes without splitting a rune and appends the TruncationMarker
+func truncate(msg string, size int) string {
+	if len(msg) <= size {
+		return msg
+	}
+
+	for size > 0 && !utf8.RuneStart(msg[size]) {
+		size--
+	}
+
+	return msg[:size] + TruncationMarker
+}
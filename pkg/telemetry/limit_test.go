@@ -0,0 +1,101 @@
+package telemetry_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/telemetrytest"
+)
+
+func TestErrorIsTruncated(t *testing.T) {
+	tel, recorder := telemetrytest.NewTelemetry(t)
+	transaction := start(t, tel, "truncate")
+
+	err := errors.New(strings.Repeat("e", 5*1024))
+	transaction.Error("", &err)
+
+	logs := recorder.Logs()
+	if len(logs) != 1 {
+		t.Fatalf("expected 1 log, got %d", len(logs))
+	}
+
+	message := logs[0].Message
+	if !strings.HasSuffix(message, telemetry.TruncationMarker) {
+		t.Fatalf("expected the truncation marker, got %q", message[len(message)-20:])
+	}
+
+	if size := len(message) - len(telemetry.TruncationMarker); size != telemetry.ErrorBytesSize {
+		t.Fatalf("expected %d bytes of the error, got %d", telemetry.ErrorBytesSize, size)
+	}
+
+	if stats := tel.Stats(); stats.LogsTruncated != 1 {
+		t.Fatalf("expected 1 truncated log, got %d", stats.LogsTruncated)
+	}
+}
+
+func TestInfoBytesSize(t *testing.T) {
+	tel, recorder := telemetrytest.NewTelemetry(t)
+	tel.SetInfoBytesSize(8)
+	transaction := start(t, tel, "truncate")
+
+	short := "short"
+	long := "longer than eight bytes"
+	transaction.Info("", &short)
+	transaction.Info("", &long)
+
+	recorder.AssertLog(t, "info", short)
+	recorder.AssertLog(t, "info", long[:8]+telemetry.TruncationMarker)
+}
+
+func TestStdoutDriverKeepsTruncationMarker(t *testing.T) {
+	for _, size := range []int{0, 4096} {
+		var buf bytes.Buffer
+
+		tel := telemetry.New()
+		err := tel.RegisterDriver("json", telemetry.NewStdoutDriver(&buf))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tel.SetDriver("json")
+		tel.SetTraceDriver("json")
+		tel.SetLogPrefix("[prefix] ")
+		tel.SetErrorBytesSize(size)
+		transaction := start(t, tel, "truncate")
+
+		logErr := errors.New(strings.Repeat("e", 5*1024))
+		transaction.Error("", &logErr)
+
+		var message string
+		decoder := json.NewDecoder(&buf)
+		for decoder.More() {
+			var event struct {
+				Event   string `json:"event"`
+				Message string `json:"message"`
+			}
+
+			err := decoder.Decode(&event)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if event.Event == "error" {
+				message = event.Message
+			}
+		}
+
+		if !strings.HasSuffix(message, telemetry.TruncationMarker) {
+			t.Fatalf("size %d: expected the output to end with the truncation marker, got %d bytes", size, len(message))
+		}
+
+		expected := max(size, telemetry.ErrorBytesSize)
+		body := strings.TrimSuffix(strings.TrimPrefix(message, "[prefix] "), telemetry.TruncationMarker)
+		if body != strings.Repeat("e", expected) {
+			t.Fatalf("size %d: expected %d bytes of the error, got %d", size, expected, len(body))
+		}
+	}
+}
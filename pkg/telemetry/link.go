@@ -0,0 +1,83 @@
+package telemetry
+
+import (
+	"fmt"
+	"maps"
+)
+
+// StartOption configures a transaction container at Start
+type StartOption func(*startConfig)
+
+// startConfig holds the options applied at Start
+type startConfig struct {
+	links      []link
+	attributes map[string]any
+}
+
+// link is a trace the transaction is linked to
+type link struct {
+	trace      string
+	attributes map[string]any
+}
+
+// WithLink links the transaction to the provided trace right after it is initialized
+func WithLink(trace string, attributes map[string]any) StartOption {
+	return func(sc *startConfig) {
+		sc.links = append(sc.links, link{
+			trace:      trace,
+			attributes: attributes,
+		})
+	}
+}
+
+// WithAttributes adds the attributes to the transaction before Start returns, after the resource attributes.
+// Calling it more than once merges the attributes
+func WithAttributes(attributes map[string]any) StartOption {
+	return func(sc *startConfig) {
+		if sc.attributes == nil {
+			sc.attributes = make(map[string]any, len(attributes))
+		}
+
+		maps.Copy(sc.attributes, attributes)
+	}
+}
+
+// newStartConfig returns the configuration with all options applied
+func newStartConfig(opts []StartOption) startConfig {
+	var sc startConfig
+
+	for _, opt := range opts {
+		opt(&sc)
+	}
+
+	return sc
+}
+
+// AddLink links the transaction to a related trace without making it a child of that trace,
+// e.g. a job started by a message of another transaction.
+// Attributes with an unsupported type are dropped. Drivers without link support ignore the link
+func (tc *TransactionContainer) AddLink(trace string, attributes map[string]any) error {
+	var ew ErrorWrapper
+
+	if trace == "" {
+		return fmt.Errorf("link trace must not be empty")
+	}
+
+	attributes, err := tc.validAttributes(attributes)
+	if err != nil {
+		ew.Add(err)
+	}
+
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+
+	for _, driverName := range tc.driverOrder() {
+		transaction := tc.transactions[driverName]
+		err := safeCall(func() error { return transaction.AddLink(trace, attributes) })
+		if err != nil {
+			ew.Add(tc.driverError(driverName, "AddLink", err))
+		}
+	}
+
+	return ew.Error()
+}
@@ -0,0 +1,26 @@
+package telemetry
+
+import "fmt"
+
+// InfoString logs the info message in the registered driver transactions
+// If segmentID is empty, the info will be logged directly on the transaction
+func (tc *TransactionContainer) InfoString(segmentID string, msg string) {
+	tc.Info(segmentID, &msg)
+}
+
+// Infof formats the info message and logs it in the registered driver transactions
+// If segmentID is empty, the info will be logged directly on the transaction
+func (tc *TransactionContainer) Infof(segmentID string, format string, args ...any) {
+	if !tc.telemetry.logEnabled(LevelInfo) {
+		return
+	}
+
+	msg := fmt.Sprintf(format, args...)
+	tc.Info(segmentID, &msg)
+}
+
+// ErrorString logs the error in the registered driver transactions
+// If segmentID is empty, the error will be logged directly on the transaction
+func (tc *TransactionContainer) ErrorString(segmentID string, err error) {
+	tc.Error(segmentID, &err)
+}
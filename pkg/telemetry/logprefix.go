@@ -0,0 +1,58 @@
+package telemetry
+
+import "strings"
+
+// Placeholders of the log prefix template, see SetLogPrefix
+const (
+	LogPrefixTrace   = "{trace}"
+	LogPrefixProcess = "{process}"
+)
+
+// SetLogPrefix sets the log prefix template of the default instance
+func SetLogPrefix(template string) {
+	defaultTelemetry.SetLogPrefix(template)
+}
+
+// SetLogPrefix prepends the template to the messages of Debug, Info, Warn and Error before they are handed to the
+// drivers, so raw log lines can be correlated with the traces, e.g. "[{trace}] ". LogPrefixTrace and LogPrefixProcess
+// are replaced with the trace and the process id of the trace drivers when the message is logged, an unavailable value
+// is replaced with an empty string. Snapshots keep the messages without the prefix. The default is no prefix
+func (t *Telemetry) SetLogPrefix(template string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.logPrefix = template
+}
+
+// logPrefixTemplate returns the log prefix template
+func (t *Telemetry) logPrefixTemplate() string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.logPrefix
+}
+
+// prefixMessage prepends the resolved log prefix template to the message
+func (tc *TransactionContainer) prefixMessage(message string) string {
+	template := tc.telemetry.logPrefixTemplate()
+	if template == "" {
+		return message
+	}
+
+	var replacements []string
+	if strings.Contains(template, LogPrefixTrace) {
+		trace, _ := tc.Trace()
+		replacements = append(replacements, LogPrefixTrace, trace)
+	}
+
+	if strings.Contains(template, LogPrefixProcess) {
+		processID, _ := tc.ProcessID()
+		replacements = append(replacements, LogPrefixProcess, processID)
+	}
+
+	if len(replacements) == 0 {
+		return template + message
+	}
+
+	return strings.NewReplacer(replacements...).Replace(template) + message
+}
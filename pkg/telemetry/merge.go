@@ -0,0 +1,82 @@
+package telemetry
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// ErrDuplicateAttribute is logged with MergeError for segment attributes which were already set
+var ErrDuplicateAttribute = errors.New("telemetry attribute already set")
+
+// AttributeMergePolicy decides what happens when a segment attribute is set again with the same key
+type AttributeMergePolicy int
+
+// Available attribute merge policies
+const (
+	// MergeOverwrite passes the new value to the drivers, the last value wins
+	MergeOverwrite AttributeMergePolicy = iota
+	// MergeKeepFirst drops the new value, the first value wins
+	MergeKeepFirst
+	// MergeError drops the new value like MergeKeepFirst and logs ErrDuplicateAttribute
+	MergeError
+)
+
+// SetAttributeMergePolicy sets the attribute merge policy of the default instance
+func SetAttributeMergePolicy(policy AttributeMergePolicy) {
+	defaultTelemetry.SetAttributeMergePolicy(policy)
+}
+
+// SetAttributeMergePolicy sets how segment attributes set again with the same key are merged. The container tracks
+// the attributes of every segment and applies the policy before calling the drivers, so all drivers end up with
+// the same value whether they overwrite attributes or keep both. The default is MergeOverwrite.
+// Transaction attributes are always overwritten, so they can override the resource attributes
+func (t *Telemetry) SetAttributeMergePolicy(policy AttributeMergePolicy) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.attributeMergePolicy = policy
+}
+
+// mergePolicy returns the attribute merge policy
+func (t *Telemetry) mergePolicy() AttributeMergePolicy {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.attributeMergePolicy
+}
+
+// mergeAttributes returns the attributes which may be added to stored under the policy and the sorted keys
+// of the attributes dropped because they are already set
+func mergeAttributes(stored map[string]any, attributes map[string]any, policy AttributeMergePolicy) (map[string]any, []string) {
+	if policy == MergeOverwrite {
+		return attributes, nil
+	}
+
+	var duplicates []string
+	merged := make(map[string]any, len(attributes))
+	for key, value := range attributes {
+		if _, ok := stored[key]; ok && key != AttributesDroppedAttribute {
+			duplicates = append(duplicates, key)
+			continue
+		}
+
+		merged[key] = value
+	}
+
+	sort.Strings(duplicates)
+
+	return merged, duplicates
+}
+
+// logDuplicateAttributes logs ErrDuplicateAttribute for the keys if the policy is MergeError
+func logDuplicateAttributes(segmentID string, duplicates []string, policy AttributeMergePolicy) {
+	if policy != MergeError || len(duplicates) == 0 {
+		return
+	}
+
+	err := fmt.Errorf("%w: %s", ErrDuplicateAttribute, strings.Join(duplicates, ", "))
+	log.Printf("telemetry segment %s | Error: %v", segmentID, err)
+}
@@ -0,0 +1,66 @@
+package telemetry_test
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/telemetrytest"
+)
+
+func TestAttributeMergePolicies(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   telemetry.AttributeMergePolicy
+		expected any
+		logged   bool
+	}{
+		{name: "overwrite", policy: telemetry.MergeOverwrite, expected: "second"},
+		{name: "keep first", policy: telemetry.MergeKeepFirst, expected: "first"},
+		{name: "error", policy: telemetry.MergeError, expected: "first", logged: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log.SetOutput(&buf)
+			t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+			tel, recorder := telemetrytest.NewTelemetry(t)
+			tel.SetAttributeMergePolicy(tt.policy)
+			transaction := start(t, tel, "merge")
+
+			segmentID := transaction.SegmentStart("segment")
+			transaction.AddSegmentAttribute(segmentID, "key", "first")
+			transaction.AddSegmentAttribute(segmentID, "key", "second")
+
+			var values []any
+			for _, attribute := range recorder.SegmentAttributes() {
+				if attribute.Key == "key" {
+					values = append(values, attribute.Value)
+				}
+			}
+
+			if tt.policy != telemetry.MergeOverwrite && len(values) != 1 {
+				t.Errorf("expected the duplicate to be dropped, got %v", values)
+			}
+
+			if values[len(values)-1] != tt.expected {
+				t.Errorf("expected the drivers to end with %v, got %v", tt.expected, values)
+			}
+
+			value, _ := transaction.GetSegmentAttribute(segmentID, "key")
+			if value != tt.expected {
+				t.Errorf("expected the container to hold %v, got %v", tt.expected, value)
+			}
+
+			logged := strings.Contains(buf.String(), telemetry.ErrDuplicateAttribute.Error())
+			if logged != tt.logged {
+				t.Errorf("expected duplicate logged %t, got %t: %s", tt.logged, logged, buf.String())
+			}
+		})
+	}
+}
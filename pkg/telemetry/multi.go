@@ -0,0 +1,377 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// MultiDriverOption configures a multi driver
+type MultiDriverOption func(*multiDriver)
+
+// multiDriver groups several drivers into a single driver
+type multiDriver struct {
+	drivers    []Driver
+	traceIndex int
+}
+
+// multiTransaction fans out every call to the transactions of all grouped drivers
+type multiTransaction struct {
+	transactions []Transaction
+	traceIndex   int
+}
+
+// WithMultiTraceDriver uses the driver at index for the trace related methods instead of the first one
+func WithMultiTraceDriver(index int) MultiDriverOption {
+	return func(md *multiDriver) {
+		md.traceIndex = index
+	}
+}
+
+// NewMultiDriver returns a driver which groups the provided drivers.
+// The trace related methods are delegated to the first driver
+func NewMultiDriver(drivers ...Driver) Driver {
+	return NewMultiDriverWithOptions(drivers)
+}
+
+// NewMultiDriverWithOptions returns a driver which groups the provided drivers
+func NewMultiDriverWithOptions(drivers []Driver, opts ...MultiDriverOption) Driver {
+	md := multiDriver{
+		drivers: drivers,
+	}
+
+	for _, opt := range opts {
+		opt(&md)
+	}
+
+	return md
+}
+
+// InitializeTransaction initializes the transactions of all grouped drivers
+func (md multiDriver) InitializeTransaction(name string) (Transaction, error) {
+	if len(md.drivers) == 0 {
+		return nil, errors.New("multi driver without drivers")
+	}
+
+	if md.traceIndex < 0 || md.traceIndex >= len(md.drivers) {
+		return nil, fmt.Errorf("multi driver trace index %d out of range", md.traceIndex)
+	}
+
+	mt := &multiTransaction{
+		transactions: make([]Transaction, 0, len(md.drivers)),
+		traceIndex:   md.traceIndex,
+	}
+
+	for i, driver := range md.drivers {
+		transaction, err := driver.InitializeTransaction(name)
+		if err != nil {
+			mt.Erase()
+			return nil, fmt.Errorf("multi driver %d: %w", i, err)
+		}
+
+		mt.transactions = append(mt.transactions, transaction)
+	}
+
+	return mt, nil
+}
+
+// each calls fn for every transaction and joins the errors
+func (mt *multiTransaction) each(fn func(Transaction) error) error {
+	var ew ErrorWrapper
+
+	for i, transaction := range mt.transactions {
+		err := fn(transaction)
+		if err != nil {
+			ew.Add(fmt.Errorf("multi driver %d: %w", i, err))
+		}
+	}
+
+	return ew.Error()
+}
+
+// eachReader drains rc once and passes a copy of the content to fn for every transaction
+func (mt *multiTransaction) eachReader(rc io.ReadCloser, fn func(Transaction, io.ReadCloser) error) error {
+	defer rc.Close()
+
+	content, err := io.ReadAll(rc)
+	if err != nil {
+		return err
+	}
+
+	return mt.each(func(transaction Transaction) error {
+		return fn(transaction, io.NopCloser(bytes.NewReader(content)))
+	})
+}
+
+// traceTransaction returns the transaction used for the trace related methods
+func (mt *multiTransaction) traceTransaction() Transaction {
+	return mt.transactions[mt.traceIndex]
+}
+
+// Start starts the transactions of all drivers with the name
+func (mt *multiTransaction) Start(name string) {
+	for _, transaction := range mt.transactions {
+		transaction.Start(name)
+	}
+}
+
+// SetName renames the transactions of all drivers
+func (mt *multiTransaction) SetName(name string) error {
+	return mt.each(func(transaction Transaction) error {
+		return transaction.SetName(name)
+	})
+}
+
+// AddTransactionAttribute adds the attribute to the transactions of all drivers
+func (mt *multiTransaction) AddTransactionAttribute(key string, value any) error {
+	return mt.each(func(transaction Transaction) error {
+		return transaction.AddTransactionAttribute(key, value)
+	})
+}
+
+// SegmentStart starts the segment in the transactions of all drivers
+func (mt *multiTransaction) SegmentStart(segmentID string, name string) error {
+	return mt.each(func(transaction Transaction) error {
+		return transaction.SegmentStart(segmentID, name)
+	})
+}
+
+// SegmentStartChild starts the segment as child of the parent segment in the transactions of all drivers
+func (mt *multiTransaction) SegmentStartChild(parentID string, segmentID string, name string) error {
+	return mt.each(func(transaction Transaction) error {
+		return transaction.SegmentStartChild(parentID, segmentID, name)
+	})
+}
+
+// AddSegmentAttribute adds the attribute to the segment in the transactions of all drivers
+func (mt *multiTransaction) AddSegmentAttribute(segmentID string, key string, value any) error {
+	return mt.each(func(transaction Transaction) error {
+		return transaction.AddSegmentAttribute(segmentID, key, value)
+	})
+}
+
+// AddSegmentAttributes adds the attributes to the segment in the transactions of all drivers
+func (mt *multiTransaction) AddSegmentAttributes(segmentID string, attributes map[string]any) error {
+	return mt.each(func(transaction Transaction) error {
+		return transaction.AddSegmentAttributes(segmentID, attributes)
+	})
+}
+
+// AddSegmentEvent adds the event to the segment in the transactions of all drivers
+func (mt *multiTransaction) AddSegmentEvent(segmentID string, name string, attributes map[string]any) error {
+	return mt.each(func(transaction Transaction) error {
+		return transaction.AddSegmentEvent(segmentID, name, attributes)
+	})
+}
+
+// SegmentEnd ends the segment in the transactions of all drivers
+func (mt *multiTransaction) SegmentEnd(segmentID string) error {
+	return mt.each(func(transaction Transaction) error {
+		return transaction.SegmentEnd(segmentID)
+	})
+}
+
+// SegmentEndWithStatus ends the segment with the status in the transactions of all drivers
+func (mt *multiTransaction) SegmentEndWithStatus(segmentID string, status SegmentStatus) error {
+	return mt.each(func(transaction Transaction) error {
+		return transaction.SegmentEndWithStatus(segmentID, status)
+	})
+}
+
+// AddLink links the trace in the transactions of all drivers
+func (mt *multiTransaction) AddLink(trace string, attributes map[string]any) error {
+	return mt.each(func(transaction Transaction) error {
+		return transaction.AddLink(trace, attributes)
+	})
+}
+
+// SetStatus sets the status of the transactions of all drivers
+func (mt *multiTransaction) SetStatus(status TransactionStatus) error {
+	return mt.each(func(transaction Transaction) error {
+		return transaction.SetStatus(status)
+	})
+}
+
+// AttachPayload attaches the payload to every transaction, transactions without payload support log it
+func (mt *multiTransaction) AttachPayload(segmentID string, payload Payload) error {
+	return mt.each(func(transaction Transaction) error {
+		return attachPayload(transaction, segmentID, payload)
+	})
+}
+
+// Flush flushes the transactions of all drivers
+func (mt *multiTransaction) Flush() error {
+	return mt.each(func(transaction Transaction) error {
+		return transaction.Flush()
+	})
+}
+
+// Done finishes the transactions of all drivers and joins their errors
+func (mt *multiTransaction) Done() error {
+	return mt.each(func(transaction Transaction) error {
+		return transaction.Done()
+	})
+}
+
+// Info logs the message in the transactions of all drivers
+func (mt *multiTransaction) Info(segmentID string, rc io.ReadCloser) error {
+	return mt.eachReader(rc, func(transaction Transaction, rc io.ReadCloser) error {
+		return transaction.Info(segmentID, rc)
+	})
+}
+
+// Warn logs the message as warning in the transactions of all drivers
+func (mt *multiTransaction) Warn(segmentID string, rc io.ReadCloser) error {
+	return mt.eachReader(rc, func(transaction Transaction, rc io.ReadCloser) error {
+		return transaction.Warn(segmentID, rc)
+	})
+}
+
+// Error logs the message as error in the transactions of all drivers
+func (mt *multiTransaction) Error(segmentID string, rc io.ReadCloser) error {
+	return mt.eachReader(rc, func(transaction Transaction, rc io.ReadCloser) error {
+		return transaction.Error(segmentID, rc)
+	})
+}
+
+// Debug logs the message as debug message in the transactions of all drivers
+func (mt *multiTransaction) Debug(segmentID string, rc io.ReadCloser) error {
+	return mt.eachReader(rc, func(transaction Transaction, rc io.ReadCloser) error {
+		return transaction.Debug(segmentID, rc)
+	})
+}
+
+// InfoFields logs the fields in the transactions of all drivers
+func (mt *multiTransaction) InfoFields(segmentID string, fields map[string]any) error {
+	return mt.each(func(transaction Transaction) error {
+		return transaction.InfoFields(segmentID, fields)
+	})
+}
+
+// ErrorFields logs the fields as error in the transactions of all drivers
+func (mt *multiTransaction) ErrorFields(segmentID string, fields map[string]any) error {
+	return mt.each(func(transaction Transaction) error {
+		return transaction.ErrorFields(segmentID, fields)
+	})
+}
+
+// SegmentStartContext passes ctx to the transactions implementing ContextTransaction
+func (mt *multiTransaction) SegmentStartContext(ctx context.Context, segmentID string, name string) error {
+	return mt.each(func(transaction Transaction) error {
+		if ct, ok := transaction.(ContextTransaction); ok {
+			return ct.SegmentStartContext(ctx, segmentID, name)
+		}
+
+		return transaction.SegmentStart(segmentID, name)
+	})
+}
+
+// InfoContext passes ctx to the transactions implementing ContextTransaction
+func (mt *multiTransaction) InfoContext(ctx context.Context, segmentID string, rc io.ReadCloser) error {
+	return mt.eachReader(rc, func(transaction Transaction, rc io.ReadCloser) error {
+		if ct, ok := transaction.(ContextTransaction); ok {
+			return ct.InfoContext(ctx, segmentID, rc)
+		}
+
+		return transaction.Info(segmentID, rc)
+	})
+}
+
+// ErrorContext passes ctx to the transactions implementing ContextTransaction
+func (mt *multiTransaction) ErrorContext(ctx context.Context, segmentID string, rc io.ReadCloser) error {
+	return mt.eachReader(rc, func(transaction Transaction, rc io.ReadCloser) error {
+		if ct, ok := transaction.(ContextTransaction); ok {
+			return ct.ErrorContext(ctx, segmentID, rc)
+		}
+
+		return transaction.Error(segmentID, rc)
+	})
+}
+
+// CreateTrace creates the trace with the trace driver
+func (mt *multiTransaction) CreateTrace() (string, error) {
+	return mt.traceTransaction().CreateTrace()
+}
+
+// SetTrace sets the trace on the trace driver and its trace id on every other driver
+func (mt *multiTransaction) SetTrace(trace string) error {
+	traceTransaction := mt.traceTransaction()
+
+	err := traceTransaction.SetTrace(trace)
+	if err != nil {
+		return err
+	}
+
+	traceID, err := traceTransaction.TraceID()
+	if err != nil {
+		return err
+	}
+
+	var ew ErrorWrapper
+
+	for i, transaction := range mt.transactions {
+		if i == mt.traceIndex {
+			continue
+		}
+
+		err := transaction.SetTraceID(traceID)
+		if err != nil {
+			ew.Add(fmt.Errorf("multi driver %d: %w", i, err))
+		}
+	}
+
+	return ew.Error()
+}
+
+// Trace returns the trace of the trace driver
+func (mt *multiTransaction) Trace() (string, error) {
+	return mt.traceTransaction().Trace()
+}
+
+// TraceID returns the trace id of the trace driver
+func (mt *multiTransaction) TraceID() (string, error) {
+	return mt.traceTransaction().TraceID()
+}
+
+// SetTraceID sets the trace id of the transactions of all drivers
+func (mt *multiTransaction) SetTraceID(traceID string) error {
+	return mt.each(func(transaction Transaction) error {
+		return transaction.SetTraceID(traceID)
+	})
+}
+
+// Erase erases the transactions of all drivers
+func (mt *multiTransaction) Erase() {
+	for _, transaction := range mt.transactions {
+		transaction.Erase()
+	}
+}
+
+// CreateProcessID creates the process id with the trace driver
+func (mt *multiTransaction) CreateProcessID() (string, error) {
+	return mt.traceTransaction().CreateProcessID()
+}
+
+// SetProcessID sets the process id of the transactions of all drivers
+func (mt *multiTransaction) SetProcessID(processID string) error {
+	return mt.each(func(transaction Transaction) error {
+		return transaction.SetProcessID(processID)
+	})
+}
+
+// ProcessID returns the process id of the trace driver
+func (mt *multiTransaction) ProcessID() (string, error) {
+	return mt.traceTransaction().ProcessID()
+}
+
+// SpanID returns the span id of the trace driver, see SpanIDProvider
+func (mt *multiTransaction) SpanID(segmentID string) (string, error) {
+	provider, ok := mt.traceTransaction().(SpanIDProvider)
+	if !ok {
+		return "", ErrNoSpanID
+	}
+
+	return provider.SpanID(segmentID)
+}
@@ -0,0 +1,124 @@
+package telemetry_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/telemetrytest"
+)
+
+// failingDriver fails to initialize transactions
+type failingDriver struct{}
+
+// InitializeTransaction returns an error
+func (failingDriver) InitializeTransaction(string) (telemetry.Transaction, error) {
+	return nil, errors.New("initialization failed")
+}
+
+// contextDriver is a recording driver whose transactions implement telemetry.ContextTransaction
+type contextDriver struct {
+	*telemetrytest.RecordingDriver
+	contexts []context.Context
+}
+
+// contextTransaction records the contexts passed to the context methods
+type contextTransaction struct {
+	telemetry.Transaction
+	driver *contextDriver
+}
+
+// InitializeTransaction returns a recording transaction implementing telemetry.ContextTransaction
+func (d *contextDriver) InitializeTransaction(name string) (telemetry.Transaction, error) {
+	transaction, err := d.RecordingDriver.InitializeTransaction(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &contextTransaction{Transaction: transaction, driver: d}, nil
+}
+
+// SegmentStartContext records ctx
+func (ct *contextTransaction) SegmentStartContext(ctx context.Context, segmentID string, name string) error {
+	ct.driver.contexts = append(ct.driver.contexts, ctx)
+	return ct.SegmentStart(segmentID, name)
+}
+
+// InfoContext records ctx
+func (ct *contextTransaction) InfoContext(ctx context.Context, segmentID string, rc io.ReadCloser) error {
+	ct.driver.contexts = append(ct.driver.contexts, ctx)
+	return ct.Info(segmentID, rc)
+}
+
+// ErrorContext records ctx
+func (ct *contextTransaction) ErrorContext(ctx context.Context, segmentID string, rc io.ReadCloser) error {
+	ct.driver.contexts = append(ct.driver.contexts, ctx)
+	return ct.Error(segmentID, rc)
+}
+
+func TestMultiDriverErasesTransactionsOnFailedInitialization(t *testing.T) {
+	recorder := telemetrytest.New()
+	driver := telemetry.NewMultiDriver(recorder, failingDriver{})
+
+	_, err := driver.InitializeTransaction("multi")
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if !slices.Contains(recorder.Calls(), "Erase") {
+		t.Fatalf("expected the initialized transaction to be erased, got calls %v", recorder.Calls())
+	}
+}
+
+func TestMultiDriverForwardsContext(t *testing.T) {
+	inner := &contextDriver{RecordingDriver: telemetrytest.New()}
+	plain := telemetrytest.New()
+
+	tel := telemetry.New()
+	err := tel.RegisterDriver("multi", telemetry.NewMultiDriver(inner, plain))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tel.SetDriver("multi")
+	tel.SetTraceDriver("multi")
+	transaction := start(t, tel, "multi")
+
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "value")
+
+	segmentID, err := transaction.SegmentStartCtx(ctx, "segment")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := "message"
+	logErr := errors.New("failure")
+	for _, err := range []error{
+		transaction.InfoCtx(ctx, segmentID, &msg),
+		transaction.ErrorCtx(ctx, segmentID, &logErr),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	transaction.SegmentEnd(segmentID)
+
+	if len(inner.contexts) != 3 {
+		t.Fatalf("expected 3 context calls, got %d", len(inner.contexts))
+	}
+
+	for _, got := range inner.contexts {
+		if got.Value(key{}) != "value" {
+			t.Fatal("expected the context of the caller")
+		}
+	}
+
+	plain.AssertSegment(t, "segment")
+	plain.AssertLog(t, "info", msg)
+	plain.AssertLog(t, "error", logErr.Error())
+}
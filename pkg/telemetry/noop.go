@@ -0,0 +1,164 @@
+package telemetry
+
+import (
+	"io"
+
+	"github.com/google/uuid"
+)
+
+// NoopDriverName is the name the noop driver is registered with
+const NoopDriverName = "noop"
+
+// NoopDriver is a driver which discards everything. It is meant for tests and local development
+type NoopDriver struct{}
+
+// noopTransaction is the transaction of the noop driver
+type noopTransaction struct {
+	name string
+}
+
+// InitializeTransaction returns a transaction which discards everything
+func (d NoopDriver) InitializeTransaction(name string) (Transaction, error) {
+	return noopTransaction{name: name}, nil
+}
+
+// Start ...
+func (t noopTransaction) Start(string) {}
+
+// SetName ...
+func (t noopTransaction) SetName(string) error {
+	return nil
+}
+
+// AddTransactionAttribute ...
+func (t noopTransaction) AddTransactionAttribute(string, any) error {
+	return nil
+}
+
+// SegmentStart ...
+func (t noopTransaction) SegmentStart(string, string) error {
+	return nil
+}
+
+// SegmentStartChild ...
+func (t noopTransaction) SegmentStartChild(string, string, string) error {
+	return nil
+}
+
+// AddSegmentAttribute ...
+func (t noopTransaction) AddSegmentAttribute(string, string, any) error {
+	return nil
+}
+
+// AddSegmentAttributes ...
+func (t noopTransaction) AddSegmentAttributes(string, map[string]any) error {
+	return nil
+}
+
+// AddSegmentEvent ...
+func (t noopTransaction) AddSegmentEvent(string, string, map[string]any) error {
+	return nil
+}
+
+// SegmentEnd ...
+func (t noopTransaction) SegmentEnd(string) error {
+	return nil
+}
+
+// SegmentEndWithStatus ...
+func (t noopTransaction) SegmentEndWithStatus(string, SegmentStatus) error {
+	return nil
+}
+
+// AddLink ...
+func (t noopTransaction) AddLink(string, map[string]any) error {
+	return nil
+}
+
+// SetStatus ...
+func (t noopTransaction) SetStatus(TransactionStatus) error {
+	return nil
+}
+
+// Flush ...
+func (t noopTransaction) Flush() error {
+	return nil
+}
+
+// Done ...
+func (t noopTransaction) Done() error {
+	return nil
+}
+
+// Info ...
+func (t noopTransaction) Info(string, io.ReadCloser) error {
+	return nil
+}
+
+// Warn ...
+func (t noopTransaction) Warn(string, io.ReadCloser) error {
+	return nil
+}
+
+// Error ...
+func (t noopTransaction) Error(string, io.ReadCloser) error {
+	return nil
+}
+
+// Debug ...
+func (t noopTransaction) Debug(string, io.ReadCloser) error {
+	return nil
+}
+
+// InfoFields ...
+func (t noopTransaction) InfoFields(string, map[string]any) error {
+	return nil
+}
+
+// ErrorFields ...
+func (t noopTransaction) ErrorFields(string, map[string]any) error {
+	return nil
+}
+
+// CreateTrace returns a trace derived from the transaction name, so it is the same for every call
+func (t noopTransaction) CreateTrace() (string, error) {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte("trace:"+t.name)).String(), nil
+}
+
+// SetTrace ...
+func (t noopTransaction) SetTrace(string) error {
+	return nil
+}
+
+// Trace returns the same trace as CreateTrace
+func (t noopTransaction) Trace() (string, error) {
+	return t.CreateTrace()
+}
+
+// TraceID returns the same trace as CreateTrace
+func (t noopTransaction) TraceID() (string, error) {
+	return t.CreateTrace()
+}
+
+// SetTraceID ...
+func (t noopTransaction) SetTraceID(string) error {
+	return nil
+}
+
+// Erase ...
+func (t noopTransaction) Erase() {}
+
+// CreateProcessID returns a process id derived from the transaction name, so it is the same for every call
+func (t noopTransaction) CreateProcessID() (string, error) {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte("process:"+t.name)).String(), nil
+}
+
+// SetProcessID ...
+func (t noopTransaction) SetProcessID(string) error {
+	return nil
+}
+
+// ProcessID returns the same process id as CreateProcessID
+func (t noopTransaction) ProcessID() (string, error) {
+	return t.CreateProcessID()
+}
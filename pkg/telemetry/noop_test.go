@@ -0,0 +1,67 @@
+package telemetry_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+)
+
+func TestNoopTransactionIDsAreDeterministic(t *testing.T) {
+	transaction, err := telemetry.NoopDriver{}.InitializeTransaction("checkout")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created, err := transaction.CreateTrace()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = uuid.Parse(created); err != nil {
+		t.Fatalf("expected a UUID trace, got %q: %v", created, err)
+	}
+
+	for name, get := range map[string]func() (string, error){
+		"Trace":   transaction.Trace,
+		"TraceID": transaction.TraceID,
+	} {
+		value, err := get()
+		if err != nil || value != created {
+			t.Errorf("expected %s to return %s, got %q, %v", name, created, value, err)
+		}
+	}
+
+	processID, err := transaction.CreateProcessID()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	value, err := transaction.ProcessID()
+	if err != nil || value != processID {
+		t.Errorf("expected ProcessID to return %s, got %q, %v", processID, value, err)
+	}
+
+	other, err := telemetry.NoopDriver{}.InitializeTransaction("refund")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherTrace, _ := other.Trace()
+	if otherTrace == created {
+		t.Error("expected transactions with different names to have different traces")
+	}
+
+	tel := newNoopTelemetry()
+	container := start(t, tel, "checkout")
+
+	trace, err := container.StartTracing()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	current, err := container.Trace()
+	if err != nil || current != trace || trace != created {
+		t.Errorf("expected the container trace %s, got %q and %q, %v", created, trace, current, err)
+	}
+}
@@ -0,0 +1,108 @@
+package telemetry_test
+
+import (
+	"io"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/telemetrytest"
+)
+
+// callOrder records the names of the drivers in the order they were called
+type callOrder struct {
+	mu    sync.Mutex
+	names []string
+}
+
+// add appends the driver name
+func (co *callOrder) add(name string) {
+	co.mu.Lock()
+	defer co.mu.Unlock()
+
+	co.names = append(co.names, name)
+}
+
+// take returns and resets the driver names
+func (co *callOrder) take() []string {
+	co.mu.Lock()
+	defer co.mu.Unlock()
+
+	names := co.names
+	co.names = nil
+
+	return names
+}
+
+// orderedDriver is a recording driver whose transactions add its name to the call order on SegmentStart and Info
+type orderedDriver struct {
+	*telemetrytest.RecordingDriver
+	name  string
+	order *callOrder
+}
+
+// orderedTransaction adds the driver name to the call order
+type orderedTransaction struct {
+	telemetry.Transaction
+	name  string
+	order *callOrder
+}
+
+// InitializeTransaction returns a recording transaction adding the driver name to the call order
+func (d orderedDriver) InitializeTransaction(name string) (telemetry.Transaction, error) {
+	transaction, err := d.RecordingDriver.InitializeTransaction(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return orderedTransaction{Transaction: transaction, name: d.name, order: d.order}, nil
+}
+
+// SegmentStart adds the driver name to the call order
+func (ot orderedTransaction) SegmentStart(segmentID string, name string) error {
+	ot.order.add(ot.name)
+	return ot.Transaction.SegmentStart(segmentID, name)
+}
+
+// Info adds the driver name to the call order
+func (ot orderedTransaction) Info(segmentID string, rc io.ReadCloser) error {
+	ot.order.add(ot.name)
+	return ot.Transaction.Info(segmentID, rc)
+}
+
+func TestDriversAreCalledInSetDriverOrder(t *testing.T) {
+	order := &callOrder{}
+	names := []string{"echo", "alpha", "delta", "charlie", "bravo"}
+
+	tel := telemetry.New()
+	for _, name := range names {
+		err := tel.RegisterDriver(name, orderedDriver{RecordingDriver: telemetrytest.New(), name: name, order: order})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tel.SetDriver(names...)
+	tel.SetTraceDriver(names[0])
+
+	for i := 0; i < 50; i++ {
+		transaction := start(t, tel, "order")
+
+		transaction.SegmentStart("segment")
+		if got := order.take(); !slices.Equal(got, names) {
+			t.Fatalf("SegmentStart: expected drivers %v, got %v", names, got)
+		}
+
+		msg := "message"
+		transaction.Info("", &msg)
+		if got := order.take(); !slices.Equal(got, names) {
+			t.Fatalf("Info: expected drivers %v, got %v", names, got)
+		}
+
+		err := transaction.Done()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package oteldriver
+
+import (
+	"context"
+	"time"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// FlushTimeout is the maximum time Done of a transaction waits for the final batch to be exported
+const FlushTimeout = 5 * time.Second
+
+// Option configures a driver created with NewWithExporter
+type Option func(*exporterConfig)
+
+// exporterConfig holds the span processor pipeline of a driver created with NewWithExporter
+type exporterConfig struct {
+	batchOptions    []sdktrace.BatchSpanProcessorOption
+	batching        bool
+	providerOptions []sdktrace.TracerProviderOption
+}
+
+// WithBatching exports the spans in batches in the background instead of synchronously on every span end.
+// maxQueue is the maximum number of buffered spans, spans ending while the queue is full are dropped.
+// maxBatch is the maximum number of spans per export and timeout the maximum delay before a batch is exported.
+// Values below 1 keep the defaults of the OpenTelemetry SDK
+func WithBatching(maxQueue int, maxBatch int, timeout time.Duration) Option {
+	return func(c *exporterConfig) {
+		c.batching = true
+		c.batchOptions = nil
+
+		if maxQueue > 0 {
+			c.batchOptions = append(c.batchOptions, sdktrace.WithMaxQueueSize(maxQueue))
+		}
+
+		if maxBatch > 0 {
+			c.batchOptions = append(c.batchOptions, sdktrace.WithMaxExportBatchSize(maxBatch))
+		}
+
+		if timeout > 0 {
+			c.batchOptions = append(c.batchOptions, sdktrace.WithBatchTimeout(timeout))
+		}
+	}
+}
+
+// WithTracerProviderOptions adds options to the tracer provider created by NewWithExporter, e.g. sdktrace.WithResource.
+// Span processors added this way are not flushed by the driver
+func WithTracerProviderOptions(opts ...sdktrace.TracerProviderOption) Option {
+	return func(c *exporterConfig) {
+		c.providerOptions = append(c.providerOptions, opts...)
+	}
+}
+
+// NewWithExporter returns a driver sending its spans to the exporter through its own tracer provider.
+// By default every span is exported synchronously when it ends, see WithBatching to export in the background.
+// With batching, Done of a transaction exports the pending spans before it returns,
+// in the synchronous mode of telemetry.SetSynchronous every segment end does as well
+func NewWithExporter(exporter sdktrace.SpanExporter, opts ...Option) telemetry.Driver {
+	var config exporterConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	processor := sdktrace.NewSimpleSpanProcessor(exporter)
+	if config.batching {
+		processor = sdktrace.NewBatchSpanProcessor(exporter, config.batchOptions...)
+	}
+
+	tracerProvider := sdktrace.NewTracerProvider(append(config.providerOptions, sdktrace.WithSpanProcessor(processor))...)
+
+	d := New(tracerProvider).(driver)
+	d.ownedProvider = tracerProvider
+	if config.batching {
+		d.processor = processor
+	}
+
+	return d
+}
+
+// Shutdown exports the pending spans and shuts down the exporter of a driver created with NewWithExporter.
+// A tracer provider passed to New is owned by the caller and not shut down
+func (d driver) Shutdown(ctx context.Context) error {
+	if d.ownedProvider == nil {
+		return nil
+	}
+
+	return d.ownedProvider.Shutdown(ctx)
+}
+
+// flush exports the spans pending in the batch span processor of the driver
+func (d driver) flush() error {
+	if d.processor == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), FlushTimeout)
+	defer cancel()
+
+	return d.processor.ForceFlush(ctx)
+}
@@ -0,0 +1,553 @@
+// Package oteldriver provides a telemetry driver built on the OpenTelemetry tracing API
+package oteldriver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName is the name of the tracer created by the driver
+const InstrumentationName = "github.com/plentymarkets/mc-telemetry/pkg/telemetry/oteldriver"
+
+// Span attributes set by the driver
+const (
+	AttributeProcessID = "mc.process_id"
+	AttributeTrace     = "mc.trace"
+	AttributeTraceID   = "mc.trace_id"
+	AttributeMessage   = "message"
+	AttributeLinkTrace = "mc.link.trace"
+)
+
+// driver creates OpenTelemetry spans for transactions and segments
+type driver struct {
+	tracerProvider trace.TracerProvider
+	tracer         trace.Tracer
+	// processor is the batch span processor flushed on Done, nil if the spans are not batched by the driver
+	processor sdktrace.SpanProcessor
+	// ownedProvider is the tracer provider created by NewWithExporter, nil if it was provided by the caller
+	ownedProvider *sdktrace.TracerProvider
+}
+
+// transaction maps a telemetry transaction to a root span and its segments to child spans
+type transaction struct {
+	driver    driver
+	mu        sync.Mutex
+	ctx       context.Context
+	span      trace.Span
+	segments  map[string]segment
+	trace     string
+	traceID   string
+	processID string
+}
+
+// segment holds the span and context of a segment
+type segment struct {
+	ctx  context.Context
+	span trace.Span
+}
+
+// New returns a driver using the provided tracer provider.
+// If tracerProvider is nil the global tracer provider is used
+func New(tracerProvider trace.TracerProvider) telemetry.Driver {
+	if tracerProvider == nil {
+		tracerProvider = otel.GetTracerProvider()
+	}
+
+	return driver{
+		tracerProvider: tracerProvider,
+		tracer:         tracerProvider.Tracer(InstrumentationName),
+	}
+}
+
+// InitializeTransaction starts the root span of the transaction
+func (d driver) InitializeTransaction(name string) (telemetry.Transaction, error) {
+	ctx, span := d.tracer.Start(context.Background(), name, trace.WithNewRoot())
+
+	return &transaction{
+		driver:   d,
+		ctx:      ctx,
+		span:     span,
+		segments: make(map[string]segment),
+	}, nil
+}
+
+// Start renames the root span
+func (t *transaction) Start(name string) {
+	t.span.SetName(name)
+}
+
+// SetName renames the root span
+func (t *transaction) SetName(name string) error {
+	t.span.SetName(name)
+
+	return nil
+}
+
+// AddTransactionAttribute sets the attribute on the root span
+func (t *transaction) AddTransactionAttribute(key string, value any) error {
+	t.span.SetAttributes(keyValue(key, value))
+
+	return nil
+}
+
+// SegmentStart starts a child span of the root span
+func (t *transaction) SegmentStart(segmentID string, name string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ctx, span := t.driver.tracer.Start(t.ctx, name)
+	t.segments[segmentID] = segment{
+		ctx:  ctx,
+		span: span,
+	}
+
+	return nil
+}
+
+// SegmentStartChild starts a child span of the parent segment span
+func (t *transaction) SegmentStartChild(parentID string, segmentID string, name string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	parent, ok := t.segments[parentID]
+	if !ok {
+		return fmt.Errorf("segment %s not found", parentID)
+	}
+
+	ctx, span := t.driver.tracer.Start(parent.ctx, name)
+	t.segments[segmentID] = segment{
+		ctx:  ctx,
+		span: span,
+	}
+
+	return nil
+}
+
+// AddSegmentAttribute sets the attribute on the segment span
+func (t *transaction) AddSegmentAttribute(segmentID string, key string, value any) error {
+	span, err := t.segmentSpan(segmentID)
+	if err != nil {
+		return err
+	}
+
+	span.SetAttributes(keyValue(key, value))
+
+	return nil
+}
+
+// AddSegmentAttributes sets all attributes on the segment span
+func (t *transaction) AddSegmentAttributes(segmentID string, attributes map[string]any) error {
+	span, err := t.segmentSpan(segmentID)
+	if err != nil {
+		return err
+	}
+
+	span.SetAttributes(keyValues(attributes)...)
+
+	return nil
+}
+
+// AddSegmentEvent adds the event with the attributes to the segment span
+func (t *transaction) AddSegmentEvent(segmentID string, name string, attributes map[string]any) error {
+	span, err := t.segmentSpan(segmentID)
+	if err != nil {
+		return err
+	}
+
+	span.AddEvent(name, trace.WithAttributes(keyValues(attributes)...))
+
+	return nil
+}
+
+// SegmentEnd ends the segment span
+func (t *transaction) SegmentEnd(segmentID string) error {
+	return t.SegmentEndWithStatus(segmentID, telemetry.StatusOK)
+}
+
+// SegmentEndWithStatus sets the span status and ends the segment span. StatusOK leaves the status unchanged,
+// so the error status set by a logged error is kept. A span with StatusDropped is discarded without ending it,
+// so it is never exported
+func (t *transaction) SegmentEndWithStatus(segmentID string, status telemetry.SegmentStatus) error {
+	t.mu.Lock()
+	s, ok := t.segments[segmentID]
+	delete(t.segments, segmentID)
+	t.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("segment %s not found", segmentID)
+	}
+
+	switch status {
+	case telemetry.StatusDropped:
+		return nil
+	case telemetry.StatusOK:
+	default:
+		s.span.SetStatus(codes.Error, status.String())
+	}
+
+	s.span.End()
+
+	if telemetry.Synchronous() {
+		return t.driver.flush()
+	}
+
+	return nil
+}
+
+// AddLink adds a span link to the root span. The trace can be a W3C traceparent or a trace id.
+// A span link needs the span id, so a trace id without it is added as link event instead
+func (t *transaction) AddLink(traceValue string, attributes map[string]any) error {
+	parts := strings.Split(traceValue, "-")
+	if len(parts) == 4 {
+		traceID, err := trace.TraceIDFromHex(parts[1])
+		if err != nil {
+			return err
+		}
+
+		spanID, err := trace.SpanIDFromHex(parts[2])
+		if err != nil {
+			return err
+		}
+
+		t.span.AddLink(trace.Link{
+			SpanContext: trace.NewSpanContext(trace.SpanContextConfig{
+				TraceID:    traceID,
+				SpanID:     spanID,
+				TraceFlags: trace.FlagsSampled,
+				Remote:     true,
+			}),
+			Attributes: keyValues(attributes),
+		})
+
+		return nil
+	}
+
+	traceID, err := trace.TraceIDFromHex(strings.ReplaceAll(traceValue, "-", ""))
+	if err != nil {
+		return err
+	}
+
+	kvs := append(keyValues(attributes), attribute.String(AttributeLinkTrace, traceID.String()))
+	t.span.AddEvent("link", trace.WithAttributes(kvs...))
+
+	return nil
+}
+
+// SetStatus sets the status of the root span, which is kept when the span ends on Done
+func (t *transaction) SetStatus(status telemetry.TransactionStatus) error {
+	if status == telemetry.TransactionStatusOK {
+		t.span.SetStatus(codes.Ok, "")
+		return nil
+	}
+
+	t.span.SetStatus(codes.Error, status.String())
+
+	return nil
+}
+
+// Flush force flushes the tracer provider if it supports it
+func (t *transaction) Flush() error {
+	flusher, ok := t.driver.tracerProvider.(interface {
+		ForceFlush(context.Context) error
+	})
+	if !ok {
+		return nil
+	}
+
+	return flusher.ForceFlush(context.Background())
+}
+
+// Done ends all open segment spans and the root span.
+// If the driver batches the spans, Done exports the pending batch
+func (t *transaction) Done() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, s := range t.segments {
+		s.span.End()
+	}
+
+	t.span.End()
+
+	return t.driver.flush()
+}
+
+// Info adds an info event to the span
+func (t *transaction) Info(segmentID string, rc io.ReadCloser) error {
+	return t.event("info", segmentID, rc)
+}
+
+// Warn adds a warn event to the span
+func (t *transaction) Warn(segmentID string, rc io.ReadCloser) error {
+	return t.event("warn", segmentID, rc)
+}
+
+// Debug adds a debug event to the span
+func (t *transaction) Debug(segmentID string, rc io.ReadCloser) error {
+	return t.event("debug", segmentID, rc)
+}
+
+// Error adds an error event to the span and sets its status to error
+func (t *transaction) Error(segmentID string, rc io.ReadCloser) error {
+	defer rc.Close()
+
+	msg, err := io.ReadAll(rc)
+	if err != nil {
+		return err
+	}
+
+	span, err := t.logSpan(segmentID)
+	if err != nil {
+		return err
+	}
+
+	span.AddEvent("error", trace.WithAttributes(attribute.String(AttributeMessage, string(msg))))
+	span.SetStatus(codes.Error, string(msg))
+
+	return nil
+}
+
+// InfoFields adds an info event with the fields as attributes to the span
+func (t *transaction) InfoFields(segmentID string, fields map[string]any) error {
+	span, err := t.logSpan(segmentID)
+	if err != nil {
+		return err
+	}
+
+	span.AddEvent("info", trace.WithAttributes(keyValues(fields)...))
+
+	return nil
+}
+
+// ErrorFields adds an error event with the fields as attributes to the span and sets its status to error
+func (t *transaction) ErrorFields(segmentID string, fields map[string]any) error {
+	span, err := t.logSpan(segmentID)
+	if err != nil {
+		return err
+	}
+
+	span.AddEvent("error", trace.WithAttributes(keyValues(fields)...))
+	span.SetStatus(codes.Error, fmt.Sprint(fields[telemetry.FieldError]))
+
+	return nil
+}
+
+// CreateTrace returns the OpenTelemetry trace id of the root span
+func (t *transaction) CreateTrace() (string, error) {
+	return t.span.SpanContext().TraceID().String(), nil
+}
+
+// SetTrace sets the trace. A trace which differs from the OpenTelemetry trace id is added as attribute
+func (t *transaction) SetTrace(trace string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.trace = trace
+	t.traceID = trace
+	if trace != t.span.SpanContext().TraceID().String() {
+		t.span.SetAttributes(attribute.String(AttributeTrace, trace))
+	}
+
+	return nil
+}
+
+// ValidateTrace returns an error if the trace is no valid OpenTelemetry trace id, a 32 digit hex string
+// which may be in UUID format. Use it with telemetry.SetTraceValidator
+func ValidateTrace(traceValue string) error {
+	_, err := trace.TraceIDFromHex(strings.ReplaceAll(traceValue, "-", ""))
+	if err != nil {
+		return fmt.Errorf("invalid trace %q: %w", traceValue, err)
+	}
+
+	return nil
+}
+
+// Trace returns the trace set with SetTrace or the OpenTelemetry trace id
+func (t *transaction) Trace() (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.trace == "" {
+		return t.span.SpanContext().TraceID().String(), nil
+	}
+
+	return t.trace, nil
+}
+
+// TraceID returns the trace id set with SetTrace or SetTraceID or the OpenTelemetry trace id
+func (t *transaction) TraceID() (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.traceID == "" {
+		return t.span.SpanContext().TraceID().String(), nil
+	}
+
+	return t.traceID, nil
+}
+
+// SetTraceID sets the trace id of the trace driver as attribute of the root span
+func (t *transaction) SetTraceID(traceID string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.traceID = traceID
+	t.span.SetAttributes(attribute.String(AttributeTraceID, traceID))
+
+	return nil
+}
+
+// Erase removes all segments
+func (t *transaction) Erase() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	clear(t.segments)
+}
+
+// CreateProcessID creates a new random process id
+func (t *transaction) CreateProcessID() (string, error) {
+	return uuid.NewString(), nil
+}
+
+// SetProcessID sets the process id as attribute of the root span
+func (t *transaction) SetProcessID(processID string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.processID = processID
+	t.span.SetAttributes(attribute.String(AttributeProcessID, processID))
+
+	return nil
+}
+
+// ProcessID returns the process id
+func (t *transaction) ProcessID() (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.processID, nil
+}
+
+// SpanID returns the span id of the segment span, or of the root span for an empty segmentID
+func (t *transaction) SpanID(segmentID string) (string, error) {
+	span, err := t.logSpan(segmentID)
+	if err != nil {
+		return "", err
+	}
+
+	return span.SpanContext().SpanID().String(), nil
+}
+
+// event drains the message, already limited by the container, and adds it as event to the span
+func (t *transaction) event(name string, segmentID string, rc io.ReadCloser) error {
+	defer rc.Close()
+
+	msg, err := io.ReadAll(rc)
+	if err != nil {
+		return err
+	}
+
+	span, err := t.logSpan(segmentID)
+	if err != nil {
+		return err
+	}
+
+	span.AddEvent(name, trace.WithAttributes(attribute.String(AttributeMessage, string(msg))))
+
+	return nil
+}
+
+// segmentSpan returns the span of the segment
+func (t *transaction) segmentSpan(segmentID string) (trace.Span, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.segments[segmentID]
+	if !ok {
+		return nil, fmt.Errorf("segment %s not found", segmentID)
+	}
+
+	return s.span, nil
+}
+
+// logSpan returns the root span for an empty segmentID and the segment span otherwise
+func (t *transaction) logSpan(segmentID string) (trace.Span, error) {
+	if segmentID == "" {
+		return t.span, nil
+	}
+
+	return t.segmentSpan(segmentID)
+}
+
+// keyValues converts the attributes into OpenTelemetry attributes
+func keyValues(attributes map[string]any) []attribute.KeyValue {
+	kvs := make([]attribute.KeyValue, 0, len(attributes))
+	for key, value := range attributes {
+		kvs = append(kvs, keyValue(key, value))
+	}
+
+	return kvs
+}
+
+// keyValue converts the attribute into an OpenTelemetry attribute
+func keyValue(key string, value any) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int8:
+		return attribute.Int64(key, int64(v))
+	case int16:
+		return attribute.Int64(key, int64(v))
+	case int32:
+		return attribute.Int64(key, int64(v))
+	case int64:
+		return attribute.Int64(key, v)
+	case uint:
+		return attribute.Int64(key, int64(v))
+	case uint8:
+		return attribute.Int64(key, int64(v))
+	case uint16:
+		return attribute.Int64(key, int64(v))
+	case uint32:
+		return attribute.Int64(key, int64(v))
+	case uint64:
+		return attribute.Int64(key, int64(v))
+	case float32:
+		return attribute.Float64(key, float64(v))
+	case float64:
+		return attribute.Float64(key, v)
+	case time.Duration:
+		return attribute.String(key, v.String())
+	case time.Time:
+		return attribute.String(key, v.Format(time.RFC3339Nano))
+	}
+
+	return attribute.String(key, fmt.Sprint(value))
+}
+
+// SpanContext returns the span context of the root span if the transaction was created by the driver
+func SpanContext(t telemetry.Transaction) (trace.SpanContext, bool) {
+	tr, ok := t.(*transaction)
+	if !ok {
+		return trace.SpanContext{}, false
+	}
+
+	return tr.span.SpanContext(), true
+}
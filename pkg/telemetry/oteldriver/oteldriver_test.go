@@ -0,0 +1,230 @@
+package oteldriver_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/oteldriver"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// newTelemetry returns a telemetry instance with an OpenTelemetry driver exporting into an in-memory exporter
+func newTelemetry(t *testing.T) (*telemetry.Telemetry, *tracetest.InMemoryExporter) {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	tel := telemetry.New()
+	err := tel.RegisterDriver("otel", oteldriver.NewWithExporter(exporter))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tel.SetDriver("otel")
+	tel.SetTraceDriver("otel")
+
+	return tel, exporter
+}
+
+// spanByName returns the exported span with the name
+func spanByName(t *testing.T, spans tracetest.SpanStubs, name string) tracetest.SpanStub {
+	t.Helper()
+
+	for _, span := range spans {
+		if span.Name == name {
+			return span
+		}
+	}
+
+	t.Fatalf("span %s not exported", name)
+
+	return tracetest.SpanStub{}
+}
+
+// hasAttribute reports whether the attributes contain the key with the value
+func hasAttribute(attributes []attribute.KeyValue, key string, value attribute.Value) bool {
+	for _, kv := range attributes {
+		if string(kv.Key) == key && kv.Value == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestSegmentsBecomeSpans(t *testing.T) {
+	tel, exporter := newTelemetry(t)
+
+	transaction, err := tel.Start("checkout")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transaction.AddTransactionAttribute("tenant", "42")
+
+	parentID := transaction.SegmentStart("load cart")
+	transaction.AddSegmentAttribute(parentID, "cart.items", 3)
+
+	childID, err := transaction.SegmentStartChild(parentID, "query")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := "cache miss"
+	transaction.Info(childID, &msg)
+	transaction.SegmentEnd(childID)
+	transaction.SegmentEnd(parentID)
+
+	trace, err := transaction.Trace()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = transaction.Done()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(spans))
+	}
+
+	root := spanByName(t, spans, "checkout")
+	parent := spanByName(t, spans, "load cart")
+	child := spanByName(t, spans, "query")
+
+	if root.Parent.IsValid() {
+		t.Error("expected the transaction span to be a root span")
+	}
+
+	if parent.Parent.SpanID() != root.SpanContext.SpanID() || child.Parent.SpanID() != parent.SpanContext.SpanID() {
+		t.Error("expected the segments to be children of their parents")
+	}
+
+	if root.SpanContext.TraceID().String() != trace {
+		t.Errorf("expected the trace %s to be the OpenTelemetry trace id, got %s", trace, root.SpanContext.TraceID())
+	}
+
+	if !hasAttribute(root.Attributes, "tenant", attribute.StringValue("42")) {
+		t.Errorf("expected the transaction attribute on the root span, got %v", root.Attributes)
+	}
+
+	if !hasAttribute(parent.Attributes, "cart.items", attribute.IntValue(3)) {
+		t.Errorf("expected the segment attribute on the segment span, got %v", parent.Attributes)
+	}
+
+	if len(child.Events) != 1 || child.Events[0].Name != "info" ||
+		!hasAttribute(child.Events[0].Attributes, oteldriver.AttributeMessage, attribute.StringValue(msg)) {
+		t.Errorf("expected an info event with the message, got %v", child.Events)
+	}
+
+	for _, span := range spans {
+		if span.Status.Code != codes.Unset {
+			t.Errorf("expected span %s without error to keep the unset status, got %v", span.Name, span.Status.Code)
+		}
+	}
+}
+
+func TestErrorsSetStatus(t *testing.T) {
+	tel, exporter := newTelemetry(t)
+
+	transaction, err := tel.Start("checkout")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failedID := transaction.SegmentStart("payment")
+	paymentErr := errors.New("card declined")
+	transaction.Error(failedID, &paymentErr)
+	transaction.SegmentEnd(failedID)
+
+	cancelledID := transaction.SegmentStart("shipping")
+	err = transaction.SegmentEndWithStatus(cancelledID, telemetry.StatusCancelled)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = transaction.Done()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spans := exporter.GetSpans()
+
+	payment := spanByName(t, spans, "payment")
+	if payment.Status.Code != codes.Error || payment.Status.Description != "card declined" {
+		t.Errorf("expected the logged error to set the span status, got %+v", payment.Status)
+	}
+
+	if len(payment.Events) != 1 || payment.Events[0].Name != "error" {
+		t.Errorf("expected an error event, got %v", payment.Events)
+	}
+
+	shipping := spanByName(t, spans, "shipping")
+	if shipping.Status.Code != codes.Error || shipping.Status.Description != telemetry.StatusCancelled.String() {
+		t.Errorf("expected the segment status as error status, got %+v", shipping.Status)
+	}
+}
+
+func TestDroppedSegmentsAreNotExported(t *testing.T) {
+	tel, exporter := newTelemetry(t)
+
+	transaction, err := tel.Start("checkout")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	segmentID := transaction.SegmentStart("trivial")
+	err = transaction.SegmentEndWithStatus(segmentID, telemetry.StatusDropped)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = transaction.Done()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, span := range exporter.GetSpans() {
+		if span.Name == "trivial" {
+			t.Error("expected the dropped segment not to be exported")
+		}
+	}
+}
+
+func TestInjectHTTPUsesTheSegmentSpan(t *testing.T) {
+	tel, exporter := newTelemetry(t)
+
+	transaction, err := tel.Start("checkout")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = transaction.StartTracing()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transaction.BeginSegment("call inventory")
+	h := http.Header{}
+	err = transaction.InjectHTTP(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transaction.EndSegment()
+
+	err = transaction.Done()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	segment := spanByName(t, exporter.GetSpans(), "call inventory")
+	expected := "00-" + segment.SpanContext.TraceID().String() + "-" + segment.SpanContext.SpanID().String() + "-01"
+	if h.Get(telemetry.TraceparentHeader) != expected {
+		t.Errorf("expected traceparent %s, got %s", expected, h.Get(telemetry.TraceparentHeader))
+	}
+}
@@ -0,0 +1,169 @@
+// Package otlpdriver provides a telemetry driver exporting spans over OTLP/gRPC, e.g. to an OpenTelemetry collector,
+// without wiring the OpenTelemetry SDK by hand
+package otlpdriver
+
+import (
+	"context"
+	"crypto/tls"
+	"strings"
+	"time"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/oteldriver"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc/credentials"
+)
+
+// DefaultTimeout is the default maximum duration of an export to the collector
+const DefaultTimeout = 10 * time.Second
+
+// ShutdownTimeout is the maximum time Close waits for the pending spans to be exported
+const ShutdownTimeout = 5 * time.Second
+
+// ServiceNameAttribute is the resource attribute set by WithServiceName
+const ServiceNameAttribute = "service.name"
+
+// Option configures a driver created with New
+type Option func(*config)
+
+// config holds the exporter settings of a driver created with New
+type config struct {
+	insecure    bool
+	tlsConfig   *tls.Config
+	headers     map[string]string
+	timeout     time.Duration
+	serviceName string
+	sampleRate  float64
+}
+
+// Driver exports the spans of its transactions in batches to an OTLP/gRPC endpoint
+type Driver struct {
+	telemetry.Driver
+	tracerProvider *sdktrace.TracerProvider
+}
+
+// WithInsecure disables TLS, e.g. for a collector running as sidecar
+func WithInsecure() Option {
+	return func(c *config) {
+		c.insecure = true
+	}
+}
+
+// WithTLS sets the TLS configuration of the connection, e.g. to trust a private CA or send a client certificate
+func WithTLS(tlsConfig *tls.Config) Option {
+	return func(c *config) {
+		c.insecure = false
+		c.tlsConfig = tlsConfig
+	}
+}
+
+// WithHeaders sends the headers with every export, e.g. an authorization token
+func WithHeaders(headers map[string]string) Option {
+	return func(c *config) {
+		c.headers = headers
+	}
+}
+
+// WithTimeout sets the maximum duration of an export. Values below 1 keep DefaultTimeout
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *config) {
+		c.timeout = timeout
+	}
+}
+
+// WithServiceName sets the service name resource attribute of the exported spans
+func WithServiceName(name string) Option {
+	return func(c *config) {
+		c.serviceName = name
+	}
+}
+
+// WithSampleRate samples the fraction of traces between 0 and 1, spans of sampled remote parents are always sampled.
+// Without it every trace is sampled
+func WithSampleRate(rate float64) Option {
+	return func(c *config) {
+		c.sampleRate = rate
+	}
+}
+
+// Factory creates a driver for telemetry.RegisterDriverFactory from the configured endpoint, service name and
+// sample rate. An endpoint with the http:// scheme disables TLS like WithInsecure
+func Factory(settings telemetry.DriverSettings) (telemetry.Driver, error) {
+	opts := []Option{WithServiceName(settings.ServiceName), WithSampleRate(settings.SampleRate)}
+
+	endpoint, ok := strings.CutPrefix(settings.Endpoint, "http://")
+	if ok {
+		opts = append(opts, WithInsecure())
+	}
+
+	return New(strings.TrimPrefix(endpoint, "https://"), opts...)
+}
+
+// New returns a *Driver exporting the spans to the OTLP/gRPC endpoint, e.g. collector:4317.
+// The connection uses TLS with the system roots unless WithInsecure is set. The spans are exported in batches
+// in the background, call Close on shutdown to export the remaining ones
+func New(endpoint string, opts ...Option) (telemetry.Driver, error) {
+	c := config{timeout: DefaultTimeout, sampleRate: 1}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	if c.timeout < 1 {
+		c.timeout = DefaultTimeout
+	}
+
+	exporterOptions := []otlptracegrpc.Option{
+		otlptracegrpc.WithEndpoint(endpoint),
+		otlptracegrpc.WithTimeout(c.timeout),
+	}
+
+	if c.insecure {
+		exporterOptions = append(exporterOptions, otlptracegrpc.WithInsecure())
+	} else {
+		exporterOptions = append(exporterOptions, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(c.tlsConfig)))
+	}
+
+	if len(c.headers) > 0 {
+		exporterOptions = append(exporterOptions, otlptracegrpc.WithHeaders(c.headers))
+	}
+
+	exporter, err := otlptracegrpc.New(context.Background(), exporterOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	providerOptions := []sdktrace.TracerProviderOption{sdktrace.WithBatcher(exporter)}
+	if c.sampleRate < 1 {
+		providerOptions = append(providerOptions, sdktrace.WithSampler(
+			sdktrace.ParentBased(sdktrace.TraceIDRatioBased(c.sampleRate)),
+		))
+	}
+	if c.serviceName != "" {
+		providerOptions = append(providerOptions, sdktrace.WithResource(
+			resource.NewSchemaless(attribute.String(ServiceNameAttribute, c.serviceName)),
+		))
+	}
+
+	tracerProvider := sdktrace.NewTracerProvider(providerOptions...)
+
+	return &Driver{
+		Driver:         oteldriver.New(tracerProvider),
+		tracerProvider: tracerProvider,
+	}, nil
+}
+
+// Close exports the pending spans and closes the connection to the endpoint within the ShutdownTimeout
+func (d *Driver) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+
+	return d.Shutdown(ctx)
+}
+
+// Shutdown exports the pending spans and closes the connection to the endpoint
+func (d *Driver) Shutdown(ctx context.Context) error {
+	return d.tracerProvider.Shutdown(ctx)
+}
@@ -0,0 +1,239 @@
+package otlpdriver_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/otlpdriver"
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// collector is an in-process OTLP/gRPC trace collector keeping the exported spans and request metadata
+type collector struct {
+	collectorpb.UnimplementedTraceServiceServer
+	mu          sync.Mutex
+	serviceName string
+	spans       []*tracepb.Span
+	md          metadata.MD
+}
+
+// Export keeps the spans, the service name resource attribute and the metadata of the request
+func (c *collector) Export(ctx context.Context, req *collectorpb.ExportTraceServiceRequest) (*collectorpb.ExportTraceServiceResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.md, _ = metadata.FromIncomingContext(ctx)
+	for _, resourceSpans := range req.GetResourceSpans() {
+		for _, kv := range resourceSpans.GetResource().GetAttributes() {
+			if kv.GetKey() == otlpdriver.ServiceNameAttribute {
+				c.serviceName = kv.GetValue().GetStringValue()
+			}
+		}
+
+		for _, scopeSpans := range resourceSpans.GetScopeSpans() {
+			c.spans = append(c.spans, scopeSpans.GetSpans()...)
+		}
+	}
+
+	return &collectorpb.ExportTraceServiceResponse{}, nil
+}
+
+// span returns the exported span with the name
+func (c *collector) span(t *testing.T, name string) *tracepb.Span {
+	t.Helper()
+
+	for _, span := range c.spans {
+		if span.GetName() == name {
+			return span
+		}
+	}
+
+	t.Fatalf("expected an exported span %s", name)
+
+	return nil
+}
+
+// listen starts the collector on a local port and returns it with its address
+func listen(t *testing.T) (*collector, string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &collector{}
+	server := grpc.NewServer()
+	collectorpb.RegisterTraceServiceServer(server, c)
+
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	return c, listener.Addr().String()
+}
+
+func TestExportsSpansToCollector(t *testing.T) {
+	c, endpoint := listen(t)
+
+	driver, err := otlpdriver.New(endpoint,
+		otlpdriver.WithInsecure(),
+		otlpdriver.WithServiceName("checkout-service"),
+		otlpdriver.WithHeaders(map[string]string{"authorization": "Bearer token"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tel := telemetry.New()
+	err = tel.RegisterDriver("otlp", driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tel.SetDriver("otlp")
+	tel.SetTraceDriver("otlp")
+
+	transaction, err := tel.Start("checkout")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	segmentID := transaction.SegmentStart("load cart")
+	transaction.AddSegmentAttribute(segmentID, "cart.items", 3)
+	transaction.SegmentEnd(segmentID)
+
+	failedID := transaction.SegmentStart("payment")
+	segmentErr := errors.New("card declined")
+	transaction.Error(failedID, &segmentErr)
+	err = transaction.SegmentEndWithStatus(failedID, telemetry.StatusError)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = transaction.Done()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = driver.(*otlpdriver.Driver).Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.spans) != 3 {
+		t.Fatalf("expected 3 exported spans, got %d", len(c.spans))
+	}
+
+	if c.serviceName != "checkout-service" {
+		t.Errorf("expected the service name checkout-service, got %q", c.serviceName)
+	}
+
+	if values := c.md.Get("authorization"); len(values) != 1 || values[0] != "Bearer token" {
+		t.Errorf("expected the authorization header, got %v", values)
+	}
+
+	root := c.span(t, "checkout")
+	segment := c.span(t, "load cart")
+	if !bytes.Equal(segment.GetParentSpanId(), root.GetSpanId()) {
+		t.Error("expected the segment span to be a child of the root span")
+	}
+
+	attributes := segment.GetAttributes()
+	if len(attributes) != 1 || attributes[0].GetKey() != "cart.items" || attributes[0].GetValue().GetIntValue() != 3 {
+		t.Errorf("expected the segment attribute cart.items, got %v", attributes)
+	}
+
+	if code := segment.GetStatus().GetCode(); code == tracepb.Status_STATUS_CODE_ERROR {
+		t.Errorf("expected no error status, got %s", code)
+	}
+
+	if code := c.span(t, "payment").GetStatus().GetCode(); code != tracepb.Status_STATUS_CODE_ERROR {
+		t.Errorf("expected the error status, got %s", code)
+	}
+}
+
+func TestCloseWithoutCollector(t *testing.T) {
+	driver, err := otlpdriver.New("127.0.0.1:1", otlpdriver.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = driver.(*otlpdriver.Driver).Close()
+	if err != nil {
+		t.Errorf("expected Close without pending spans to succeed, got %v", err)
+	}
+}
+
+func TestFactory(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		sampleRate float64
+		spans      int
+	}{
+		{name: "sampled", sampleRate: 1, spans: 2},
+		{name: "dropped", sampleRate: 0, spans: 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c, endpoint := listen(t)
+
+			driver, err := otlpdriver.Factory(telemetry.DriverSettings{
+				Endpoint:    "http://" + endpoint,
+				ServiceName: "checkout-service",
+				SampleRate:  tt.sampleRate,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tel := telemetry.New()
+			err = tel.RegisterDriver("otlp", driver)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tel.SetDriver("otlp")
+			tel.SetTraceDriver("otlp")
+
+			transaction, err := tel.Start("checkout")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			transaction.SegmentEnd(transaction.SegmentStart("load cart"))
+
+			err = transaction.Done()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = driver.(*otlpdriver.Driver).Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c.mu.Lock()
+			defer c.mu.Unlock()
+
+			if len(c.spans) != tt.spans {
+				t.Fatalf("expected %d exported spans, got %d", tt.spans, len(c.spans))
+			}
+
+			if tt.spans > 0 && c.serviceName != "checkout-service" {
+				t.Errorf("expected the service name checkout-service, got %q", c.serviceName)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package telemetry
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// Transaction attributes added when a panic is recovered
+const (
+	PanicValueAttribute = "panic.value"
+	PanicStackAttribute = "panic.stack"
+)
+
+// RecoverPanic records a panic with its stack trace, ends the transaction and panics again.
+// It has to be deferred directly: defer tc.RecoverPanic()
+func (tc *TransactionContainer) RecoverPanic() {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	tc.recordPanic(r)
+
+	panic(r)
+}
+
+// RecoverAndReturn records a panic with its stack trace, ends the transaction and stores the panic as error in err.
+// It has to be deferred directly: defer tc.RecoverAndReturn(&err)
+func (tc *TransactionContainer) RecoverAndReturn(err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	panicErr := tc.recordPanic(r)
+	if err != nil {
+		*err = panicErr
+	}
+}
+
+// safeCall calls a driver method and returns a panic of the driver as ErrDriverPanic,
+// so a misbehaving driver neither crashes the application nor keeps the other drivers from being called
+func safeCall(fn func() error) (err error) {
+	defer func() {
+		r := recover()
+		if r != nil {
+			err = ErrDriverPanic{Value: r, Stack: debug.Stack()}
+		}
+	}()
+
+	return fn()
+}
+
+// recordPanic logs the panic value and stack on the transaction and ends it
+func (tc *TransactionContainer) recordPanic(r any) error {
+	stack := debug.Stack()
+
+	var err error
+	if rErr, ok := r.(error); ok {
+		err = fmt.Errorf("panic: %w", rErr)
+	} else {
+		err = fmt.Errorf("panic: %v", r)
+	}
+
+	tc.AddTransactionAttribute(PanicValueAttribute, fmt.Sprint(r))
+	tc.AddTransactionAttribute(PanicStackAttribute, string(stack))
+	tc.Error("", &err)
+	tc.Done()
+
+	return err
+}
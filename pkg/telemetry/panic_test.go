@@ -0,0 +1,73 @@
+package telemetry_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/telemetrytest"
+)
+
+// panickingDriver is a recording driver whose transactions panic in SegmentStart and Info
+type panickingDriver struct {
+	*telemetrytest.RecordingDriver
+}
+
+// panickingTransaction panics in SegmentStart and Info
+type panickingTransaction struct {
+	telemetry.Transaction
+}
+
+// InitializeTransaction returns a recording transaction panicking in SegmentStart and Info
+func (d panickingDriver) InitializeTransaction(name string) (telemetry.Transaction, error) {
+	transaction, err := d.RecordingDriver.InitializeTransaction(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return panickingTransaction{Transaction: transaction}, nil
+}
+
+// SegmentStart panics
+func (panickingTransaction) SegmentStart(string, string) error {
+	panic("segment start")
+}
+
+// Info panics
+func (panickingTransaction) Info(string, io.ReadCloser) error {
+	panic("info")
+}
+
+func TestPanickingDriverIsIsolated(t *testing.T) {
+	recorder := telemetrytest.New()
+
+	tel := telemetry.New()
+	for name, driver := range map[string]telemetry.Driver{"panicking": panickingDriver{RecordingDriver: telemetrytest.New()}, "recorder": recorder} {
+		err := tel.RegisterDriver(name, driver)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tel.SetDriver("panicking", "recorder")
+	tel.SetTraceDriver("recorder")
+	transaction := start(t, tel, "panic")
+
+	segmentID, err := transaction.SegmentStartE("segment")
+	var panicErr telemetry.ErrDriverPanic
+	if !errors.As(err, &panicErr) || panicErr.Value != "segment start" {
+		t.Fatalf("expected ErrDriverPanic, got %v", err)
+	}
+
+	msg := "message"
+	transaction.Info(segmentID, &msg)
+	transaction.SegmentEnd(segmentID)
+
+	recorder.AssertSegment(t, "segment")
+	recorder.AssertLog(t, "info", msg)
+
+	if stats := tel.Stats(); stats.DriverErrors < 2 {
+		t.Fatalf("expected the panics as driver errors, got %d", stats.DriverErrors)
+	}
+}
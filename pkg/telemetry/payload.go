@@ -0,0 +1,132 @@
+package telemetry
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// Fields of the info log written for a payload by drivers without payload support
+const (
+	PayloadNameField        = "payload.name"
+	PayloadContentTypeField = "payload.content_type"
+	PayloadSizeField        = "payload.size"
+	PayloadTruncatedField   = "payload.truncated"
+	PayloadEncodingField    = "payload.content_encoding"
+	PayloadDataField        = "payload.data"
+)
+
+// Payload is a named and typed attachment of a segment or transaction, e.g. a request body
+type Payload struct {
+	Name        string
+	ContentType string
+	Data        []byte
+	// Truncated reports whether Data was cut at the payload size limit
+	Truncated bool
+	// ContentEncoding is ContentEncodingGzip if Data is compressed, see SetLogCompression, and empty otherwise
+	ContentEncoding string
+}
+
+// PayloadAttacher is implemented by transactions which can store or preview payloads by their content type.
+// Payloads for transactions without it are logged as InfoFields with the base64 encoded data
+type PayloadAttacher interface {
+	AttachPayload(segmentID string, payload Payload) error
+}
+
+// AttachPayload reads the payload from r and attaches it with its name and MIME type to the segment in the
+// registered driver transactions, e.g. a JSON response body for debugging. Unlike Info the payload keeps its
+// content type. Payloads are limited to the info payload size, see SetInfoBytesSize, longer payloads are truncated
+// and marked as Truncated. Valid UTF-8 payloads pass the redactor under the PayloadDataField key.
+// Payloads are compressed after truncation if compression is enabled, see SetLogCompression.
+// If segmentID is empty, the payload will be attached directly to the transaction
+func (tc *TransactionContainer) AttachPayload(segmentID string, name string, contentType string, r io.Reader) error {
+	var ew ErrorWrapper
+
+	if name == "" {
+		return fmt.Errorf("payload name must not be empty")
+	}
+
+	if !tc.telemetry.logEnabled(LevelInfo) {
+		return nil
+	}
+
+	size := tc.telemetry.infoSize()
+	data, err := io.ReadAll(io.LimitReader(r, int64(size)+1))
+	if err != nil {
+		return fmt.Errorf("could not read payload %s: %w", name, err)
+	}
+
+	payload := Payload{
+		Name:        name,
+		ContentType: contentType,
+		Data:        data,
+	}
+
+	if len(payload.Data) > size {
+		payload.Data = truncatePayload(payload.Data, size)
+		payload.Truncated = true
+		tc.telemetry.counters.logsTruncated.Add(1)
+	}
+
+	if utf8.Valid(payload.Data) {
+		payload.Data = []byte(tc.telemetry.redactMessage(PayloadDataField, string(payload.Data)))
+	}
+
+	payload = tc.telemetry.compressPayload(payload)
+
+	tc.recordLog(segmentID, LevelInfo, "", payloadFields(payload))
+
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+
+	for _, driverName := range tc.driverOrder() {
+		transaction := tc.transactions[driverName]
+		err := safeCall(func() error { return attachPayload(transaction, segmentID, payload) })
+		if err != nil {
+			ew.Add(tc.driverError(driverName, "AttachPayload", err))
+		}
+	}
+
+	return ew.Error()
+}
+
+// truncatePayload cuts data after size bytes. Text is cut before a split rune, so it stays valid UTF-8
+func truncatePayload(data []byte, size int) []byte {
+	cut := size
+	for cut > 0 && cut > size-utf8.UTFMax && !utf8.RuneStart(data[cut]) {
+		cut--
+	}
+
+	if utf8.Valid(data[:cut]) {
+		return data[:cut]
+	}
+
+	return data[:size]
+}
+
+// attachPayload attaches the payload if the transaction implements PayloadAttacher and logs its fields otherwise
+func attachPayload(transaction Transaction, segmentID string, payload Payload) error {
+	if attacher, ok := transaction.(PayloadAttacher); ok {
+		return attacher.AttachPayload(segmentID, payload)
+	}
+
+	return transaction.InfoFields(segmentID, payloadFields(payload))
+}
+
+// payloadFields returns the log fields of the payload with the base64 encoded data
+func payloadFields(payload Payload) map[string]any {
+	fields := map[string]any{
+		PayloadNameField:        payload.Name,
+		PayloadContentTypeField: payload.ContentType,
+		PayloadSizeField:        len(payload.Data),
+		PayloadTruncatedField:   payload.Truncated,
+		PayloadDataField:        base64.StdEncoding.EncodeToString(payload.Data),
+	}
+
+	if payload.ContentEncoding != "" {
+		fields[PayloadEncodingField] = payload.ContentEncoding
+	}
+
+	return fields
+}
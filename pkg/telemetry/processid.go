@@ -0,0 +1,68 @@
+package telemetry
+
+// ProcessIDFormatter converts the raw process id of the trace driver into the displayed process id
+type ProcessIDFormatter func(raw string) string
+
+// ProcessIDParser converts a displayed process id back into the raw process id of the trace driver
+type ProcessIDParser func(formatted string) (string, error)
+
+// SetProcessIDFormatter sets the process id formatter of the default instance
+func SetProcessIDFormatter(formatter ProcessIDFormatter) {
+	defaultTelemetry.SetProcessIDFormatter(formatter)
+}
+
+// SetProcessIDParser sets the process id parser of the default instance
+func SetProcessIDParser(parser ProcessIDParser) {
+	defaultTelemetry.SetProcessIDParser(parser)
+}
+
+// ParseProcessID converts a displayed process id into the raw process id with the default instance
+func ParseProcessID(processID string) (string, error) {
+	return defaultTelemetry.ParseProcessID(processID)
+}
+
+// SetProcessIDFormatter sets the formatter applied to the process id returned by ProcessID and RegenerateProcessID
+// of the transaction containers, e.g. to shorten or prefix it for support tickets. The drivers keep the raw process id.
+// Set the inverse with SetProcessIDParser. A nil formatter restores the raw process id
+func (t *Telemetry) SetProcessIDFormatter(formatter ProcessIDFormatter) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.processIDFormatter = formatter
+}
+
+// SetProcessIDParser sets the inverse of the process id formatter used by ParseProcessID.
+// A nil parser returns the process id unchanged
+func (t *Telemetry) SetProcessIDParser(parser ProcessIDParser) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.processIDParser = parser
+}
+
+// ParseProcessID converts a process id returned by ProcessID of a transaction container into the raw process id
+// of the trace driver, e.g. to look up a process id from a support ticket
+func (t *Telemetry) ParseProcessID(processID string) (string, error) {
+	t.mu.RLock()
+	parser := t.processIDParser
+	t.mu.RUnlock()
+
+	if parser == nil {
+		return processID, nil
+	}
+
+	return parser(processID)
+}
+
+// formatProcessID converts the raw process id with the configured formatter
+func (t *Telemetry) formatProcessID(raw string) string {
+	t.mu.RLock()
+	formatter := t.processIDFormatter
+	t.mu.RUnlock()
+
+	if formatter == nil || raw == "" {
+		return raw
+	}
+
+	return formatter(raw)
+}
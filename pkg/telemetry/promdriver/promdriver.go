@@ -0,0 +1,317 @@
+// Package promdriver provides a telemetry driver exporting transaction counts and segment durations as Prometheus metrics
+package promdriver
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Namespace is the namespace of all metrics of the driver
+const Namespace = "mc_telemetry"
+
+// Transaction outcomes used as label value
+const (
+	OutcomeOK    = "ok"
+	OutcomeError = "error"
+)
+
+// driver records metrics for transactions and segments
+type driver struct {
+	transactionsStarted *prometheus.CounterVec
+	transactionsDone    *prometheus.CounterVec
+	segmentDuration     *prometheus.HistogramVec
+}
+
+// transaction records the metrics of a single transaction
+type transaction struct {
+	driver    driver
+	mu        sync.Mutex
+	name      string
+	segments  map[string]segment
+	errored   bool
+	status    *telemetry.TransactionStatus
+	processID string
+}
+
+// segment holds the data of a started segment
+type segment struct {
+	name  string
+	start time.Time
+}
+
+// New returns a driver whose collectors are registered with reg.
+// If reg is nil prometheus.DefaultRegisterer is used
+func New(reg prometheus.Registerer) (telemetry.Driver, error) {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+
+	d := driver{
+		transactionsStarted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "transactions_started_total",
+			Help:      "Number of started transactions.",
+		}, []string{"transaction"}),
+		transactionsDone: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "transactions_done_total",
+			Help:      "Number of finished transactions by outcome.",
+		}, []string{"transaction", "outcome"}),
+		segmentDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "segment_duration_seconds",
+			Help:      "Duration of segments.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"segment", "status"}),
+	}
+
+	for _, collector := range []prometheus.Collector{d.transactionsStarted, d.transactionsDone, d.segmentDuration} {
+		err := reg.Register(collector)
+		if err != nil {
+			return nil, fmt.Errorf("could not register prometheus collector: %w", err)
+		}
+	}
+
+	return d, nil
+}
+
+// InitializeTransaction returns a transaction recording into the collectors of the driver
+func (d driver) InitializeTransaction(name string) (telemetry.Transaction, error) {
+	return &transaction{
+		driver:   d,
+		name:     name,
+		segments: make(map[string]segment),
+	}, nil
+}
+
+// Start increments the started transactions
+func (t *transaction) Start(name string) {
+	t.mu.Lock()
+	t.name = name
+	t.mu.Unlock()
+
+	t.driver.transactionsStarted.WithLabelValues(name).Inc()
+}
+
+// SetName changes the transaction label of the finished transactions.
+// The started transactions are already counted with the previous name
+func (t *transaction) SetName(name string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.name = name
+
+	return nil
+}
+
+// AddTransactionAttribute ...
+func (t *transaction) AddTransactionAttribute(string, any) error {
+	return nil
+}
+
+// SegmentStart records the start of the segment
+func (t *transaction) SegmentStart(segmentID string, name string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.segments[segmentID] = segment{
+		name:  name,
+		start: time.Now(),
+	}
+
+	return nil
+}
+
+// SegmentStartChild records the start of the segment, metrics have no hierarchy
+func (t *transaction) SegmentStartChild(_ string, segmentID string, name string) error {
+	return t.SegmentStart(segmentID, name)
+}
+
+// AddSegmentAttribute ...
+func (t *transaction) AddSegmentAttribute(string, string, any) error {
+	return nil
+}
+
+// AddSegmentAttributes ...
+func (t *transaction) AddSegmentAttributes(string, map[string]any) error {
+	return nil
+}
+
+// AddSegmentEvent ...
+func (t *transaction) AddSegmentEvent(string, string, map[string]any) error {
+	return nil
+}
+
+// SegmentEnd observes the segment duration with status ok
+func (t *transaction) SegmentEnd(segmentID string) error {
+	return t.SegmentEndWithStatus(segmentID, telemetry.StatusOK)
+}
+
+// SegmentEndWithStatus observes the segment duration labeled by segment name and status.
+// Segments with StatusDropped are not observed
+func (t *transaction) SegmentEndWithStatus(segmentID string, status telemetry.SegmentStatus) error {
+	t.mu.Lock()
+	s, ok := t.segments[segmentID]
+	delete(t.segments, segmentID)
+	if status != telemetry.StatusOK && status != telemetry.StatusDropped {
+		t.errored = true
+	}
+	t.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("segment %s not found", segmentID)
+	}
+
+	if status == telemetry.StatusDropped {
+		return nil
+	}
+
+	t.driver.segmentDuration.WithLabelValues(s.name, status.String()).Observe(time.Since(s.start).Seconds())
+
+	return nil
+}
+
+// AddLink ...
+func (t *transaction) AddLink(string, map[string]any) error {
+	return nil
+}
+
+// SetStatus sets the outcome of the transaction, which takes precedence over errors of segments and logs
+func (t *transaction) SetStatus(status telemetry.TransactionStatus) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.status = &status
+
+	return nil
+}
+
+// Flush ...
+func (t *transaction) Flush() error {
+	return nil
+}
+
+// Done increments the finished transactions labeled by outcome
+func (t *transaction) Done() error {
+	t.mu.Lock()
+	name := t.name
+	outcome := OutcomeOK
+	if t.errored {
+		outcome = OutcomeError
+	}
+	if t.status != nil {
+		outcome = OutcomeOK
+		if *t.status == telemetry.TransactionStatusError {
+			outcome = OutcomeError
+		}
+	}
+	t.mu.Unlock()
+
+	t.driver.transactionsDone.WithLabelValues(name, outcome).Inc()
+
+	return nil
+}
+
+// Info ...
+func (t *transaction) Info(_ string, rc io.ReadCloser) error {
+	return rc.Close()
+}
+
+// Warn ...
+func (t *transaction) Warn(_ string, rc io.ReadCloser) error {
+	return rc.Close()
+}
+
+// Error marks the transaction outcome as error
+func (t *transaction) Error(_ string, rc io.ReadCloser) error {
+	t.markErrored()
+
+	return rc.Close()
+}
+
+// Debug ...
+func (t *transaction) Debug(_ string, rc io.ReadCloser) error {
+	return rc.Close()
+}
+
+// InfoFields ...
+func (t *transaction) InfoFields(string, map[string]any) error {
+	return nil
+}
+
+// ErrorFields marks the transaction outcome as error
+func (t *transaction) ErrorFields(string, map[string]any) error {
+	t.markErrored()
+
+	return nil
+}
+
+// CreateTrace ...
+func (t *transaction) CreateTrace() (string, error) {
+	return "", nil
+}
+
+// SetTrace ...
+func (t *transaction) SetTrace(string) error {
+	return nil
+}
+
+// Trace ...
+func (t *transaction) Trace() (string, error) {
+	return "", nil
+}
+
+// TraceID ...
+func (t *transaction) TraceID() (string, error) {
+	return "", nil
+}
+
+// SetTraceID ...
+func (t *transaction) SetTraceID(string) error {
+	return nil
+}
+
+// Erase removes all segments
+func (t *transaction) Erase() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	clear(t.segments)
+}
+
+// CreateProcessID creates a new random process id
+func (t *transaction) CreateProcessID() (string, error) {
+	return uuid.NewString(), nil
+}
+
+// SetProcessID sets the process id
+func (t *transaction) SetProcessID(processID string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.processID = processID
+
+	return nil
+}
+
+// ProcessID returns the process id
+func (t *transaction) ProcessID() (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.processID, nil
+}
+
+// markErrored sets the transaction outcome to error
+func (t *transaction) markErrored() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.errored = true
+}
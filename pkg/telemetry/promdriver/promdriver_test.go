@@ -0,0 +1,160 @@
+package promdriver_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/promdriver"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// newTelemetry returns a telemetry instance with a Prometheus driver registered with a new registry
+func newTelemetry(t *testing.T) (*telemetry.Telemetry, *prometheus.Registry) {
+	t.Helper()
+
+	reg := prometheus.NewRegistry()
+	driver, err := promdriver.New(reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tel := telemetry.New()
+	err = tel.RegisterDriver("prometheus", driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tel.SetDriver("prometheus")
+	tel.SetTraceDriver("prometheus")
+
+	return tel, reg
+}
+
+// metric returns the metric of the family with the labels or nil if it was not collected
+func metric(t *testing.T, reg *prometheus.Registry, name string, labels map[string]string) *dto.Metric {
+	t.Helper()
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, family := range families {
+		if family.GetName() != promdriver.Namespace+"_"+name {
+			continue
+		}
+
+		for _, m := range family.GetMetric() {
+			matched := 0
+			for _, label := range m.GetLabel() {
+				if labels[label.GetName()] == label.GetValue() {
+					matched++
+				}
+			}
+
+			if matched == len(labels) {
+				return m
+			}
+		}
+	}
+
+	return nil
+}
+
+func TestTransactionsAndSegmentsBecomeMetrics(t *testing.T) {
+	tel, reg := newTelemetry(t)
+
+	transaction, err := tel.Start("checkout")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transaction.SegmentEnd(transaction.SegmentStart("load cart"))
+
+	paymentID := transaction.SegmentStart("payment")
+	err = transaction.SegmentEndWithStatus(paymentID, telemetry.StatusError)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	droppedID := transaction.SegmentStart("trivial")
+	err = transaction.SegmentEndWithStatus(droppedID, telemetry.StatusDropped)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = transaction.Done()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	started := metric(t, reg, "transactions_started_total", map[string]string{"transaction": "checkout"})
+	if started.GetCounter().GetValue() != 1 {
+		t.Errorf("expected 1 started transaction, got %v", started)
+	}
+
+	done := metric(t, reg, "transactions_done_total", map[string]string{"transaction": "checkout", "outcome": promdriver.OutcomeError})
+	if done.GetCounter().GetValue() != 1 {
+		t.Errorf("expected 1 failed transaction, got %v", done)
+	}
+
+	for segment, status := range map[string]string{"load cart": "ok", "payment": "error"} {
+		duration := metric(t, reg, "segment_duration_seconds", map[string]string{"segment": segment, "status": status})
+		if duration.GetHistogram().GetSampleCount() != 1 {
+			t.Errorf("expected 1 observation of segment %s with status %s, got %v", segment, status, duration)
+		}
+	}
+
+	if dropped := metric(t, reg, "segment_duration_seconds", map[string]string{"segment": "trivial"}); dropped != nil {
+		t.Errorf("expected the dropped segment not to be observed, got %v", dropped)
+	}
+}
+
+func TestErrorsSetTheOutcome(t *testing.T) {
+	tel, reg := newTelemetry(t)
+
+	for _, overrideStatus := range []bool{false, true} {
+		transaction, err := tel.Start("refund")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		refundErr := errors.New("refund rejected")
+		transaction.Error("", &refundErr)
+
+		if overrideStatus {
+			err = transaction.SetStatus(telemetry.TransactionStatusOK)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		err = transaction.Done()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, outcome := range []string{promdriver.OutcomeError, promdriver.OutcomeOK} {
+		done := metric(t, reg, "transactions_done_total", map[string]string{"transaction": "refund", "outcome": outcome})
+		if done.GetCounter().GetValue() != 1 {
+			t.Errorf("expected 1 transaction with outcome %s, got %v", outcome, done)
+		}
+	}
+}
+
+func TestNewRejectsDuplicateRegistration(t *testing.T) {
+	reg := prometheus.NewRegistry()
+
+	_, err := promdriver.New(reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = promdriver.New(reg)
+	if err == nil {
+		t.Error("expected an error registering the collectors twice")
+	}
+}
@@ -0,0 +1,199 @@
+package telemetry
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// TraceparentHeader is the W3C trace context header
+const TraceparentHeader = "traceparent"
+
+// TracestateHeader is the W3C trace context header carrying vendor specific trace data
+const TracestateHeader = "tracestate"
+
+// ErrNoTraceContext is returned if no trace context is available
+var ErrNoTraceContext = errors.New("no trace context")
+
+// ErrNoSpanID is returned by SpanID if a transaction does not expose span ids
+var ErrNoSpanID = errors.New("no span id")
+
+// SpanIDProvider is implemented by transactions exposing the span ids of their segments.
+// SpanID returns the 16 character hex span id of the segment, or of the transaction for an empty segmentID
+type SpanIDProvider interface {
+	SpanID(segmentID string) (string, error)
+}
+
+// traceState holds the W3C tracestate of a transaction container
+type traceState struct {
+	mu    sync.RWMutex
+	value string
+}
+
+// InjectHTTP writes the current trace of the trace driver as W3C traceparent header, the tracestate as W3C tracestate
+// header and the baggage as W3C baggage header. A trace in UUID format is used as trace id, every other trace is
+// hashed into one. The parent id is the span id of the active segment, see parentSpanID
+func (tc *TransactionContainer) InjectHTTP(h http.Header) error {
+	trace, err := tc.Trace()
+	if err != nil {
+		return err
+	}
+
+	if trace == "" {
+		return ErrNoTraceContext
+	}
+
+	parentID, err := tc.parentSpanID()
+	if err != nil {
+		return err
+	}
+
+	h.Set(TraceparentHeader, fmt.Sprintf("00-%s-%s-01", traceparentTraceID(trace), parentID))
+	if state := tc.TraceState(); state != "" {
+		h.Set(TracestateHeader, state)
+	}
+	tc.injectBaggage(h)
+
+	return nil
+}
+
+// parentSpanID returns the span id of the active segment, the latest one begun with BeginSegment, or of the
+// transaction without one, if the trace driver implements SpanIDProvider. Otherwise a random span id is returned
+func (tc *TransactionContainer) parentSpanID() (string, error) {
+	segmentID, _ := tc.stack.top()
+
+	var spanID string
+
+	tc.mu.RLock()
+	_, err := tc.traceTransaction("SpanID", func(transaction Transaction) error {
+		provider, ok := transaction.(SpanIDProvider)
+		if !ok {
+			return ErrNoSpanID
+		}
+
+		var err error
+		spanID, err = provider.SpanID(segmentID)
+
+		return err
+	})
+	tc.mu.RUnlock()
+
+	if err == nil && isHex(spanID, 16) && !isZero(spanID) {
+		return spanID, nil
+	}
+
+	random := make([]byte, 8)
+	_, err = rand.Read(random)
+	if err != nil {
+		return "", fmt.Errorf("could not create span id: %w", err)
+	}
+
+	return hex.EncodeToString(random), nil
+}
+
+// SetTraceState sets the W3C tracestate which InjectHTTP passes on unchanged, e.g. the one of ExtractTraceState
+func (tc *TransactionContainer) SetTraceState(state string) {
+	tc.tracestate.mu.Lock()
+	defer tc.tracestate.mu.Unlock()
+
+	tc.tracestate.value = state
+}
+
+// TraceState returns the W3C tracestate set with SetTraceState
+func (tc *TransactionContainer) TraceState() string {
+	tc.tracestate.mu.RLock()
+	defer tc.tracestate.mu.RUnlock()
+
+	return tc.tracestate.value
+}
+
+// ExtractTraceState returns the W3C tracestate header to be used with SetTraceState, multiple header lines are
+// joined. The list members are not validated, an empty string is returned if the header is missing
+func ExtractTraceState(h http.Header) string {
+	return strings.TrimSpace(strings.Join(h.Values(TracestateHeader), ","))
+}
+
+// ExtractHTTP parses the W3C traceparent header and returns the trace id in UUID format to be used with SetTrace.
+// It returns ErrNoTraceContext if the header is missing
+func ExtractHTTP(h http.Header) (string, error) {
+	traceparent := strings.TrimSpace(h.Get(TraceparentHeader))
+	if traceparent == "" {
+		return "", ErrNoTraceContext
+	}
+
+	traceID, err := parseTraceparent(traceparent)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s-%s-%s-%s-%s", traceID[0:8], traceID[8:12], traceID[12:16], traceID[16:20], traceID[20:32]), nil
+}
+
+// traceparentTraceID converts a trace into a 32 character hex trace id
+func traceparentTraceID(trace string) string {
+	traceID := strings.ToLower(strings.ReplaceAll(trace, "-", ""))
+	if isHex(traceID, 32) && !isZero(traceID) {
+		return traceID
+	}
+
+	sum := sha256.Sum256([]byte(trace))
+
+	return hex.EncodeToString(sum[:16])
+}
+
+// parseTraceparent validates the traceparent and returns its trace id
+func parseTraceparent(traceparent string) (string, error) {
+	parts := strings.Split(traceparent, "-")
+	if len(parts) < 4 {
+		return "", fmt.Errorf("malformed traceparent %q: expected 4 fields", traceparent)
+	}
+
+	version, traceID, parentID, flags := parts[0], parts[1], parts[2], parts[3]
+
+	if !isHex(version, 2) || version == "ff" {
+		return "", fmt.Errorf("malformed traceparent %q: invalid version", traceparent)
+	}
+
+	if version == "00" && len(parts) != 4 {
+		return "", fmt.Errorf("malformed traceparent %q: expected 4 fields", traceparent)
+	}
+
+	if !isHex(traceID, 32) || isZero(traceID) {
+		return "", fmt.Errorf("malformed traceparent %q: invalid trace id", traceparent)
+	}
+
+	if !isHex(parentID, 16) || isZero(parentID) {
+		return "", fmt.Errorf("malformed traceparent %q: invalid parent id", traceparent)
+	}
+
+	if !isHex(flags, 2) {
+		return "", fmt.Errorf("malformed traceparent %q: invalid flags", traceparent)
+	}
+
+	return traceID, nil
+}
+
+// isHex reports whether s consists of exactly length lowercase hex characters
+func isHex(s string, length int) bool {
+	if len(s) != length {
+		return false
+	}
+
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isZero reports whether s consists only of zeros
+func isZero(s string) bool {
+	return strings.Trim(s, "0") == ""
+}
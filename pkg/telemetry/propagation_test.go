@@ -0,0 +1,216 @@
+package telemetry_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/telemetrytest"
+)
+
+// spanIDDriver is a recording driver whose transactions expose a span id derived from the segment id
+type spanIDDriver struct {
+	*telemetrytest.RecordingDriver
+}
+
+// spanIDTransaction implements telemetry.SpanIDProvider
+type spanIDTransaction struct {
+	telemetry.Transaction
+}
+
+// InitializeTransaction returns a recording transaction exposing span ids
+func (d spanIDDriver) InitializeTransaction(name string) (telemetry.Transaction, error) {
+	transaction, err := d.RecordingDriver.InitializeTransaction(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return spanIDTransaction{Transaction: transaction}, nil
+}
+
+// SpanID returns the first 16 hex characters of the hashed segment id
+func (spanIDTransaction) SpanID(segmentID string) (string, error) {
+	return testSpanID(segmentID), nil
+}
+
+// testSpanID returns the span id spanIDTransaction returns for the segment
+func testSpanID(segmentID string) string {
+	sum := sha256.Sum256([]byte(segmentID))
+
+	return hex.EncodeToString(sum[:8])
+}
+
+// newSpanIDTelemetry returns a telemetry instance with a spanIDDriver as driver and trace driver
+func newSpanIDTelemetry(t *testing.T) *telemetry.Telemetry {
+	t.Helper()
+
+	tel := telemetry.New()
+	err := tel.RegisterDriver("spans", spanIDDriver{RecordingDriver: telemetrytest.New()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tel.SetDriver("spans")
+	tel.SetTraceDriver("spans")
+
+	return tel
+}
+
+// traceparentParts splits the traceparent header into its four fields
+func traceparentParts(t *testing.T, h http.Header) []string {
+	t.Helper()
+
+	parts := strings.Split(h.Get(telemetry.TraceparentHeader), "-")
+	if len(parts) != 4 {
+		t.Fatalf("expected a traceparent with 4 fields, got %q", h.Get(telemetry.TraceparentHeader))
+	}
+
+	return parts
+}
+
+func TestTraceContextRoundTrip(t *testing.T) {
+	tel := newSpanIDTelemetry(t)
+	upstream := start(t, tel, "upstream")
+	upstream.SetTraceState("vendor=value,other=1")
+
+	_, err := upstream.StartTracing()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	segmentID := upstream.BeginSegment("call")
+	outgoing := http.Header{}
+	err = upstream.InjectHTTP(outgoing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	upstream.EndSegment()
+
+	upstreamParts := traceparentParts(t, outgoing)
+	if upstreamParts[2] != testSpanID(segmentID) {
+		t.Errorf("expected the span id of the active segment %s as parent id, got %s", testSpanID(segmentID), upstreamParts[2])
+	}
+
+	if outgoing.Get(telemetry.TracestateHeader) != "vendor=value,other=1" {
+		t.Errorf("expected the tracestate to be passed through, got %q", outgoing.Get(telemetry.TracestateHeader))
+	}
+
+	trace, err := telemetry.ExtractHTTP(outgoing)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	downstream := start(t, tel, "downstream")
+	err = downstream.SetTrace(trace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	downstream.SetTraceState(telemetry.ExtractTraceState(outgoing))
+
+	forwarded := http.Header{}
+	err = downstream.InjectHTTP(forwarded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	downstreamParts := traceparentParts(t, forwarded)
+	if downstreamParts[1] != upstreamParts[1] {
+		t.Errorf("expected the trace id %s to survive the round trip, got %s", upstreamParts[1], downstreamParts[1])
+	}
+
+	if downstreamParts[2] != testSpanID("") {
+		t.Errorf("expected the span id of the transaction as parent id without active segment, got %s", downstreamParts[2])
+	}
+
+	if forwarded.Get(telemetry.TracestateHeader) != outgoing.Get(telemetry.TracestateHeader) {
+		t.Errorf("expected the tracestate %q, got %q", outgoing.Get(telemetry.TracestateHeader), forwarded.Get(telemetry.TracestateHeader))
+	}
+}
+
+func TestInjectHTTPWithoutSpanIDs(t *testing.T) {
+	tel, _ := telemetrytest.NewTelemetry(t)
+	transaction := start(t, tel, "random")
+
+	_, err := transaction.StartTracing()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := http.Header{}
+	err = transaction.InjectHTTP(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parts := traceparentParts(t, h)
+	if len(parts[2]) != 16 || strings.Trim(parts[2], "0") == "" {
+		t.Errorf("expected a random parent id, got %s", parts[2])
+	}
+
+	if _, ok := h[http.CanonicalHeaderKey(telemetry.TracestateHeader)]; ok {
+		t.Error("expected no tracestate header without tracestate")
+	}
+}
+
+func TestExtractHTTP(t *testing.T) {
+	tests := []struct {
+		name        string
+		traceparent string
+		trace       string
+		err         error
+	}{
+		{
+			name:        "valid",
+			traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			trace:       "4bf92f35-77b3-4da6-a3ce-929d0e0e4736",
+		},
+		{
+			name:        "future version with extra fields",
+			traceparent: "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+			trace:       "4bf92f35-77b3-4da6-a3ce-929d0e0e4736",
+		},
+		{name: "missing", err: telemetry.ErrNoTraceContext},
+		{name: "version 00 with extra fields", traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra"},
+		{name: "invalid version", traceparent: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		{name: "zero trace id", traceparent: "00-00000000000000000000000000000000-00f067aa0ba902b7-01"},
+		{name: "zero parent id", traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01"},
+		{name: "uppercase trace id", traceparent: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01"},
+		{name: "short", traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.Header{}
+			if tt.traceparent != "" {
+				h.Set(telemetry.TraceparentHeader, tt.traceparent)
+			}
+
+			trace, err := telemetry.ExtractHTTP(h)
+			if tt.trace == "" {
+				if err == nil || (tt.err != nil && !errors.Is(err, tt.err)) {
+					t.Fatalf("expected an error, got %q, %v", trace, err)
+				}
+
+				return
+			}
+
+			if err != nil || trace != tt.trace {
+				t.Errorf("expected %s, got %q, %v", tt.trace, trace, err)
+			}
+		})
+	}
+}
+
+func TestExtractTraceStateJoinsHeaderLines(t *testing.T) {
+	h := http.Header{}
+	h.Add(telemetry.TracestateHeader, "vendor=value")
+	h.Add(telemetry.TracestateHeader, "other=1")
+
+	if state := telemetry.ExtractTraceState(h); state != "vendor=value,other=1" {
+		t.Errorf("expected the joined tracestate, got %q", state)
+	}
+}
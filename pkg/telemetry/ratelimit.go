@@ -0,0 +1,133 @@
+package telemetry
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LogsSuppressedMessage is the warning logged on the transaction for the log messages dropped by the log rate limit
+const LogsSuppressedMessage = "%d logs suppressed"
+
+// logSummaryInterval is the minimum time between two LogsSuppressedMessage warnings of a transaction
+const logSummaryInterval = time.Second
+
+// SetLogRateLimit sets the log rate limit of the default instance
+func SetLogRateLimit(perSecond int) {
+	defaultTelemetry.SetLogRateLimit(perSecond)
+}
+
+// SetLogRateLimit limits the Info, Warn and Error messages of each transaction to perSecond with bursts of up to
+// perSecond messages, e.g. to protect the backend from an Error in a tight loop. Each transaction has its own budget,
+// so a noisy transaction does not affect others. Dropped messages are counted and reported as a LogsSuppressedMessage
+// warning on the transaction at most once per second and on Done. Values below 1 disable the limit, which is the default
+func (t *Telemetry) SetLogRateLimit(perSecond int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.logRateLimit = perSecond
+}
+
+// logRateLimitPerSecond returns the maximum number of log messages per second and transaction
+func (t *Telemetry) logRateLimitPerSecond() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.logRateLimit
+}
+
+// logLimiter is the token bucket limiting the log messages of a transaction
+type logLimiter struct {
+	mu          sync.Mutex
+	tokens      float64
+	last        time.Time
+	suppressed  int
+	lastSummary time.Time
+	now         func() time.Time
+}
+
+// allow takes a token from the bucket refilled with limit tokens per second and reports whether the message may be logged.
+// If so and the last summary is at least logSummaryInterval ago, it also returns the number of messages suppressed
+// since the last summary. A limit below 1 allows every message
+func (ll *logLimiter) allow(limit int) (bool, int) {
+	if limit < 1 {
+		return true, 0
+	}
+
+	ll.mu.Lock()
+	defer ll.mu.Unlock()
+
+	now := ll.now()
+	if ll.last.IsZero() {
+		ll.tokens = float64(limit)
+	} else {
+		ll.tokens = min(float64(limit), ll.tokens+now.Sub(ll.last).Seconds()*float64(limit))
+	}
+	ll.last = now
+
+	if ll.tokens < 1 {
+		ll.suppressed++
+		return false, 0
+	}
+
+	ll.tokens--
+
+	if ll.suppressed == 0 || now.Sub(ll.lastSummary) < logSummaryInterval {
+		return true, 0
+	}
+
+	suppressed := ll.suppressed
+	ll.suppressed = 0
+	ll.lastSummary = now
+
+	return true, suppressed
+}
+
+// flush returns the number of messages suppressed since the last summary and resets it
+func (ll *logLimiter) flush() int {
+	ll.mu.Lock()
+	defer ll.mu.Unlock()
+
+	suppressed := ll.suppressed
+	ll.suppressed = 0
+	ll.lastSummary = ll.now()
+
+	return suppressed
+}
+
+// allowLog reports whether a log message passes the log rate limit and logs the pending LogsSuppressedMessage before it
+func (tc *TransactionContainer) allowLog() bool {
+	allowed, suppressed := tc.limiter.allow(tc.telemetry.logRateLimitPerSecond())
+	if !allowed {
+		tc.telemetry.counters.logsSuppressed.Add(1)
+		return false
+	}
+
+	tc.logSuppressed(suppressed)
+
+	return true
+}
+
+// logSuppressed logs the LogsSuppressedMessage warning on the transaction if messages were suppressed
+func (tc *TransactionContainer) logSuppressed(suppressed int) {
+	if suppressed == 0 || !tc.telemetry.logEnabled(LevelWarn) {
+		return
+	}
+
+	message := fmt.Sprintf(LogsSuppressedMessage, suppressed)
+	tc.recordLog("", LevelWarn, message, nil)
+
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+
+	for _, driverName := range tc.driverOrder() {
+		transaction := tc.transactions[driverName]
+		rc := io.NopCloser(strings.NewReader(message))
+		err := safeCall(func() error { return transaction.Warn("", rc) })
+		if err != nil {
+			tc.logDriverError(driverName, "Warn", err)
+		}
+	}
+}
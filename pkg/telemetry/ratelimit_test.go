@@ -0,0 +1,62 @@
+package telemetry_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/telemetrytest"
+)
+
+func TestLogRateLimitSuppressesBurst(t *testing.T) {
+	const (
+		limit = 10
+		burst = 1000
+	)
+
+	tel, recorder := telemetrytest.NewTelemetry(t)
+	clock := telemetrytest.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	tel.SetClock(clock.Now)
+	tel.SetLogRateLimit(limit)
+	transaction := start(t, tel, "burst")
+
+	msg := "tight loop"
+	for i := 0; i < burst; i++ {
+		transaction.Info("", &msg)
+	}
+
+	if logs := len(recorder.Logs()); logs != limit {
+		t.Fatalf("expected %d logs to pass the limit, got %d", limit, logs)
+	}
+
+	suppressed := tel.Stats().LogsSuppressed
+	if suppressed != burst-limit {
+		t.Fatalf("expected %d suppressed logs, got %d", burst-limit, suppressed)
+	}
+
+	clock.Advance(time.Second)
+	transaction.Info("", &msg)
+
+	recorder.AssertLog(t, "warn", fmt.Sprintf(telemetry.LogsSuppressedMessage, burst-limit))
+}
+
+func TestLogRateLimitReportsOnDone(t *testing.T) {
+	tel, recorder := telemetrytest.NewTelemetry(t)
+	clock := telemetrytest.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	tel.SetClock(clock.Now)
+	tel.SetLogRateLimit(1)
+	transaction := start(t, tel, "done")
+
+	msg := "tight loop"
+	for i := 0; i < 3; i++ {
+		transaction.Info("", &msg)
+	}
+
+	err := transaction.Done()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder.AssertLog(t, "warn", fmt.Sprintf(telemetry.LogsSuppressedMessage, 2))
+}
@@ -0,0 +1,85 @@
+package telemetry
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// RedactionMask replaces the values matched by the RegexRedactor
+const RedactionMask = "***"
+
+// Redactor returns the value which is passed to the drivers instead of the provided one.
+// Log messages are passed with the FieldMessage or FieldError key
+type Redactor func(key string, value any) any
+
+// SetRedactor sets the redactor of the default instance
+func SetRedactor(redactor Redactor) {
+	defaultTelemetry.SetRedactor(redactor)
+}
+
+// SetRedactor sets the redactor called for every attribute, field and log message before they are passed to the drivers.
+// A nil redactor passes every value unchanged
+func (t *Telemetry) SetRedactor(redactor Redactor) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.redactor = redactor
+}
+
+// RegexRedactor returns a redactor which replaces all substrings of string values matching one of the patterns with RedactionMask
+func RegexRedactor(patterns ...*regexp.Regexp) Redactor {
+	return func(_ string, value any) any {
+		s, ok := value.(string)
+		if !ok {
+			return value
+		}
+
+		for _, pattern := range patterns {
+			s = pattern.ReplaceAllLiteralString(s, RedactionMask)
+		}
+
+		return s
+	}
+}
+
+// redact returns the value returned by the redactor
+func (t *Telemetry) redact(key string, value any) any {
+	t.mu.RLock()
+	redactor := t.redactor
+	t.mu.RUnlock()
+
+	if redactor == nil {
+		return value
+	}
+
+	return redactor(key, value)
+}
+
+// redactMessage returns the log message returned by the redactor
+func (t *Telemetry) redactMessage(key string, msg string) string {
+	redacted := t.redact(key, msg)
+
+	if s, ok := redacted.(string); ok {
+		return s
+	}
+
+	return fmt.Sprint(redacted)
+}
+
+// redactFields returns a copy of the fields with every value replaced by the one returned by the redactor
+func (t *Telemetry) redactFields(fields map[string]any) map[string]any {
+	t.mu.RLock()
+	redactor := t.redactor
+	t.mu.RUnlock()
+
+	if redactor == nil {
+		return fields
+	}
+
+	redacted := make(map[string]any, len(fields))
+	for key, value := range fields {
+		redacted[key] = redactor(key, value)
+	}
+
+	return redacted
+}
@@ -0,0 +1,262 @@
+package telemetry
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"sort"
+	"time"
+)
+
+// ReplayOption configures Replay
+type ReplayOption func(*replayConfig)
+
+// replayConfig holds the options applied by Replay
+type replayConfig struct {
+	speed       float64
+	originalIDs bool
+}
+
+// replayEvent is a step of a replayed transaction at the time it happened originally
+type replayEvent struct {
+	at    time.Time
+	apply func() error
+}
+
+// WithReplaySpeed replays the transaction speed times faster than it happened, e.g. 10 for a tenth of the time.
+// A speed of 0 or below replays everything without waiting
+func WithReplaySpeed(speed float64) ReplayOption {
+	return func(rc *replayConfig) {
+		rc.speed = speed
+	}
+}
+
+// WithOriginalIDs keeps the process id and the trace of the snapshot instead of creating new ones
+func WithOriginalIDs() ReplayOption {
+	return func(rc *replayConfig) {
+		rc.originalIDs = true
+	}
+}
+
+// Replay sends the transaction of the snapshot again through the active drivers of the default instance
+func Replay(snapshot TransactionSnapshot, opts ...ReplayOption) error {
+	return defaultTelemetry.Replay(snapshot, opts...)
+}
+
+// Replay starts a transaction with the name of the snapshot on the active drivers and replays its attributes,
+// segments, segment events and log lines with their original relative timing, e.g. to reproduce a transaction
+// exported with Snapshot against a local collector. Segments which were open in the snapshot are still open on Done.
+// The replayed transaction is subject to the sampler like every other transaction
+func (t *Telemetry) Replay(snapshot TransactionSnapshot, opts ...ReplayOption) error {
+	rc := replayConfig{speed: 1}
+	for _, opt := range opts {
+		opt(&rc)
+	}
+
+	tc, err := t.Start(snapshot.Name)
+	if err != nil {
+		return fmt.Errorf("could not replay transaction: %w", err)
+	}
+
+	var ew ErrorWrapper
+
+	if rc.originalIDs {
+		ew.Add(tc.replayIDs(snapshot))
+	}
+
+	for key, value := range snapshot.Attributes {
+		tc.AddTransactionAttribute(key, value)
+	}
+
+	events := tc.replayEvents(snapshot)
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].at.Before(events[j].at)
+	})
+
+	start := time.Now()
+	for _, event := range events {
+		if rc.speed > 0 {
+			offset := time.Duration(float64(event.at.Sub(snapshot.Start)) / rc.speed)
+			time.Sleep(time.Until(start.Add(offset)))
+		}
+
+		ew.Add(event.apply())
+	}
+
+	if rc.speed > 0 && snapshot.End != nil {
+		offset := time.Duration(float64(snapshot.End.Sub(snapshot.Start)) / rc.speed)
+		time.Sleep(time.Until(start.Add(offset)))
+	}
+
+	if snapshot.Status != "" {
+		status, err := ParseTransactionStatus(snapshot.Status)
+		if err != nil {
+			ew.Add(err)
+		} else {
+			ew.Add(tc.SetStatus(status))
+		}
+	}
+
+	ew.Add(tc.Done())
+
+	return ew.Error()
+}
+
+// replayIDs sets the process id and the trace of the snapshot
+func (tc *TransactionContainer) replayIDs(snapshot TransactionSnapshot) error {
+	var ew ErrorWrapper
+
+	if snapshot.ProcessID != "" {
+		processID, err := tc.telemetry.ParseProcessID(snapshot.ProcessID)
+		if err != nil {
+			ew.Add(err)
+		} else {
+			ew.Add(tc.SetProcessID(processID))
+		}
+	}
+
+	if snapshot.Trace != "" {
+		ew.Add(tc.SetTrace(snapshot.Trace))
+	}
+
+	return ew.Error()
+}
+
+// replayEvents returns the events of the transaction logs and all segments, parents before their children
+func (tc *TransactionContainer) replayEvents(snapshot TransactionSnapshot) []replayEvent {
+	events := make([]replayEvent, 0, len(snapshot.Logs))
+	for _, log := range snapshot.Logs {
+		log := log
+		events = append(events, replayEvent{
+			at: log.Time,
+			apply: func() error {
+				return tc.replayLog("", log)
+			},
+		})
+	}
+
+	// ids maps the segment ids of the snapshot to the ids of the replayed segments
+	ids := make(map[string]string)
+
+	return append(events, tc.replaySegmentEvents(ids, "", snapshot.Segments)...)
+}
+
+// replaySegmentEvents returns the start, log, event and end events of the segments and their children
+func (tc *TransactionContainer) replaySegmentEvents(ids map[string]string, parentID string, segments []SegmentSnapshot) []replayEvent {
+	var events []replayEvent
+	for _, segment := range segments {
+		segment := segment
+		events = append(events, replayEvent{
+			at: segment.Start,
+			apply: func() error {
+				var (
+					segmentID string
+					err       error
+				)
+
+				if parentID == "" {
+					segmentID, err = tc.SegmentStartE(segment.Name)
+				} else {
+					segmentID, err = tc.SegmentStartChild(ids[parentID], segment.Name)
+				}
+				ids[segment.ID] = segmentID
+
+				if len(segment.Attributes) > 0 {
+					tc.AddSegmentAttributes(segmentID, segment.Attributes)
+				}
+
+				return err
+			},
+		})
+
+		for _, log := range segment.Logs {
+			log := log
+			events = append(events, replayEvent{
+				at: log.Time,
+				apply: func() error {
+					return tc.replayLog(ids[segment.ID], log)
+				},
+			})
+		}
+
+		for _, event := range segment.Events {
+			event := event
+			events = append(events, replayEvent{
+				at: event.Time,
+				apply: func() error {
+					return tc.AddSegmentEvent(ids[segment.ID], event.Name, event.Attributes)
+				},
+			})
+		}
+
+		events = append(events, tc.replaySegmentEvents(ids, segment.ID, segment.Children)...)
+
+		if segment.End == nil {
+			continue
+		}
+
+		events = append(events, replayEvent{
+			at: *segment.End,
+			apply: func() error {
+				status, err := ParseSegmentStatus(segment.Status)
+				if err != nil {
+					return err
+				}
+
+				return tc.SegmentEndWithStatus(ids[segment.ID], status)
+			},
+		})
+	}
+
+	return events
+}
+
+// replayLog logs the log line with its level. Log lines without message are logged with their fields
+func (tc *TransactionContainer) replayLog(segmentID string, log LogSnapshot) error {
+	level, err := ParseLevel(log.Level)
+	if err != nil {
+		return err
+	}
+
+	if log.Message == "" && len(log.Fields) > 0 {
+		fields := maps.Clone(log.Fields)
+
+		var reserved map[string]any
+		for _, key := range []string{FieldMessage, FieldCode, FieldSeverity} {
+			if value, ok := fields[key]; ok {
+				if reserved == nil {
+					reserved = make(map[string]any)
+				}
+				reserved[key] = value
+				delete(fields, key)
+			}
+		}
+
+		if level != LevelError {
+			return tc.infoFields(segmentID, level, fields, reserved)
+		}
+
+		var logErr error
+		if message, ok := fields[FieldError].(string); ok {
+			logErr = errors.New(message)
+			delete(fields, FieldError)
+		}
+
+		return tc.errorFields(segmentID, logErr, fields, reserved)
+	}
+
+	message := log.Message
+	switch level {
+	case LevelError:
+		logErr := errors.New(message)
+		tc.Error(segmentID, &logErr)
+	case LevelWarn:
+		tc.Warn(segmentID, &message)
+	case LevelInfo:
+		tc.Info(segmentID, &message)
+	default:
+		tc.Debug(segmentID, &message)
+	}
+
+	return nil
+}
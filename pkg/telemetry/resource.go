@@ -0,0 +1,52 @@
+package telemetry
+
+import (
+	"log"
+	"maps"
+)
+
+// SetResourceAttributes sets the resource attributes of the default instance
+func SetResourceAttributes(attributes map[string]any) {
+	defaultTelemetry.SetResourceAttributes(attributes)
+}
+
+// ResourceAttributes returns the resource attributes of the default instance
+func ResourceAttributes() map[string]any {
+	return defaultTelemetry.ResourceAttributes()
+}
+
+// SetResourceAttributes sets attributes describing the service, e.g. service.name, service.version,
+// deployment.environment or host.name, which are added to every started transaction.
+// They are added right after the start, so transaction attributes with the same key override them.
+// Attributes with an unsupported type are dropped, a nil map removes all resource attributes
+func (t *Telemetry) SetResourceAttributes(attributes map[string]any) {
+	valid, err := validAttributes(attributes)
+	if err != nil {
+		log.Printf("telemetry Function: SetResourceAttributes | Error: %v", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.resourceAttributes = valid
+	t.resourceAttributesErr = err
+}
+
+// ResourceAttributes returns a copy of the resource attributes added to every started transaction
+func (t *Telemetry) ResourceAttributes() map[string]any {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return maps.Clone(t.resourceAttributes)
+}
+
+// addResourceAttributes adds the resource attributes to the sampled transaction
+func (tc *TransactionContainer) addResourceAttributes() {
+	if !tc.sampled {
+		return
+	}
+
+	for key, value := range tc.telemetry.ResourceAttributes() {
+		tc.AddTransactionAttribute(key, value)
+	}
+}
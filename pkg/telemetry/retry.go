@@ -0,0 +1,100 @@
+package telemetry
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// SetInitRetry sets the initialization retry of the default instance
+func SetInitRetry(attempts int, backoff time.Duration) {
+	defaultTelemetry.SetInitRetry(attempts, backoff)
+}
+
+// SetInitRetry sets how often Start retries a failing driver initialization.
+// The first retry waits for backoff, which doubles with every further retry.
+// Drivers are retried independently of each other. Attempts below 1 disable retries
+func (t *Telemetry) SetInitRetry(attempts int, backoff time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.initRetryAttempts = attempts
+	t.initRetryBackoff = backoff
+}
+
+// initRetry returns the retries and the backoff of a failing driver initialization
+func (t *Telemetry) initRetry() (int, time.Duration) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.initRetryAttempts, t.initRetryBackoff
+}
+
+// initializeTransactions initializes the transactions of the drivers and returns them with the error of each driver.
+// Without retries the drivers are initialized one after another and the first failure stops the initialization.
+// With retries they are initialized concurrently, so the backoff of one driver does not delay the others
+func (t *Telemetry) initializeTransactions(drivers []Driver, name string) ([]Transaction, []error) {
+	transactions := make([]Transaction, len(drivers))
+	errs := make([]error, len(drivers))
+
+	attempts, backoff := t.initRetry()
+	if attempts < 1 {
+		for i, driver := range drivers {
+			transactions[i], errs[i] = initializeTransaction(driver, name, 0, 0)
+			if errs[i] != nil {
+				break
+			}
+		}
+
+		return transactions, errs
+	}
+
+	var wg sync.WaitGroup
+	for i, driver := range drivers {
+		wg.Add(1)
+		go func(i int, driver Driver) {
+			defer wg.Done()
+			transactions[i], errs[i] = initializeTransaction(driver, name, attempts, backoff)
+		}(i, driver)
+	}
+	wg.Wait()
+
+	return transactions, errs
+}
+
+// endTransactions ends the initialized transactions of a failed initialization, so no span or sender goroutine leaks
+func endTransactions(transactions []Transaction) {
+	for _, transaction := range transactions {
+		if transaction == nil {
+			continue
+		}
+
+		err := safeCall(transaction.Done)
+		if err != nil {
+			log.Printf("telemetry Function: Done | Error: %v", err)
+		}
+	}
+}
+
+// initializeTransaction initializes the transaction of the driver and retries up to attempts times on failure.
+// It returns the error of the last attempt if all attempts fail
+func initializeTransaction(driver Driver, name string, attempts int, backoff time.Duration) (Transaction, error) {
+
+	var transaction Transaction
+	initialize := func() error {
+		var err error
+		transaction, err = driver.InitializeTransaction(name)
+
+		return err
+	}
+
+	err := safeCall(initialize)
+	for i := 0; err != nil && i < attempts; i++ {
+		time.Sleep(backoff)
+		backoff *= 2
+
+		err = safeCall(initialize)
+	}
+
+	return transaction, err
+}
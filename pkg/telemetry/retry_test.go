@@ -0,0 +1,49 @@
+package telemetry_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/telemetrytest"
+)
+
+func TestFailedInitializationEndsTransactions(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		attempts int
+	}{
+		{name: "without retries"},
+		{name: "with retries", attempts: 2},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := telemetrytest.New()
+
+			tel := telemetry.New()
+			tel.SetInitRetry(tt.attempts, time.Millisecond)
+			for name, driver := range map[string]telemetry.Driver{"first": recorder, "second": failingDriver{}} {
+				err := tel.RegisterDriver(name, driver)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			tel.SetDriver("first", "second")
+			tel.SetTraceDriver("first")
+
+			_, err := tel.Start("failing")
+			if err == nil {
+				t.Fatal("expected the initialization of the second driver to fail")
+			}
+
+			if transactions := recorder.Transactions(); len(transactions) != 1 {
+				t.Fatalf("expected the first driver to be initialized once, got %v", transactions)
+			}
+
+			if !slices.Contains(recorder.Calls(), "Done") {
+				t.Errorf("expected the transaction of the first driver to be ended, got calls %v", recorder.Calls())
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package telemetry
+
+import "runtime"
+
+// Transaction attributes added by SetCaptureRuntimeStats on start
+const (
+	RuntimeGoroutinesAttribute = "runtime.goroutines"
+	RuntimeHeapAllocAttribute  = "runtime.heap_alloc_bytes"
+	RuntimeGCPauseAttribute    = "runtime.gc_pause_total_ns"
+	RuntimeNumGCAttribute      = "runtime.num_gc"
+)
+
+// Transaction attributes added by SetCaptureRuntimeStats on Done
+const (
+	RuntimeGoroutinesDeltaAttribute = "runtime.goroutines_delta"
+	RuntimeHeapAllocDeltaAttribute  = "runtime.heap_alloc_delta_bytes"
+	RuntimeGCPauseDeltaAttribute    = "runtime.gc_pause_delta_ns"
+	RuntimeNumGCDeltaAttribute      = "runtime.num_gc_delta"
+)
+
+// runtimeStats is a snapshot of the Go runtime taken on start and Done of a transaction
+type runtimeStats struct {
+	goroutines int
+	heapAlloc  uint64
+	gcPause    uint64
+	numGC      uint32
+}
+
+// SetCaptureRuntimeStats enables or disables capturing the runtime stats of the default instance
+func SetCaptureRuntimeStats(capture bool) {
+	defaultTelemetry.SetCaptureRuntimeStats(capture)
+}
+
+// SetCaptureRuntimeStats adds the goroutine count, the heap allocation and the GC pauses as transaction attributes on
+// start and their deltas since the start on Done, as per transaction memory pressure signal.
+// The heap and GC stats are read with runtime.ReadMemStats, which stops the world for a short time on every start
+// and Done, so it is disabled by default. The deltas include the work of all concurrent transactions
+func (t *Telemetry) SetCaptureRuntimeStats(capture bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.captureRuntimeStats = capture
+}
+
+// capturesRuntimeStats reports whether the runtime stats are captured
+func (t *Telemetry) capturesRuntimeStats() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.captureRuntimeStats
+}
+
+// readRuntimeStats returns the current runtime stats
+func readRuntimeStats() runtimeStats {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	return runtimeStats{
+		goroutines: runtime.NumGoroutine(),
+		heapAlloc:  memStats.HeapAlloc,
+		gcPause:    memStats.PauseTotalNs,
+		numGC:      memStats.NumGC,
+	}
+}
+
+// addRuntimeStats adds the runtime stats as transaction attributes if they are captured and keeps them for Done
+func (tc *TransactionContainer) addRuntimeStats() {
+	if !tc.sampled || !tc.telemetry.capturesRuntimeStats() {
+		return
+	}
+
+	stats := readRuntimeStats()
+
+	tc.record.mu.Lock()
+	tc.record.runtimeStats = &stats
+	tc.record.mu.Unlock()
+
+	tc.AddTransactionAttribute(RuntimeGoroutinesAttribute, stats.goroutines)
+	tc.AddTransactionAttribute(RuntimeHeapAllocAttribute, stats.heapAlloc)
+	tc.AddTransactionAttribute(RuntimeGCPauseAttribute, stats.gcPause)
+	tc.AddTransactionAttribute(RuntimeNumGCAttribute, stats.numGC)
+}
+
+// addRuntimeStatsDelta adds the deltas of the runtime stats since the start with AddTransactionAttribute
+// if they were captured on start. The start stats are taken, so the deltas are only added by the first Done.
+// The caller must not hold the lock
+func (tc *TransactionContainer) addRuntimeStatsDelta() {
+	tc.record.mu.Lock()
+	start := tc.record.runtimeStats
+	tc.record.runtimeStats = nil
+	tc.record.mu.Unlock()
+
+	if start == nil {
+		return
+	}
+
+	stats := readRuntimeStats()
+
+	tc.AddTransactionAttribute(RuntimeGoroutinesDeltaAttribute, stats.goroutines-start.goroutines)
+	tc.AddTransactionAttribute(RuntimeHeapAllocDeltaAttribute, int64(stats.heapAlloc)-int64(start.heapAlloc))
+	tc.AddTransactionAttribute(RuntimeGCPauseDeltaAttribute, stats.gcPause-start.gcPause)
+	tc.AddTransactionAttribute(RuntimeNumGCDeltaAttribute, stats.numGC-start.numGC)
+}
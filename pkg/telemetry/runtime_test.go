@@ -0,0 +1,53 @@
+package telemetry_test
+
+import (
+	"testing"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/telemetrytest"
+)
+
+func TestCaptureRuntimeStats(t *testing.T) {
+	tel, recorder := telemetrytest.NewTelemetry(t)
+	tel.SetCaptureRuntimeStats(true)
+	tel.SetRedactor(func(key string, value any) any {
+		if key == telemetry.RuntimeHeapAllocDeltaAttribute {
+			return "redacted"
+		}
+
+		return value
+	})
+
+	transaction := start(t, tel, "runtime")
+	err := transaction.Done()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = transaction.Done()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counts := make(map[string]int)
+	for _, attribute := range recorder.TransactionAttributes() {
+		counts[attribute.Key]++
+	}
+
+	for _, key := range []string{
+		telemetry.RuntimeGoroutinesAttribute,
+		telemetry.RuntimeHeapAllocAttribute,
+		telemetry.RuntimeGCPauseAttribute,
+		telemetry.RuntimeNumGCAttribute,
+		telemetry.RuntimeGoroutinesDeltaAttribute,
+		telemetry.RuntimeHeapAllocDeltaAttribute,
+		telemetry.RuntimeGCPauseDeltaAttribute,
+		telemetry.RuntimeNumGCDeltaAttribute,
+	} {
+		if counts[key] != 1 {
+			t.Errorf("expected attribute %s once, got %d", key, counts[key])
+		}
+	}
+
+	recorder.AssertAttribute(t, telemetry.RuntimeHeapAllocDeltaAttribute, "redacted")
+}
@@ -0,0 +1,39 @@
+package telemetry
+
+// Sampler decides whether the transaction with the provided name is recorded
+type Sampler func(name string) bool
+
+// SetSampler sets the sampler of the default instance
+func SetSampler(sampler Sampler) {
+	defaultTelemetry.SetSampler(sampler)
+}
+
+// SetSampler sets the sampler deciding at Start whether a transaction is recorded.
+// Transactions which are not sampled are backed by the noop driver. A nil sampler records every transaction,
+// Validate reports it as configuration mistake
+func (t *Telemetry) SetSampler(sampler Sampler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.sampler = sampler
+	t.samplerSet = true
+}
+
+// sample reports whether the transaction with the provided name is recorded
+func (t *Telemetry) sample(name string) bool {
+	t.mu.RLock()
+	sampler := t.sampler
+	t.mu.RUnlock()
+
+	if sampler == nil {
+		return true
+	}
+
+	return sampler(name)
+}
+
+// Sampled reports whether the transaction is recorded by the activated drivers.
+// It can be used to skip expensive attribute computation
+func (tc *TransactionContainer) Sampled() bool {
+	return tc.sampled
+}
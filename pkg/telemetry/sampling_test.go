@@ -0,0 +1,55 @@
+package telemetry_test
+
+import (
+	"testing"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/telemetrytest"
+)
+
+func TestSamplerDropsTransactions(t *testing.T) {
+	tel, recorder := telemetrytest.NewTelemetry(t)
+	tel.SetSampler(func(string) bool { return false })
+
+	transaction := start(t, tel, "sampled-out")
+	if transaction.Sampled() {
+		t.Fatal("expected the transaction not to be sampled")
+	}
+
+	segmentID := transaction.SegmentStart("segment")
+	transaction.AddSegmentAttribute(segmentID, "key", "value")
+	msg := "message"
+	transaction.Info(segmentID, &msg)
+	transaction.SegmentEnd(segmentID)
+
+	err := transaction.Done()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if calls := recorder.Calls(); len(calls) != 0 {
+		t.Fatalf("expected no calls on the driver, got %v", calls)
+	}
+
+	if stats := tel.Stats(); stats.TransactionsSampledOut != 1 {
+		t.Fatalf("expected 1 sampled out transaction, got %d", stats.TransactionsSampledOut)
+	}
+}
+
+func TestSamplerKeepsTransactions(t *testing.T) {
+	tel, recorder := telemetrytest.NewTelemetry(t)
+	tel.SetSampler(func(name string) bool { return name == "kept" })
+
+	transaction := start(t, tel, "kept")
+	if !transaction.Sampled() {
+		t.Fatal("expected the transaction to be sampled")
+	}
+
+	transaction.SegmentEnd(transaction.SegmentStart("segment"))
+
+	err := transaction.Done()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder.AssertSegment(t, "segment")
+}
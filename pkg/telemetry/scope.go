@@ -0,0 +1,44 @@
+package telemetry
+
+// ScopedContainer is a view on a transaction container which prefixes every attribute key.
+// It shares the transactions and segments of the container it was created from
+type ScopedContainer struct {
+	*TransactionContainer
+	prefix string
+}
+
+// WithPrefix returns a view which prepends prefix + "." to the keys of all attributes added through it
+func (tc *TransactionContainer) WithPrefix(prefix string) *ScopedContainer {
+	return &ScopedContainer{
+		TransactionContainer: tc,
+		prefix:               prefix + ".",
+	}
+}
+
+// WithPrefix returns a view which additionally prepends prefix + "." to the prefixed keys
+func (sc *ScopedContainer) WithPrefix(prefix string) *ScopedContainer {
+	return &ScopedContainer{
+		TransactionContainer: sc.TransactionContainer,
+		prefix:               sc.prefix + prefix + ".",
+	}
+}
+
+// AddTransactionAttribute adds the attribute with the prefixed key to the transactions
+func (sc *ScopedContainer) AddTransactionAttribute(name string, attribute any) {
+	sc.TransactionContainer.AddTransactionAttribute(sc.prefix+name, attribute)
+}
+
+// AddSegmentAttribute adds the attribute with the prefixed key to the segment
+func (sc *ScopedContainer) AddSegmentAttribute(segmentID string, name string, attribute any) {
+	sc.TransactionContainer.AddSegmentAttribute(segmentID, sc.prefix+name, attribute)
+}
+
+// AddSegmentAttributes adds the attributes with the prefixed keys to the segment
+func (sc *ScopedContainer) AddSegmentAttributes(segmentID string, attributes map[string]any) {
+	prefixed := make(map[string]any, len(attributes))
+	for key, value := range attributes {
+		prefixed[sc.prefix+key] = value
+	}
+
+	sc.TransactionContainer.AddSegmentAttributes(segmentID, prefixed)
+}
@@ -0,0 +1,36 @@
+package telemetry_test
+
+import (
+	"testing"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/telemetrytest"
+)
+
+func TestWithPrefix(t *testing.T) {
+	tel, recorder := telemetrytest.NewTelemetry(t)
+	transaction := start(t, tel, "scope")
+
+	db := transaction.WithPrefix("db")
+	http := transaction.WithPrefix("http")
+
+	segmentID := db.SegmentStart("query")
+	db.AddTransactionAttribute("count", 1)
+	http.AddTransactionAttribute("count", 2)
+	db.AddSegmentAttribute(segmentID, "rows", 3)
+	db.WithPrefix("replica").AddSegmentAttributes(segmentID, map[string]any{"lag": 4})
+	transaction.AddTransactionAttribute("count", 5)
+	db.SegmentEnd(segmentID)
+
+	recorder.AssertAttribute(t, "db.count", 1)
+	recorder.AssertAttribute(t, "http.count", 2)
+	recorder.AssertAttribute(t, "db.rows", 3)
+	recorder.AssertAttribute(t, "db.replica.lag", 4)
+	recorder.AssertAttribute(t, "count", 5)
+	recorder.AssertSegment(t, "query")
+
+	for _, attribute := range recorder.SegmentAttributes() {
+		if attribute.SegmentID != segmentID {
+			t.Errorf("expected attribute %s on the segment of the scoped view, got %s", attribute.Key, attribute.SegmentID)
+		}
+	}
+}
@@ -0,0 +1,553 @@
+package telemetry
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OpenSegmentsAttribute is the transaction attribute listing the segments which were still open on Done
+const OpenSegmentsAttribute = "segments_open"
+
+// errSegmentEnded is returned for a segment which already ended with another status
+var errSegmentEnded = errors.New("already ended")
+
+// SegmentStatus is the outcome of a segment
+type SegmentStatus int
+
+// Available segment statuses
+const (
+	StatusOK SegmentStatus = iota
+	StatusError
+	StatusCancelled
+	// StatusDropped is passed to the drivers for segments ending faster than the minimum segment duration.
+	// Drivers discard the segment with its attributes and logs instead of exporting it where possible
+	StatusDropped
+)
+
+// String returns the name of the status
+func (s SegmentStatus) String() string {
+	switch s {
+	case StatusOK:
+		return "ok"
+	case StatusError:
+		return "error"
+	case StatusCancelled:
+		return "cancelled"
+	case StatusDropped:
+		return "dropped"
+	}
+
+	return fmt.Sprintf("status(%d)", int(s))
+}
+
+// ParseSegmentStatus returns the status for the provided name (ok, error, cancelled or dropped)
+func ParseSegmentStatus(name string) (SegmentStatus, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "ok":
+		return StatusOK, nil
+	case "error":
+		return StatusError, nil
+	case "cancelled", "canceled":
+		return StatusCancelled, nil
+	case "dropped":
+		return StatusDropped, nil
+	}
+
+	return StatusOK, fmt.Errorf("unknown telemetry segment status %q", name)
+}
+
+// segmentRegistry keeps track of the segments started through a transaction container
+type segmentRegistry struct {
+	mu       sync.Mutex
+	segments map[string]*segmentState
+	now      func() time.Time
+	// retain keeps the ended segments with their log lines, events and attributes for the snapshot.
+	// Otherwise ended segments are removed once they have no open children, see release
+	retain bool
+}
+
+// segmentState holds the bookkeeping of a single segment
+type segmentState struct {
+	name     string
+	parentID string
+	ended    bool
+	status   SegmentStatus
+	// openChildren counts the started but not ended children of the segment
+	openChildren int
+	start        time.Time
+	end          time.Time
+	// attributes, logs and events are retained for the snapshot
+	attributes        map[string]any
+	logs              []LogSnapshot
+	events            []EventSnapshot
+	droppedAttributes int
+	// inherited holds the default attributes the segment started with, see segmentDefaults
+	inherited map[string]any
+	// pending holds the attributes collected until the segment ends, see SetDeferredSegmentAttributes
+	pending map[string]any
+	// stop is closed when the segment ends, see watch
+	stop chan struct{}
+}
+
+// newSegmentRegistry returns an empty segment registry taking the times from now
+func newSegmentRegistry(now func() time.Time, retain bool) *segmentRegistry {
+	return &segmentRegistry{
+		segments: make(map[string]*segmentState),
+		now:      now,
+		retain:   retain,
+	}
+}
+
+// start records a started segment. It returns an error if the segmentID is empty or already in use
+func (sr *segmentRegistry) start(segmentID string, parentID string, name string) error {
+	if segmentID == "" {
+		return errors.New("segment id must not be empty")
+	}
+
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	if _, ok := sr.segments[segmentID]; ok {
+		return fmt.Errorf("segment id %s already in use", segmentID)
+	}
+
+	if parent, ok := sr.segments[parentID]; ok {
+		parent.openChildren++
+	}
+
+	sr.segments[segmentID] = &segmentState{
+		name:     name,
+		parentID: parentID,
+		start:    sr.now(),
+	}
+
+	return nil
+}
+
+// end marks a segment as ended with the provided status and returns the status to pass to the drivers.
+// A segment ending with StatusOK faster than minDuration ends with StatusDropped, its attributes and logs are discarded.
+// It reports whether the segment was active and returns an error if it already ended with another status
+func (sr *segmentRegistry) end(segmentID string, status SegmentStatus, minDuration time.Duration) (SegmentStatus, bool, error) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	segment, ok := sr.segments[segmentID]
+	if !ok {
+		return status, false, fmt.Errorf("segment %s not found", segmentID)
+	}
+
+	if segment.ended {
+		if segment.status != status && (segment.status != StatusDropped || status != StatusOK) {
+			return status, false, fmt.Errorf("segment %s %w with status %s", segmentID, errSegmentEnded, segment.status)
+		}
+
+		return segment.status, false, nil
+	}
+
+	segment.ended = true
+	segment.status = status
+	segment.end = sr.now()
+
+	if parent, ok := sr.segments[segment.parentID]; ok {
+		parent.openChildren--
+	}
+
+	if segment.stop != nil {
+		close(segment.stop)
+	}
+
+	if status == StatusOK && segment.end.Sub(segment.start) < minDuration {
+		segment.status = StatusDropped
+		segment.pending = nil
+	}
+
+	if !sr.retain || segment.status == StatusDropped {
+		segment.attributes = nil
+		segment.inherited = nil
+		segment.logs = nil
+		segment.events = nil
+	}
+
+	return segment.status, true, nil
+}
+
+// active returns an error if the segment is unknown or already ended
+func (sr *segmentRegistry) active(segmentID string) error {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	segment, ok := sr.segments[segmentID]
+	if !ok {
+		return fmt.Errorf("segment %s not found", segmentID)
+	}
+
+	if segment.ended {
+		return fmt.Errorf("segment %s already ended", segmentID)
+	}
+
+	return nil
+}
+
+// duration returns the elapsed time of the segment until now or until its end
+func (sr *segmentRegistry) duration(segmentID string) (time.Duration, error) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	segment, ok := sr.segments[segmentID]
+	if !ok {
+		return 0, fmt.Errorf("segment %s not found", segmentID)
+	}
+
+	if segment.ended {
+		return segment.end.Sub(segment.start), nil
+	}
+
+	return sr.now().Sub(segment.start), nil
+}
+
+// addAttributes retains the attributes of a segment and returns the attributes to pass to the drivers.
+// Attributes already set are merged with the policy, the keys of the dropped ones are returned.
+// Attributes exceeding the limit are dropped and replaced by the AttributesDroppedAttribute count.
+// It reports whether the limit was exceeded for the first time. A limit below 1 disables the limit.
+// Attributes of unknown segments are returned unchanged
+func (sr *segmentRegistry) addAttributes(segmentID string, attributes map[string]any, limit int, policy AttributeMergePolicy) (map[string]any, bool, []string) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	segment, ok := sr.segments[segmentID]
+	if !ok {
+		return attributes, false, nil
+	}
+
+	if segment.attributes == nil {
+		segment.attributes = make(map[string]any, len(attributes))
+	}
+
+	attributes, duplicates := mergeAttributes(segment.attributes, attributes, policy)
+	limited, limitExceeded := limitAttributes(segment.attributes, &segment.droppedAttributes, attributes, limit)
+
+	return limited, limitExceeded, duplicates
+}
+
+// watch returns a channel which is closed when the segment ends, or nil if the segment is unknown or ended
+func (sr *segmentRegistry) watch(segmentID string) <-chan struct{} {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	segment, ok := sr.segments[segmentID]
+	if !ok || segment.ended {
+		return nil
+	}
+
+	if segment.stop == nil {
+		segment.stop = make(chan struct{})
+	}
+
+	return segment.stop
+}
+
+// inherit retains the default attributes the segment started with
+func (sr *segmentRegistry) inherit(segmentID string, defaults map[string]any) {
+	if len(defaults) == 0 {
+		return
+	}
+
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	segment, ok := sr.segments[segmentID]
+	if !ok {
+		return
+	}
+
+	segment.inherited = defaults
+}
+
+// attribute returns the retained attribute of a segment, an attribute set on the segment wins over an inherited one
+func (sr *segmentRegistry) attribute(segmentID string, key string) (any, bool) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	segment, ok := sr.segments[segmentID]
+	if !ok {
+		return nil, false
+	}
+
+	value, ok := segment.attributes[key]
+	if ok {
+		return value, true
+	}
+
+	value, ok = segment.inherited[key]
+
+	return value, ok
+}
+
+// addLog retains a log line of a segment if snapshots are enabled. Log lines of unknown segments are dropped
+func (sr *segmentRegistry) addLog(segmentID string, log LogSnapshot) {
+	if !sr.retain {
+		return
+	}
+
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	segment, ok := sr.segments[segmentID]
+	if !ok {
+		return
+	}
+
+	segment.logs = append(segment.logs, log)
+}
+
+// addEvent retains an event of the segment if snapshots are enabled
+func (sr *segmentRegistry) addEvent(segmentID string, event EventSnapshot) {
+	if !sr.retain {
+		return
+	}
+
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	segment, ok := sr.segments[segmentID]
+	if !ok {
+		return
+	}
+
+	segment.events = append(segment.events, event)
+}
+
+// open returns the IDs of all started but not ended segments ordered by their start
+func (sr *segmentRegistry) open() []string {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	segmentIDs := make([]string, 0)
+	for segmentID, segment := range sr.segments {
+		if !segment.ended {
+			segmentIDs = append(segmentIDs, segmentID)
+		}
+	}
+
+	sort.Slice(segmentIDs, func(i, j int) bool {
+		return sr.segments[segmentIDs[i]].start.Before(sr.segments[segmentIDs[j]].start)
+	})
+
+	return segmentIDs
+}
+
+// release removes the ended segment and its ended ancestors once they have no open children,
+// unless the segments are retained for the snapshot. Long running transactions thereby only keep their open segments
+func (sr *segmentRegistry) release(segmentID string) {
+	if sr.retain {
+		return
+	}
+
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	for {
+		segment, ok := sr.segments[segmentID]
+		if !ok || !segment.ended || segment.openChildren > 0 {
+			return
+		}
+
+		delete(sr.segments, segmentID)
+		segmentID = segment.parentID
+	}
+}
+
+// clear removes all recorded segments
+func (sr *segmentRegistry) clear() {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	clear(sr.segments)
+}
+
+// SegmentDuration returns the elapsed time of the segment since its start.
+// If the segment already ended the duration between start and end is returned. Ended segments are only
+// known while they have open children or with SetSnapshotEnabled, otherwise an error is returned
+func (tc *TransactionContainer) SegmentDuration(segmentID string) (time.Duration, error) {
+	return tc.segments.duration(segmentID)
+}
+
+// startSegment records a started segment, see segmentRegistry.start
+func (tc *TransactionContainer) startSegment(segmentID string, parentID string, name string) error {
+	err := tc.segments.start(segmentID, parentID, name)
+	if err != nil {
+		return err
+	}
+
+	tc.telemetry.counters.segmentsStarted.Add(1)
+
+	return nil
+}
+
+// endSegment marks a segment as ended with the minimum segment duration applied, see segmentRegistry.end
+func (tc *TransactionContainer) endSegment(segmentID string, status SegmentStatus) (SegmentStatus, bool, error) {
+	status, active, err := tc.segments.end(segmentID, status, tc.telemetry.segmentDurationThreshold())
+	if active && status == StatusDropped {
+		tc.telemetry.counters.segmentsDropped.Add(1)
+	}
+
+	return status, active, err
+}
+
+// OpenSegments returns the IDs of the segments which were started but not ended yet, ordered by their start
+func (tc *TransactionContainer) OpenSegments() []string {
+	return tc.segments.open()
+}
+
+// endOpenSegments ends all open segments with StatusError in the registered driver transactions,
+// starting with the latest one. The IDs are logged as warning and added as OpenSegmentsAttribute.
+// Like other ended segments they are only kept in the snapshot with SetSnapshotEnabled.
+// The caller must hold the write lock
+func (tc *TransactionContainer) endOpenSegments() error {
+	var ew ErrorWrapper
+
+	segmentIDs := tc.segments.open()
+	if len(segmentIDs) == 0 {
+		return nil
+	}
+
+	tc.telemetry.counters.segmentsLeaked.Add(uint64(len(segmentIDs)))
+
+	openSegments := strings.Join(segmentIDs, ",")
+	log.Printf("telemetry transaction | Warning: segments not ended before Done: %s", openSegments)
+
+	for i := len(segmentIDs) - 1; i >= 0; i-- {
+		tc.segments.end(segmentIDs[i], StatusError, 0)
+		pending := tc.segments.takePending(segmentIDs[i])
+		tc.segments.release(segmentIDs[i])
+
+		for _, driverName := range tc.driverOrder() {
+			transaction := tc.transactions[driverName]
+			if len(pending) > 0 {
+				err := safeCall(func() error { return transaction.AddSegmentAttributes(segmentIDs[i], pending) })
+				if err != nil {
+					ew.Add(tc.driverError(driverName, "AddSegmentAttributes", err))
+				}
+			}
+
+			err := safeCall(func() error { return transaction.SegmentEndWithStatus(segmentIDs[i], StatusError) })
+			if err != nil {
+				ew.Add(tc.driverError(driverName, "SegmentEndWithStatus", err))
+			}
+		}
+	}
+
+	tc.record.addAttribute(OpenSegmentsAttribute, openSegments, 0)
+	for _, driverName := range tc.driverOrder() {
+		transaction := tc.transactions[driverName]
+		err := safeCall(func() error { return transaction.AddTransactionAttribute(OpenSegmentsAttribute, openSegments) })
+		if err != nil {
+			ew.Add(tc.driverError(driverName, "AddTransactionAttribute", err))
+		}
+	}
+
+	return ew.Error()
+}
+
+// SegmentEndWithStatus ends a segment with the provided status in the registered driver transactions.
+// Ending a segment again with the same status is a no-op, ending it with another status returns an error.
+// Without SetSnapshotEnabled ended segments are forgotten once they have no open children, ending them again
+// then returns a not found error.
+// A segment ending with StatusOK faster than the minimum segment duration is passed on with StatusDropped
+func (tc *TransactionContainer) SegmentEndWithStatus(segmentID string, status SegmentStatus) error {
+	var ew ErrorWrapper
+
+	status, active, err := tc.endSegment(segmentID, status)
+	if err != nil || !active {
+		return err
+	}
+
+	pending := tc.segments.takePending(segmentID)
+	tc.segments.release(segmentID)
+
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+
+	for _, driverName := range tc.driverOrder() {
+		transaction := tc.transactions[driverName]
+		if len(pending) > 0 {
+			err := safeCall(func() error { return transaction.AddSegmentAttributes(segmentID, pending) })
+			if err != nil {
+				ew.Add(tc.driverError(driverName, "AddSegmentAttributes", err))
+			}
+		}
+
+		err := safeCall(func() error { return transaction.SegmentEndWithStatus(segmentID, status) })
+		if err != nil {
+			ew.Add(tc.driverError(driverName, "SegmentEndWithStatus", err))
+		}
+	}
+
+	return ew.Error()
+}
+
+// SegmentEndWithAttributes adds the attributes to the segment and ends it with StatusOK in the registered driver transactions.
+// No other call of the container reaches the drivers between adding the attributes and ending the segment.
+// Attributes with an unsupported type are dropped, all attributes are dropped if the segment ends with StatusDropped
+func (tc *TransactionContainer) SegmentEndWithAttributes(segmentID string, attributes map[string]any) error {
+	var ew ErrorWrapper
+
+	attributes, err := tc.validAttributes(attributes)
+	if err != nil {
+		ew.Add(err)
+	}
+
+	attributes = tc.limitSegmentAttributes(segmentID, attributes)
+
+	status, active, err := tc.endSegment(segmentID, StatusOK)
+	if err != nil {
+		ew.Add(err)
+		return ew.Error()
+	}
+
+	if !active {
+		return ew.Error()
+	}
+
+	pending := tc.segments.takePending(segmentID)
+	tc.segments.release(segmentID)
+	if len(pending) > 0 {
+		maps.Copy(pending, attributes)
+		attributes = pending
+	}
+
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	for _, driverName := range tc.driverOrder() {
+		transaction := tc.transactions[driverName]
+		if status == StatusDropped {
+			err := safeCall(func() error { return transaction.SegmentEndWithStatus(segmentID, StatusDropped) })
+			if err != nil {
+				ew.Add(tc.driverError(driverName, "SegmentEndWithStatus", err))
+			}
+
+			continue
+		}
+
+		if len(attributes) > 0 {
+			err := safeCall(func() error { return transaction.AddSegmentAttributes(segmentID, attributes) })
+			if err != nil {
+				ew.Add(tc.driverError(driverName, "AddSegmentAttributes", err))
+			}
+		}
+
+		err := safeCall(func() error { return transaction.SegmentEnd(segmentID) })
+		if err != nil {
+			ew.Add(tc.driverError(driverName, "SegmentEnd", err))
+		}
+	}
+
+	return ew.Error()
+}
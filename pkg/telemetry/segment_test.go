@@ -0,0 +1,111 @@
+package telemetry_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/telemetrytest"
+)
+
+// errSegmentStart is returned by the segment starts of rejectingDriver
+var errSegmentStart = errors.New("segment start rejected")
+
+// rejectingDriver is a recording driver whose transactions reject every segment start
+type rejectingDriver struct {
+	*telemetrytest.RecordingDriver
+}
+
+// rejectingTransaction rejects every segment start
+type rejectingTransaction struct {
+	telemetry.Transaction
+}
+
+// InitializeTransaction returns a recording transaction rejecting segment starts
+func (d rejectingDriver) InitializeTransaction(name string) (telemetry.Transaction, error) {
+	transaction, err := d.RecordingDriver.InitializeTransaction(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return rejectingTransaction{Transaction: transaction}, nil
+}
+
+// SegmentStart returns errSegmentStart
+func (rejectingTransaction) SegmentStart(string, string) error {
+	return errSegmentStart
+}
+
+func TestSegmentStartEReturnsDriverErrors(t *testing.T) {
+	accepting := telemetrytest.New()
+	rejecting := rejectingDriver{RecordingDriver: telemetrytest.New()}
+
+	tel := telemetry.New()
+	for name, driver := range map[string]telemetry.Driver{"accepting": accepting, "rejecting": rejecting} {
+		err := tel.RegisterDriver(name, driver)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tel.SetDriver("accepting", "rejecting")
+	tel.SetTraceDriver("accepting")
+	transaction := start(t, tel, "segment")
+
+	segmentID, err := transaction.SegmentStartE("segment")
+	if !errors.Is(err, errSegmentStart) {
+		t.Fatalf("expected the error of the rejecting driver, got %v", err)
+	}
+
+	var driverErr telemetry.ErrDriverMethod
+	if !errors.As(err, &driverErr) || driverErr.Driver != "rejecting" {
+		t.Fatalf("expected ErrDriverMethod of the rejecting driver, got %v", err)
+	}
+
+	if segmentID == "" {
+		t.Fatal("expected the segment id despite the error")
+	}
+
+	accepting.AssertSegment(t, "segment")
+}
+
+// benchmarkAttributes are the attributes added to a segment by the attribute benchmarks
+var benchmarkAttributes = func() map[string]any {
+	attributes := make(map[string]any, 20)
+	for i := 0; i < 20; i++ {
+		attributes[fmt.Sprintf("attribute.%d", i)] = i
+	}
+
+	return attributes
+}()
+
+func BenchmarkAddSegmentAttribute(b *testing.B) {
+	transaction := start(b, newNoopTelemetry(), "benchmark")
+	defer transaction.Done()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		segmentID := transaction.SegmentStart("segment")
+		for key, value := range benchmarkAttributes {
+			transaction.AddSegmentAttribute(segmentID, key, value)
+		}
+		transaction.SegmentEnd(segmentID)
+	}
+}
+
+func BenchmarkAddSegmentAttributes(b *testing.B) {
+	transaction := start(b, newNoopTelemetry(), "benchmark")
+	defer transaction.Done()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		segmentID := transaction.SegmentStart("segment")
+		transaction.AddSegmentAttributes(segmentID, benchmarkAttributes)
+		transaction.SegmentEnd(segmentID)
+	}
+}
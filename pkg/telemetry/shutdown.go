@@ -0,0 +1,82 @@
+package telemetry
+
+import (
+	"context"
+	"io"
+)
+
+// Shutdowner is implemented by drivers which have to be shut down at process exit,
+// e.g. to export buffered spans or close connection pools
+type Shutdowner interface {
+	Shutdown(ctx context.Context) error
+}
+
+// Shutdown shuts down all drivers registered in the default instance
+func Shutdown(ctx context.Context) error {
+	return defaultTelemetry.Shutdown(ctx)
+}
+
+// Shutdown shuts down every registered driver implementing Shutdowner or io.Closer and joins their errors.
+// It is the process level teardown, call it once at exit after all transactions are done:
+// defer telemetry.Shutdown(ctx). A nil ctx is treated as context.Background
+func (t *Telemetry) Shutdown(ctx context.Context) error {
+	var ew ErrorWrapper
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	for _, name := range t.RegisteredDrivers() {
+		err := ctx.Err()
+		if err != nil {
+			ew.Add(err)
+			break
+		}
+
+		driver, err := t.getDriver(name)
+		if err != nil {
+			continue
+		}
+
+		err = safeCall(func() error { return shutdownDriver(ctx, driver) })
+		if err != nil {
+			t.counters.driverErrors.Add(1)
+			ew.Add(ErrDriverMethod{Driver: name, Function: "Shutdown", Err: err})
+		}
+	}
+
+	return ew.Error()
+}
+
+// shutdownDriver shuts the driver down if it implements Shutdowner or io.Closer
+func shutdownDriver(ctx context.Context, driver Driver) error {
+	switch d := driver.(type) {
+	case Shutdowner:
+		return d.Shutdown(ctx)
+	case io.Closer:
+		return d.Close()
+	}
+
+	return nil
+}
+
+// Shutdown shuts down the wrapped driver. Queued operations are applied on Done of their transaction, not here
+func (d *AsyncDriver) Shutdown(ctx context.Context) error {
+	return shutdownDriver(ctx, d.inner)
+}
+
+// Shutdown shuts down the wrapped driver
+func (d *TimeoutDriver) Shutdown(ctx context.Context) error {
+	return shutdownDriver(ctx, d.inner)
+}
+
+// Shutdown shuts down all grouped drivers
+func (md multiDriver) Shutdown(ctx context.Context) error {
+	var ew ErrorWrapper
+
+	for _, driver := range md.drivers {
+		ew.Add(shutdownDriver(ctx, driver))
+	}
+
+	return ew.Error()
+}
@@ -0,0 +1,63 @@
+package telemetry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/telemetrytest"
+)
+
+// shutdownDriver is a recording driver counting its shutdowns
+type shutdownDriver struct {
+	*telemetrytest.RecordingDriver
+	shutdowns int
+}
+
+// Shutdown counts the call
+func (d *shutdownDriver) Shutdown(ctx context.Context) error {
+	d.shutdowns++
+
+	return ctx.Err()
+}
+
+func TestShutdownWithNilContext(t *testing.T) {
+	tel := telemetry.New()
+	driver := &shutdownDriver{RecordingDriver: telemetrytest.New()}
+	err := tel.RegisterDriver("shutdown", driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ctx context.Context
+	err = tel.Shutdown(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if driver.shutdowns != 1 {
+		t.Fatalf("expected 1 shutdown, got %d", driver.shutdowns)
+	}
+}
+
+func TestShutdownWithCancelledContext(t *testing.T) {
+	tel := telemetry.New()
+	driver := &shutdownDriver{RecordingDriver: telemetrytest.New()}
+	err := tel.RegisterDriver("shutdown", driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = tel.Shutdown(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	if driver.shutdowns != 0 {
+		t.Fatalf("expected no shutdown, got %d", driver.shutdowns)
+	}
+}
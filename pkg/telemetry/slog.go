@@ -0,0 +1,144 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+)
+
+// Fields added by the slog handler to every record
+const (
+	ProcessIDField = "process_id"
+	TraceIDField   = "trace_id"
+)
+
+// slogHandler routes slog records to the logging methods of a transaction container
+type slogHandler struct {
+	tc *TransactionContainer
+	// fields are the attributes added with WithAttrs, flattened with their group prefix
+	fields map[string]any
+	// prefix is the dot separated path of the groups opened with WithGroup
+	prefix string
+}
+
+// NewSlogHandler returns a slog.Handler logging the records in the transaction container, so code using slog
+// is tied to the transaction without rewriting its logging calls:
+// slog.New(telemetry.NewSlogHandler(&tc))
+// Records are logged with their attributes as fields, the message under FieldMessage, the OTel severity number
+// under FieldSeverity and the process id and trace id. Error records are logged with ErrorFields, all others with
+// InfoFields. Groups are flattened into dot separated keys. A segment id in the context passed to the slog
+// methods, see Segment, logs the record on the segment instead of the transaction
+func NewSlogHandler(tc *TransactionContainer) slog.Handler {
+	return &slogHandler{tc: tc}
+}
+
+// Enabled reports whether records of the level pass the log level of the telemetry instance
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.tc.telemetry.logEnabled(slogLevel(level))
+}
+
+// Handle logs the record in the transaction container
+func (h *slogHandler) Handle(ctx context.Context, record slog.Record) error {
+	fields := make(map[string]any, len(h.fields)+record.NumAttrs()+2)
+	maps.Copy(fields, h.fields)
+	record.Attrs(func(attr slog.Attr) bool {
+		addSlogAttr(fields, h.prefix, attr)
+		return true
+	})
+
+	processID, err := h.tc.ProcessID()
+	if err == nil && processID != "" {
+		fields[ProcessIDField] = processID
+	}
+
+	traceID, err := h.tc.TraceID()
+	if err == nil && traceID != "" {
+		fields[TraceIDField] = traceID
+	}
+
+	segmentID, _ := SegmentIDFromContext(ctx)
+	level := slogLevel(record.Level)
+
+	if level == LevelError {
+		return h.tc.errorFields(segmentID, errors.New(record.Message), fields, map[string]any{
+			FieldSeverity: level.OTelSeverity(),
+		})
+	}
+
+	return h.tc.infoFields(segmentID, level, fields, map[string]any{
+		FieldMessage:  record.Message,
+		FieldSeverity: level.OTelSeverity(),
+	})
+}
+
+// WithAttrs returns a handler adding the attributes to every record, within the currently open groups
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	fields := maps.Clone(h.fields)
+	if fields == nil {
+		fields = make(map[string]any, len(attrs))
+	}
+
+	for _, attr := range attrs {
+		addSlogAttr(fields, h.prefix, attr)
+	}
+
+	return &slogHandler{
+		tc:     h.tc,
+		fields: fields,
+		prefix: h.prefix,
+	}
+}
+
+// WithGroup returns a handler nesting the attributes added afterwards under the group
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	return &slogHandler{
+		tc:     h.tc,
+		fields: h.fields,
+		prefix: h.prefix + name + ".",
+	}
+}
+
+// addSlogAttr adds the resolved attribute to fields with prefix prepended to its key.
+// Groups are flattened, groups without key are inlined and empty attributes and groups are left out
+func addSlogAttr(fields map[string]any, prefix string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+
+	if attr.Value.Kind() != slog.KindGroup {
+		fields[prefix+attr.Key] = attr.Value.Any()
+		return
+	}
+
+	if attr.Key != "" {
+		prefix += attr.Key + "."
+	}
+
+	for _, groupAttr := range attr.Value.Group() {
+		addSlogAttr(fields, prefix, groupAttr)
+	}
+}
+
+// slogLevel returns the level of the slog level, levels between two slog levels round down
+func slogLevel(level slog.Level) Level {
+	switch {
+	case level >= slog.LevelError:
+		return LevelError
+	case level >= slog.LevelWarn:
+		return LevelWarn
+	case level >= slog.LevelInfo:
+		return LevelInfo
+	}
+
+	return LevelDebug
+}
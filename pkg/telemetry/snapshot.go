@@ -0,0 +1,348 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"maps"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TransactionSnapshot is a serializable summary of a transaction container
+type TransactionSnapshot struct {
+	Name       string            `json:"name"`
+	ProcessID  string            `json:"process_id,omitempty"`
+	Trace      string            `json:"trace,omitempty"`
+	Status     string            `json:"status,omitempty"`
+	Start      time.Time         `json:"start"`
+	End        *time.Time        `json:"end,omitempty"`
+	Attributes map[string]any    `json:"attributes,omitempty"`
+	Logs       []LogSnapshot     `json:"logs,omitempty"`
+	Segments   []SegmentSnapshot `json:"segments,omitempty"`
+}
+
+// SegmentSnapshot is a serializable summary of a segment and its child segments
+type SegmentSnapshot struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Status     string            `json:"status,omitempty"`
+	Start      time.Time         `json:"start"`
+	End        *time.Time        `json:"end,omitempty"`
+	Attributes map[string]any    `json:"attributes,omitempty"`
+	Logs       []LogSnapshot     `json:"logs,omitempty"`
+	Events     []EventSnapshot   `json:"events,omitempty"`
+	Children   []SegmentSnapshot `json:"children,omitempty"`
+}
+
+// EventSnapshot is a point in time event of a segment
+type EventSnapshot struct {
+	Name       string         `json:"name"`
+	Attributes map[string]any `json:"attributes,omitempty"`
+	Time       time.Time      `json:"time"`
+}
+
+// LogSnapshot is a log line of a transaction or segment
+type LogSnapshot struct {
+	Level   string         `json:"level"`
+	Message string         `json:"message,omitempty"`
+	Fields  map[string]any `json:"fields,omitempty"`
+	Time    time.Time      `json:"time"`
+}
+
+// transactionRecord retains the transaction data of a container for its snapshot
+type transactionRecord struct {
+	mu         sync.Mutex
+	name       string
+	attributes map[string]any
+	logs       []LogSnapshot
+	final      *TransactionSnapshot
+	// droppedAttributes counts the attributes dropped because of the limit
+	droppedAttributes int
+	// inheritAttributes overrides the inheritance of the transaction attributes by segments if set
+	inheritAttributes *bool
+	// status is the transaction status set with SetStatus, nil if none was set
+	status *TransactionStatus
+	// deferSegmentAttributes collects the segment attributes until the segment ends
+	deferSegmentAttributes bool
+	// runtimeStats holds the runtime stats captured on start, nil if they are not captured
+	runtimeStats *runtimeStats
+	// retain keeps the log lines, see SetSnapshotEnabled
+	retain bool
+}
+
+// newTransactionRecord returns an empty record for the transaction with the provided name
+func newTransactionRecord(name string, retain bool) *transactionRecord {
+	return &transactionRecord{
+		name:       name,
+		attributes: make(map[string]any),
+		retain:     retain,
+	}
+}
+
+// SetSnapshotEnabled enables or disables retaining the data for Snapshot in the default instance
+func SetSnapshotEnabled(enabled bool) {
+	defaultTelemetry.SetSnapshotEnabled(enabled)
+}
+
+// SetSnapshotEnabled makes transactions started afterwards retain their log lines, segment events and the
+// attributes of ended segments until Done, so Snapshot returns the complete transaction.
+// Retaining them costs memory for the whole lifetime of a transaction, so it is disabled by default.
+// Without it ended segments are forgotten once they have no open children, so Snapshot only contains the
+// transaction attributes and the open segments, and SegmentDuration and GetSegmentAttribute do not find ended segments
+func (t *Telemetry) SetSnapshotEnabled(enabled bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.snapshotEnabled = enabled
+}
+
+// snapshotsEnabled reports whether transactions retain the data for Snapshot
+func (t *Telemetry) snapshotsEnabled() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.snapshotEnabled
+}
+
+// addAttribute retains a transaction attribute and returns the attributes to pass to the drivers.
+// It behaves like segmentRegistry.addAttributes
+func (tr *transactionRecord) addAttribute(key string, value any, limit int) (map[string]any, bool) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	return limitAttributes(tr.attributes, &tr.droppedAttributes, map[string]any{key: value}, limit)
+}
+
+// attribute returns a retained transaction attribute
+func (tr *transactionRecord) attribute(key string) (any, bool) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	value, ok := tr.attributes[key]
+
+	return value, ok
+}
+
+// rename changes the retained transaction name
+func (tr *transactionRecord) rename(name string) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	tr.name = name
+}
+
+// addLog retains a transaction log line if snapshots are enabled
+func (tr *transactionRecord) addLog(log LogSnapshot) {
+	if !tr.retain {
+		return
+	}
+
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	tr.logs = append(tr.logs, log)
+}
+
+// finish keeps the snapshot taken when the transaction is done
+func (tr *transactionRecord) finish(snapshot TransactionSnapshot) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	tr.final = &snapshot
+}
+
+// Snapshot returns a summary of the transaction with its attributes, segments and log lines.
+// After Done the snapshot taken when the transaction finished is returned.
+// Ended segments with their log lines, events and attributes are only retained with SetSnapshotEnabled
+func (tc *TransactionContainer) Snapshot() TransactionSnapshot {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+
+	tc.record.mu.Lock()
+	final := tc.record.final
+	tc.record.mu.Unlock()
+
+	if final != nil {
+		return *final
+	}
+
+	return tc.snapshot()
+}
+
+// snapshot builds the summary of the transaction. The caller must hold the read lock
+func (tc *TransactionContainer) snapshot() TransactionSnapshot {
+	snapshot := TransactionSnapshot{
+		Segments: tc.segments.snapshot(),
+	}
+
+	_, _ = tc.traceTransaction("ProcessID", func(transaction Transaction) error {
+		var err error
+		snapshot.ProcessID, err = transaction.ProcessID()
+
+		return err
+	})
+	snapshot.ProcessID = tc.telemetry.formatProcessID(snapshot.ProcessID)
+
+	_, _ = tc.traceTransaction("Trace", func(transaction Transaction) error {
+		var err error
+		snapshot.Trace, err = transaction.Trace()
+
+		return err
+	})
+
+	tc.timing.mu.Lock()
+	snapshot.Start = tc.timing.start
+	if !tc.timing.end.IsZero() {
+		end := tc.timing.end
+		snapshot.End = &end
+	}
+	tc.timing.mu.Unlock()
+
+	tc.record.mu.Lock()
+	snapshot.Name = tc.record.name
+	snapshot.Attributes = maps.Clone(tc.record.attributes)
+	snapshot.Logs = append([]LogSnapshot(nil), tc.record.logs...)
+	if tc.record.status != nil {
+		snapshot.Status = tc.record.status.String()
+	}
+	tc.record.mu.Unlock()
+
+	return snapshot
+}
+
+// recordLog retains a log line on the transaction or on the segment
+func (tc *TransactionContainer) recordLog(segmentID string, level Level, message string, fields map[string]any) {
+	log := LogSnapshot{
+		Level:   level.String(),
+		Message: message,
+		Fields:  fields,
+		Time:    tc.telemetry.now(),
+	}
+
+	if segmentID == "" {
+		tc.record.addLog(log)
+		return
+	}
+
+	tc.segments.addLog(segmentID, log)
+}
+
+// snapshot returns the tree of all recorded segments ordered by their start
+func (sr *segmentRegistry) snapshot() []SegmentSnapshot {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	children := make(map[string][]string, len(sr.segments))
+	for segmentID, segment := range sr.segments {
+		if segment.status == StatusDropped {
+			continue
+		}
+
+		parentID := segment.parentID
+		if parent, ok := sr.segments[parentID]; !ok || parent.status == StatusDropped {
+			parentID = ""
+		}
+
+		children[parentID] = append(children[parentID], segmentID)
+	}
+
+	return sr.snapshotChildren(children, "")
+}
+
+// snapshotChildren returns the snapshots of the children of parentID. The caller must hold the lock
+func (sr *segmentRegistry) snapshotChildren(children map[string][]string, parentID string) []SegmentSnapshot {
+	ids := children[parentID]
+	if len(ids) == 0 {
+		return nil
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		return sr.segments[ids[i]].start.Before(sr.segments[ids[j]].start)
+	})
+
+	snapshots := make([]SegmentSnapshot, 0, len(ids))
+	for _, segmentID := range ids {
+		segment := sr.segments[segmentID]
+
+		snapshot := SegmentSnapshot{
+			ID:         segmentID,
+			Name:       segment.name,
+			Start:      segment.start,
+			Attributes: maps.Clone(segment.attributes),
+			Logs:       append([]LogSnapshot(nil), segment.logs...),
+			Events:     append([]EventSnapshot(nil), segment.events...),
+			Children:   sr.snapshotChildren(children, segmentID),
+		}
+
+		if segment.ended {
+			end := segment.end
+			snapshot.Status = segment.status.String()
+			snapshot.End = &end
+		}
+
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots
+}
+
+// MarshalJSON encodes the snapshot with durations as strings
+func (ts TransactionSnapshot) MarshalJSON() ([]byte, error) {
+	type transactionSnapshot TransactionSnapshot
+
+	ts.Attributes = jsonAttributes(ts.Attributes)
+	ts.Logs = jsonLogs(ts.Logs)
+
+	return json.Marshal(transactionSnapshot(ts))
+}
+
+// MarshalJSON encodes the snapshot with durations as strings
+func (ss SegmentSnapshot) MarshalJSON() ([]byte, error) {
+	type segmentSnapshot SegmentSnapshot
+
+	ss.Attributes = jsonAttributes(ss.Attributes)
+	ss.Logs = jsonLogs(ss.Logs)
+	ss.Events = jsonEvents(ss.Events)
+
+	return json.Marshal(segmentSnapshot(ss))
+}
+
+// jsonLogs returns a copy of the logs with the durations of their fields as strings
+func jsonLogs(logs []LogSnapshot) []LogSnapshot {
+	encoded := make([]LogSnapshot, len(logs))
+	for i, log := range logs {
+		log.Fields = jsonAttributes(log.Fields)
+		encoded[i] = log
+	}
+
+	return encoded
+}
+
+// jsonEvents returns a copy of the events with the durations of their attributes as strings
+func jsonEvents(events []EventSnapshot) []EventSnapshot {
+	encoded := make([]EventSnapshot, len(events))
+	for i, event := range events {
+		event.Attributes = jsonAttributes(event.Attributes)
+		encoded[i] = event
+	}
+
+	return encoded
+}
+
+// jsonAttributes returns a copy of the attributes with durations as strings
+func jsonAttributes(attributes map[string]any) map[string]any {
+	if attributes == nil {
+		return nil
+	}
+
+	encoded := make(map[string]any, len(attributes))
+	for key, value := range attributes {
+		if d, ok := value.(time.Duration); ok {
+			value = d.String()
+		}
+
+		encoded[key] = value
+	}
+
+	return encoded
+}
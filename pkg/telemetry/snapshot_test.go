@@ -0,0 +1,90 @@
+package telemetry_test
+
+import (
+	"testing"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/telemetrytest"
+)
+
+func TestSnapshotRetention(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		tel, _ := telemetrytest.NewTelemetry(t)
+		tel.SetSnapshotEnabled(enabled)
+		transaction := start(t, tel, "snapshot")
+
+		segmentID := transaction.SegmentStart("segment")
+		transaction.AddSegmentAttribute(segmentID, "rows", 3)
+		err := transaction.AddSegmentEvent(segmentID, "retry", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg := "message"
+		transaction.Info(segmentID, &msg)
+		transaction.Info("", &msg)
+		transaction.SegmentEnd(segmentID)
+
+		_, found := transaction.GetSegmentAttribute(segmentID, "rows")
+
+		err = transaction.Done()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		snapshot := transaction.Snapshot()
+		retained := []bool{
+			found,
+			len(snapshot.Logs) == 1,
+			len(snapshot.Segments) == 1,
+		}
+
+		if enabled {
+			segment := snapshot.Segments[0]
+			retained = append(retained, len(segment.Logs) == 1, len(segment.Events) == 1, segment.Attributes["rows"] == 3)
+		}
+
+		for i, ok := range retained {
+			if ok != enabled {
+				t.Errorf("enabled %t: expected retention %d to be %t", enabled, i, enabled)
+			}
+		}
+	}
+}
+
+func TestEndedSegmentsAreReleased(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		tel, _ := telemetrytest.NewTelemetry(t)
+		tel.SetSnapshotEnabled(enabled)
+		transaction := start(t, tel, "release")
+
+		parentID := transaction.SegmentStart("parent")
+		childID, err := transaction.SegmentStartChild(parentID, "child")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		transaction.SegmentEnd(parentID)
+
+		_, err = transaction.SegmentDuration(parentID)
+		if err != nil {
+			t.Fatalf("enabled %t: expected the ended parent with an open child to be kept, got %v", enabled, err)
+		}
+
+		transaction.SegmentEnd(childID)
+
+		for _, segmentID := range []string{parentID, childID} {
+			_, err = transaction.SegmentDuration(segmentID)
+			if (err == nil) != enabled {
+				t.Errorf("enabled %t: expected segment %s to be retained %t, got %v", enabled, segmentID, enabled, err)
+			}
+		}
+
+		if open := transaction.OpenSegments(); len(open) != 0 {
+			t.Errorf("enabled %t: expected no open segments, got %v", enabled, open)
+		}
+
+		err = transaction.Done()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
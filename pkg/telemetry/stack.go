@@ -0,0 +1,91 @@
+package telemetry
+
+import (
+	"log"
+	"sync"
+)
+
+// segmentStack holds the ids of the segments started with BeginSegment, the most recent one last
+type segmentStack struct {
+	mu  sync.Mutex
+	ids []string
+}
+
+// push adds the segment id on top of the stack
+func (ss *segmentStack) push(segmentID string) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	ss.ids = append(ss.ids, segmentID)
+}
+
+// top returns the most recent segment id
+func (ss *segmentStack) top() (string, bool) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	if len(ss.ids) == 0 {
+		return "", false
+	}
+
+	return ss.ids[len(ss.ids)-1], true
+}
+
+// pop removes and returns the most recent segment id
+func (ss *segmentStack) pop() (string, bool) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	if len(ss.ids) == 0 {
+		return "", false
+	}
+
+	segmentID := ss.ids[len(ss.ids)-1]
+	ss.ids = ss.ids[:len(ss.ids)-1]
+
+	return segmentID, true
+}
+
+// clear removes all segment ids
+func (ss *segmentStack) clear() {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	ss.ids = nil
+}
+
+// BeginSegment starts a segment and pushes it on the segment stack of the container.
+// If another segment begun with BeginSegment is still open, the new segment is started as its child.
+// The segment id is returned for the ID based methods, it is not needed to end the segment with EndSegment
+func (tc *TransactionContainer) BeginSegment(name string) string {
+	var (
+		segmentID string
+		err       error
+	)
+
+	parentSegmentID, ok := tc.stack.top()
+	if ok {
+		segmentID, err = tc.SegmentStartChild(parentSegmentID, name)
+	} else {
+		segmentID, err = tc.SegmentStartE(name)
+	}
+	if err != nil {
+		log.Print(err)
+	}
+
+	tc.stack.push(segmentID)
+
+	return segmentID
+}
+
+// EndSegment pops the most recent segment of the segment stack and ends it.
+// Calling EndSegment on an empty stack is logged and otherwise ignored
+func (tc *TransactionContainer) EndSegment() {
+	segmentID, ok := tc.stack.pop()
+	if !ok {
+		log.Print("telemetry Function: EndSegment | Error: no segment begun")
+		return
+	}
+
+	tc.SegmentEnd(segmentID)
+}
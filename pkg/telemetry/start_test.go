@@ -0,0 +1,59 @@
+package telemetry_test
+
+import (
+	"testing"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/telemetrytest"
+)
+
+func TestStartWithAttributesAddsEachAttributeOnce(t *testing.T) {
+	tel, recorders := newRecordingTelemetry(t, "first", "second")
+	attributes := map[string]any{"tenant": "acme", "plan": "pro", "seats": 12}
+
+	transaction, err := tel.StartWithAttributes("attributes", attributes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, recorder := range recorders {
+		counts := make(map[string]int)
+		for _, attribute := range recorder.TransactionAttributes() {
+			counts[attribute.Key]++
+		}
+
+		for key, value := range attributes {
+			if counts[key] != 1 {
+				t.Errorf("driver %d: expected %s once, got %d times", i, key, counts[key])
+			}
+
+			recorder.AssertAttribute(t, key, value)
+		}
+	}
+
+	value, ok := transaction.GetTransactionAttribute("tenant")
+	if !ok || value != "acme" {
+		t.Errorf("expected tenant acme, got %v", value)
+	}
+}
+
+func TestWithAttributesMerges(t *testing.T) {
+	tel, recorder := telemetrytest.NewTelemetry(t)
+
+	_, err := tel.Start("attributes",
+		telemetry.WithAttributes(map[string]any{"tenant": "acme", "plan": "free"}),
+		telemetry.WithAttributes(map[string]any{"plan": "pro"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder.AssertAttribute(t, "tenant", "acme")
+	recorder.AssertAttribute(t, "plan", "pro")
+
+	for _, attribute := range recorder.TransactionAttributes() {
+		if attribute.Key == "plan" && attribute.Value != "pro" {
+			t.Errorf("expected only the merged plan, got %v", attribute.Value)
+		}
+	}
+}
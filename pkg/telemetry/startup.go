@@ -0,0 +1,172 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+)
+
+// DefaultStartupLogSize is the default maximum number of buffered startup log messages
+const DefaultStartupLogSize = 100
+
+// Fields of the startup log messages attached to a transaction
+const (
+	StartupMessageField = "startup_message"
+	StartupTimeField    = "startup_time"
+)
+
+// Info logs an info message of the startup phase with the default instance
+func Info(msg string) {
+	defaultTelemetry.Info(msg)
+}
+
+// Error logs an error of the startup phase with the default instance
+func Error(err error) {
+	defaultTelemetry.Error(err)
+}
+
+// SetStartupLogSize sets the startup log buffer size of the default instance
+func SetStartupLogSize(n int) {
+	defaultTelemetry.SetStartupLogSize(n)
+}
+
+// FlushStartupLogs writes the buffered startup log messages of the default instance to w
+func FlushStartupLogs(w io.Writer) error {
+	return defaultTelemetry.FlushStartupLogs(w)
+}
+
+// Info logs an info message without a transaction, e.g. while loading the configuration before the first Start.
+// The message is buffered and attached to the next started and sampled transaction as info with the
+// StartupMessageField and StartupTimeField fields. Messages below the log level are discarded
+func (t *Telemetry) Info(msg string) {
+	t.bufferStartupLog(LevelInfo, msg)
+}
+
+// Error logs an error without a transaction, e.g. while loading the configuration before the first Start.
+// The error is buffered and attached to the next started and sampled transaction as error with the
+// StartupTimeField field. Nil errors and errors below the log level are discarded
+func (t *Telemetry) Error(err error) {
+	if err == nil {
+		return
+	}
+
+	t.bufferStartupLog(LevelError, err.Error())
+}
+
+// SetStartupLogSize sets the maximum number of buffered startup log messages, DefaultStartupLogSize by default.
+// If the buffer is full the oldest message is dropped, the number of dropped messages is logged as warning
+// with the buffered messages. A size below 1 disables the buffering, Info and Error discard all messages then
+func (t *Telemetry) SetStartupLogSize(n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.startupLogSize = n
+	if n < 1 {
+		t.startupLogs = nil
+		return
+	}
+
+	if len(t.startupLogs) > n {
+		t.startupLogsDropped += len(t.startupLogs) - n
+		t.startupLogs = t.startupLogs[len(t.startupLogs)-n:]
+	}
+}
+
+// FlushStartupLogs writes the buffered startup log messages as JSON lines to w and clears the buffer.
+// Use it to keep the messages if the application exits before a transaction is started, e.g. FlushStartupLogs(os.Stdout)
+func (t *Telemetry) FlushStartupLogs(w io.Writer) error {
+	logs, dropped := t.takeStartupLogs()
+	if dropped > 0 {
+		logs = append(logs, droppedStartupLogs(dropped, t.now()))
+	}
+
+	encoder := json.NewEncoder(w)
+	for _, entry := range logs {
+		err := encoder.Encode(entry)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// bufferStartupLog appends a log message to the startup buffer and drops the oldest message if it is full
+func (t *Telemetry) bufferStartupLog(level Level, msg string) {
+	if !t.logEnabled(level) {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.startupLogSize < 1 {
+		return
+	}
+
+	if len(t.startupLogs) >= t.startupLogSize {
+		t.startupLogs = t.startupLogs[1:]
+		t.startupLogsDropped++
+	}
+
+	t.startupLogs = append(t.startupLogs, LogSnapshot{
+		Level:   level.String(),
+		Message: msg,
+		Time:    clockTime(t.clock),
+	})
+}
+
+// takeStartupLogs returns and clears the buffered startup log messages and the number of dropped messages
+func (t *Telemetry) takeStartupLogs() ([]LogSnapshot, int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	logs, dropped := t.startupLogs, t.startupLogsDropped
+	t.startupLogs, t.startupLogsDropped = nil, 0
+
+	return logs, dropped
+}
+
+// attachStartupLogs logs the buffered startup log messages in the transaction.
+// The messages stay buffered for the next transaction if tc is not sampled
+func (t *Telemetry) attachStartupLogs(tc *TransactionContainer) {
+	if !tc.sampled {
+		return
+	}
+
+	logs, dropped := t.takeStartupLogs()
+	if dropped > 0 {
+		msg := droppedStartupLogs(dropped, t.now()).Message
+		tc.Warn("", &msg)
+	}
+
+	for _, entry := range logs {
+		fields := map[string]any{
+			StartupTimeField: entry.Time.Format(time.RFC3339Nano),
+		}
+
+		var err error
+		if entry.Level == LevelError.String() {
+			err = tc.ErrorFields("", errors.New(entry.Message), fields)
+		} else {
+			fields[StartupMessageField] = entry.Message
+			err = tc.InfoFields("", fields)
+		}
+
+		if err != nil {
+			log.Print(err)
+		}
+	}
+}
+
+// droppedStartupLogs returns the warning about dropped startup log messages at now
+func droppedStartupLogs(dropped int, now time.Time) LogSnapshot {
+	return LogSnapshot{
+		Level:   LevelWarn.String(),
+		Message: fmt.Sprintf("%d startup log messages dropped, the buffer was full", dropped),
+		Time:    now,
+	}
+}
@@ -0,0 +1,112 @@
+package telemetry
+
+import (
+	"log"
+	"sync/atomic"
+)
+
+// TelemetryStats holds counters about the telemetry layer itself, e.g. to export them as metrics.
+// All counters are totals since the telemetry instance was created
+type TelemetryStats struct {
+	// TransactionsStarted is the number of started transactions
+	TransactionsStarted uint64
+	// TransactionsSampledOut is the number of started transactions which were not sampled
+	TransactionsSampledOut uint64
+	// SegmentsStarted is the number of started segments
+	SegmentsStarted uint64
+	// SegmentsDropped is the number of segments dropped for ending faster than the minimum segment duration
+	SegmentsDropped uint64
+	// SegmentsLeaked is the number of segments which were still open on Done
+	SegmentsLeaked uint64
+	// AttributesDropped is the number of attributes dropped for an unsupported type or an exceeded limit
+	AttributesDropped uint64
+	// LogsTruncated is the number of log messages shortened to the configured payload size
+	LogsTruncated uint64
+	// LogsSuppressed is the number of log messages dropped by the log rate limit
+	LogsSuppressed uint64
+	// DriverErrors is the number of errors returned by driver methods
+	DriverErrors uint64
+	// DriverOperationsDropped is the number of operations dropped by registered drivers with a full buffer, e.g. AsyncDriver
+	DriverOperationsDropped uint64
+}
+
+// droppedCounter is implemented by drivers which drop operations, e.g. AsyncDriver
+type droppedCounter interface {
+	Dropped() uint64
+}
+
+// counters holds the atomic counters behind TelemetryStats
+type counters struct {
+	transactionsStarted    atomic.Uint64
+	transactionsSampledOut atomic.Uint64
+	segmentsStarted        atomic.Uint64
+	segmentsDropped        atomic.Uint64
+	segmentsLeaked         atomic.Uint64
+	attributesDropped      atomic.Uint64
+	logsTruncated          atomic.Uint64
+	logsSuppressed         atomic.Uint64
+	driverErrors           atomic.Uint64
+}
+
+// Stats returns the counters of the default instance
+func Stats() TelemetryStats {
+	return defaultTelemetry.Stats()
+}
+
+// Stats returns the counters about dropped and failed operations of the telemetry layer.
+// The counters are read individually, so a snapshot taken during concurrent operations may be slightly inconsistent
+func (t *Telemetry) Stats() TelemetryStats {
+	return TelemetryStats{
+		TransactionsStarted:     t.counters.transactionsStarted.Load(),
+		TransactionsSampledOut:  t.counters.transactionsSampledOut.Load(),
+		SegmentsStarted:         t.counters.segmentsStarted.Load(),
+		SegmentsDropped:         t.counters.segmentsDropped.Load(),
+		SegmentsLeaked:          t.counters.segmentsLeaked.Load(),
+		AttributesDropped:       t.counters.attributesDropped.Load(),
+		LogsTruncated:           t.counters.logsTruncated.Load(),
+		LogsSuppressed:          t.counters.logsSuppressed.Load(),
+		DriverErrors:            t.counters.driverErrors.Load(),
+		DriverOperationsDropped: t.droppedOperations(),
+	}
+}
+
+// droppedOperations returns the sum of the operations dropped by the registered drivers
+func (t *Telemetry) droppedOperations() uint64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var dropped uint64
+	for _, driver := range t.registeredDriver {
+		if dc, ok := driver.(droppedCounter); ok {
+			dropped += dc.Dropped()
+		}
+	}
+
+	return dropped
+}
+
+// countDroppedAttributes adds the attributes missing in kept to the dropped attributes
+func (t *Telemetry) countDroppedAttributes(attributes map[string]any, kept map[string]any) {
+	var dropped uint64
+	for key := range attributes {
+		if _, ok := kept[key]; !ok {
+			dropped++
+		}
+	}
+
+	if dropped > 0 {
+		t.counters.attributesDropped.Add(dropped)
+	}
+}
+
+// driverError counts the error of a driver method and returns it wrapped
+func (tc *TransactionContainer) driverError(driverName string, function string, err error) ErrDriverMethod {
+	tc.telemetry.counters.driverErrors.Add(1)
+
+	return ErrDriverMethod{Driver: driverName, Function: function, Err: err}
+}
+
+// logDriverError counts and logs the error of a driver method which is not returned to the caller
+func (tc *TransactionContainer) logDriverError(driverName string, function string, err error) {
+	log.Print(tc.driverError(driverName, function, err))
+}
@@ -0,0 +1,83 @@
+package telemetry
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TransactionErrorAttribute is the transaction attribute holding the error message passed to SetError
+const TransactionErrorAttribute = "error.message"
+
+// TransactionStatus is the outcome of a transaction as a whole
+type TransactionStatus int
+
+// Available transaction statuses
+const (
+	TransactionStatusOK TransactionStatus = iota
+	TransactionStatusError
+)
+
+// String returns the name of the status
+func (s TransactionStatus) String() string {
+	switch s {
+	case TransactionStatusOK:
+		return "ok"
+	case TransactionStatusError:
+		return "error"
+	}
+
+	return fmt.Sprintf("status(%d)", int(s))
+}
+
+// ParseTransactionStatus returns the status for the provided name (ok or error)
+func ParseTransactionStatus(name string) (TransactionStatus, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "ok":
+		return TransactionStatusOK, nil
+	case "error":
+		return TransactionStatusError, nil
+	}
+
+	return TransactionStatusOK, fmt.Errorf("unknown telemetry transaction status %q", name)
+}
+
+// SetStatus sets the outcome of the transaction in the registered driver transactions, e.g. an error for a request
+// rejected by the business logic although all its segments succeeded. The last status set before Done is kept
+func (tc *TransactionContainer) SetStatus(status TransactionStatus) error {
+	var ew ErrorWrapper
+
+	tc.record.setStatus(status)
+
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+
+	for _, driverName := range tc.driverOrder() {
+		transaction := tc.transactions[driverName]
+		err := safeCall(func() error { return transaction.SetStatus(status) })
+		if err != nil {
+			ew.Add(tc.driverError(driverName, "SetStatus", err))
+		}
+	}
+
+	return ew.Error()
+}
+
+// SetError marks the transaction as failed with TransactionStatusError and adds the error message
+// as TransactionErrorAttribute. A nil error does nothing
+func (tc *TransactionContainer) SetError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	tc.AddTransactionAttribute(TransactionErrorAttribute, err.Error())
+
+	return tc.SetStatus(TransactionStatusError)
+}
+
+// setStatus retains the transaction status
+func (tr *transactionRecord) setStatus(status TransactionStatus) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	tr.status = &status
+}
@@ -0,0 +1,468 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// StdoutDriverName is the name the stdout driver is registered with
+const StdoutDriverName = "stdout"
+
+// stdoutDriver writes every telemetry event as a JSON object to a writer
+type stdoutDriver struct {
+	mu *sync.Mutex
+	w  io.Writer
+}
+
+// stdoutTransaction is the transaction of the stdout driver
+type stdoutTransaction struct {
+	driver    stdoutDriver
+	mu        sync.Mutex
+	name      string
+	start     time.Time
+	trace     string
+	traceID   string
+	processID string
+	status    *TransactionStatus
+	segments  map[string]stdoutSegment
+}
+
+// stdoutSegment holds the data of a started segment
+type stdoutSegment struct {
+	name  string
+	start time.Time
+}
+
+// stdoutEvent is the JSON object written for every event
+type stdoutEvent struct {
+	Time        time.Time `json:"time"`
+	Event       string    `json:"event"`
+	Transaction string    `json:"transaction"`
+	ProcessID   string    `json:"processID,omitempty"`
+	TraceID     string    `json:"traceID,omitempty"`
+	SegmentID   string    `json:"segmentID,omitempty"`
+	ParentID    string    `json:"parentID,omitempty"`
+	Segment     string    `json:"segment,omitempty"`
+	Key         string    `json:"key,omitempty"`
+	Value       any       `json:"value,omitempty"`
+	Message     string    `json:"message,omitempty"`
+	Duration    string    `json:"duration,omitempty"`
+	Status      string    `json:"status,omitempty"`
+	Link        string    `json:"link,omitempty"`
+	ContentType string    `json:"contentType,omitempty"`
+	Encoding    string    `json:"contentEncoding,omitempty"`
+	Truncated   bool      `json:"truncated,omitempty"`
+}
+
+// NewStdoutDriver returns a driver which writes every transaction, segment, attribute and log line
+// as a JSON object to w. If w is nil os.Stdout is used
+func NewStdoutDriver(w io.Writer) Driver {
+	if w == nil {
+		w = os.Stdout
+	}
+
+	return stdoutDriver{
+		mu: &sync.Mutex{},
+		w:  w,
+	}
+}
+
+// InitializeTransaction returns a transaction which writes to the writer of the driver
+func (d stdoutDriver) InitializeTransaction(name string) (Transaction, error) {
+	return &stdoutTransaction{
+		driver:   d,
+		name:     name,
+		segments: make(map[string]stdoutSegment),
+	}, nil
+}
+
+// write encodes the event and writes it to the driver writer
+func (d stdoutDriver) write(event stdoutEvent) error {
+	event.Time = time.Now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return json.NewEncoder(d.w).Encode(event)
+}
+
+// flush flushes the driver writer if it is buffered
+func (d stdoutDriver) flush() error {
+	flusher, ok := d.w.(interface{ Flush() error })
+	if !ok {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return flusher.Flush()
+}
+
+// event returns a new event prefilled with the transaction data
+func (t *stdoutTransaction) event(name string) stdoutEvent {
+	return stdoutEvent{
+		Event:       name,
+		Transaction: t.name,
+		ProcessID:   t.processID,
+		TraceID:     t.traceID,
+	}
+}
+
+// Start writes the start of the transaction
+func (t *stdoutTransaction) Start(name string) {
+	t.mu.Lock()
+	t.start = time.Now()
+	event := t.event("transactionStart")
+	t.mu.Unlock()
+
+	err := t.driver.write(event)
+	if err != nil {
+		log.Printf("%s%s Function: Start | Error: %v", TelemetryDriverError, StdoutDriverName, err)
+	}
+}
+
+// SetName renames the transaction and writes the rename with the previous name as value
+func (t *stdoutTransaction) SetName(name string) error {
+	t.mu.Lock()
+	previous := t.name
+	t.name = name
+	event := t.event("transactionRename")
+	t.mu.Unlock()
+
+	event.Value = previous
+
+	return t.driver.write(event)
+}
+
+// AddTransactionAttribute writes the transaction attribute
+func (t *stdoutTransaction) AddTransactionAttribute(key string, value any) error {
+	t.mu.Lock()
+	event := t.event("transactionAttribute")
+	t.mu.Unlock()
+
+	event.Key = key
+	event.Value = value
+
+	return t.driver.write(event)
+}
+
+// SegmentStart writes the start of the segment
+func (t *stdoutTransaction) SegmentStart(segmentID string, name string) error {
+	t.mu.Lock()
+	t.segments[segmentID] = stdoutSegment{
+		name:  name,
+		start: time.Now(),
+	}
+	event := t.event("segmentStart")
+	t.mu.Unlock()
+
+	event.SegmentID = segmentID
+	event.Segment = name
+
+	return t.driver.write(event)
+}
+
+// SegmentStartChild writes the start of the segment with its parent segment
+func (t *stdoutTransaction) SegmentStartChild(parentID string, segmentID string, name string) error {
+	t.mu.Lock()
+	t.segments[segmentID] = stdoutSegment{
+		name:  name,
+		start: time.Now(),
+	}
+	event := t.event("segmentStart")
+	t.mu.Unlock()
+
+	event.SegmentID = segmentID
+	event.ParentID = parentID
+	event.Segment = name
+
+	return t.driver.write(event)
+}
+
+// AddSegmentAttribute writes the segment attribute
+func (t *stdoutTransaction) AddSegmentAttribute(segmentID string, key string, value any) error {
+	t.mu.Lock()
+	segment, ok := t.segments[segmentID]
+	event := t.event("segmentAttribute")
+	t.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("segment %s not found", segmentID)
+	}
+
+	event.SegmentID = segmentID
+	event.Segment = segment.name
+	event.Key = key
+	event.Value = value
+
+	return t.driver.write(event)
+}
+
+// AddSegmentAttributes writes all segment attributes as one event
+func (t *stdoutTransaction) AddSegmentAttributes(segmentID string, attributes map[string]any) error {
+	t.mu.Lock()
+	segment, ok := t.segments[segmentID]
+	event := t.event("segmentAttributes")
+	t.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("segment %s not found", segmentID)
+	}
+
+	event.SegmentID = segmentID
+	event.Segment = segment.name
+	event.Value = attributes
+
+	return t.driver.write(event)
+}
+
+// AddSegmentEvent writes the event with its attributes as value
+func (t *stdoutTransaction) AddSegmentEvent(segmentID string, name string, attributes map[string]any) error {
+	t.mu.Lock()
+	segment, ok := t.segments[segmentID]
+	event := t.event("segmentEvent")
+	t.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("segment %s not found", segmentID)
+	}
+
+	event.SegmentID = segmentID
+	event.Segment = segment.name
+	event.Key = name
+	event.Value = attributes
+
+	return t.driver.write(event)
+}
+
+// SegmentEnd writes the end of the segment with the elapsed duration
+func (t *stdoutTransaction) SegmentEnd(segmentID string) error {
+	return t.SegmentEndWithStatus(segmentID, StatusOK)
+}
+
+// SegmentEndWithStatus writes the end of the segment with the elapsed duration and the status
+func (t *stdoutTransaction) SegmentEndWithStatus(segmentID string, status SegmentStatus) error {
+	t.mu.Lock()
+	segment, ok := t.segments[segmentID]
+	delete(t.segments, segmentID)
+	event := t.event("segmentEnd")
+	t.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("segment %s not found", segmentID)
+	}
+
+	event.SegmentID = segmentID
+	event.Segment = segment.name
+	event.Duration = time.Since(segment.start).String()
+	event.Status = status.String()
+
+	return t.driver.write(event)
+}
+
+// AddLink writes the linked trace with its attributes
+func (t *stdoutTransaction) AddLink(trace string, attributes map[string]any) error {
+	t.mu.Lock()
+	event := t.event("transactionLink")
+	t.mu.Unlock()
+
+	event.Link = trace
+	if len(attributes) > 0 {
+		event.Value = attributes
+	}
+
+	return t.driver.write(event)
+}
+
+// Flush flushes the writer of the driver if it supports it
+func (t *stdoutTransaction) Flush() error {
+	return t.driver.flush()
+}
+
+// AttachPayload writes the payload with its content type and encoding. Valid UTF-8 payloads are written as text,
+// other and compressed payloads are base64 encoded
+func (t *stdoutTransaction) AttachPayload(segmentID string, payload Payload) error {
+	t.mu.Lock()
+	event := t.event("payload")
+	t.mu.Unlock()
+
+	event.SegmentID = segmentID
+	event.Key = payload.Name
+	event.ContentType = payload.ContentType
+	event.Truncated = payload.Truncated
+	event.Encoding = payload.ContentEncoding
+	event.Value = payload.Data
+	if payload.ContentEncoding == "" && utf8.Valid(payload.Data) {
+		event.Value = string(payload.Data)
+	}
+
+	return t.driver.write(event)
+}
+
+// SetStatus writes the transaction status, which is repeated on the end of the transaction
+func (t *stdoutTransaction) SetStatus(status TransactionStatus) error {
+	t.mu.Lock()
+	t.status = &status
+	event := t.event("transactionStatus")
+	t.mu.Unlock()
+
+	event.Status = status.String()
+
+	return t.driver.write(event)
+}
+
+// Done writes the end of the transaction with the elapsed duration
+func (t *stdoutTransaction) Done() error {
+	t.mu.Lock()
+	event := t.event("transactionEnd")
+	event.Duration = time.Since(t.start).String()
+	if t.status != nil {
+		event.Status = t.status.String()
+	}
+	t.mu.Unlock()
+
+	return t.driver.write(event)
+}
+
+// Info writes the info message
+func (t *stdoutTransaction) Info(segmentID string, rc io.ReadCloser) error {
+	return t.log("info", segmentID, rc)
+}
+
+// Warn writes the warning message
+func (t *stdoutTransaction) Warn(segmentID string, rc io.ReadCloser) error {
+	return t.log("warn", segmentID, rc)
+}
+
+// Error writes the error message
+func (t *stdoutTransaction) Error(segmentID string, rc io.ReadCloser) error {
+	return t.log("error", segmentID, rc)
+}
+
+// Debug writes the debug message
+func (t *stdoutTransaction) Debug(segmentID string, rc io.ReadCloser) error {
+	return t.log("debug", segmentID, rc)
+}
+
+// InfoFields writes the info fields
+func (t *stdoutTransaction) InfoFields(segmentID string, fields map[string]any) error {
+	return t.logFields("info", segmentID, fields)
+}
+
+// ErrorFields writes the error fields
+func (t *stdoutTransaction) ErrorFields(segmentID string, fields map[string]any) error {
+	return t.logFields("error", segmentID, fields)
+}
+
+// logFields writes the fields with the given level
+func (t *stdoutTransaction) logFields(level string, segmentID string, fields map[string]any) error {
+	t.mu.Lock()
+	event := t.event(level)
+	t.mu.Unlock()
+
+	event.SegmentID = segmentID
+	event.Value = fields
+
+	return t.driver.write(event)
+}
+
+// log drains the message, already limited by the container, and writes it with the given level
+func (t *stdoutTransaction) log(level string, segmentID string, rc io.ReadCloser) error {
+	defer rc.Close()
+
+	msg, err := io.ReadAll(rc)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	event := t.event(level)
+	t.mu.Unlock()
+
+	event.SegmentID = segmentID
+	event.Message = string(msg)
+
+	return t.driver.write(event)
+}
+
+// CreateTrace creates a new random trace
+func (t *stdoutTransaction) CreateTrace() (string, error) {
+	return uuid.NewString(), nil
+}
+
+// SetTrace sets the trace and uses it as trace id
+func (t *stdoutTransaction) SetTrace(trace string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.trace = trace
+	t.traceID = trace
+
+	return nil
+}
+
+// Trace returns the trace
+func (t *stdoutTransaction) Trace() (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.trace, nil
+}
+
+// TraceID returns the trace id
+func (t *stdoutTransaction) TraceID() (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.traceID, nil
+}
+
+// SetTraceID sets the trace id
+func (t *stdoutTransaction) SetTraceID(traceID string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.traceID = traceID
+
+	return nil
+}
+
+// Erase removes all segments of the transaction
+func (t *stdoutTransaction) Erase() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	clear(t.segments)
+}
+
+// CreateProcessID creates a new random process id
+func (t *stdoutTransaction) CreateProcessID() (string, error) {
+	return uuid.NewString(), nil
+}
+
+// SetProcessID sets the process id
+func (t *stdoutTransaction) SetProcessID(processID string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.processID = processID
+
+	return nil
+}
+
+// ProcessID returns the process id
+func (t *stdoutTransaction) ProcessID() (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.processID, nil
+}
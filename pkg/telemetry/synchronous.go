@@ -0,0 +1,19 @@
+package telemetry
+
+import "sync/atomic"
+
+// synchronous forces the built-in async wrappers and batch processors to apply their operations on the caller
+var synchronous atomic.Bool
+
+// SetSynchronous makes the AsyncDriver, the TimeoutDriver and the batch span processor of the oteldriver
+// apply every operation on the calling goroutine before the call returns, so tests can assert the driver state
+// without sleeping or polling. The TimeoutDriver no longer enforces its timeout in this mode.
+// It is meant for tests only, production code must not enable it. Default false
+func SetSynchronous(enabled bool) {
+	synchronous.Store(enabled)
+}
+
+// Synchronous reports whether the synchronous mode is enabled, see SetSynchronous
+func Synchronous() bool {
+	return synchronous.Load()
+}
@@ -1,13 +1,17 @@
 package telemetry
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"log"
+	"maps"
+	"os"
+	"sort"
 	"strings"
-
-	"github.com/google/uuid"
+	"sync"
+	"time"
 )
 
 // ErrorBytesSize is used for the default error size
@@ -42,10 +46,18 @@ type Transaction interface {
 	Allocator
 	Processor
 	Start(string)
+	SetName(string) error
 	AddTransactionAttribute(string, any) error
 	SegmentStart(string, string) error
+	SegmentStartChild(string, string, string) error
 	AddSegmentAttribute(string, string, any) error
+	AddSegmentAttributes(string, map[string]any) error
+	AddSegmentEvent(string, string, map[string]any) error
 	SegmentEnd(string) error
+	SegmentEndWithStatus(string, SegmentStatus) error
+	AddLink(string, map[string]any) error
+	SetStatus(TransactionStatus) error
+	Flush() error
 	Done() error
 }
 
@@ -61,8 +73,11 @@ type Tracer interface {
 // Logger ...
 type Logger interface {
 	Info(string, io.ReadCloser) error
+	Warn(string, io.ReadCloser) error
 	Error(string, io.ReadCloser) error
 	Debug(string, io.ReadCloser) error
+	InfoFields(string, map[string]any) error
+	ErrorFields(string, map[string]any) error
 }
 
 // Allocator ...
@@ -82,63 +97,386 @@ type ErrorWrapper struct {
 	errors []error
 }
 
-// registeredDriver holds all available driver
-var registeredDriver map[string]Driver
+// Telemetry holds an independent telemetry configuration.
+// The package level functions use a default instance
+type Telemetry struct {
+	mu sync.RWMutex
+	// registeredDriver holds all available driver
+	registeredDriver map[string]Driver
+	// driverFactories create the configured drivers which are not registered, see ConfigureFromConfig
+	driverFactories map[string]DriverFactory
+	// loadedDriver is a list of drivers to use for the application
+	loadedDriver []string
+	// disabledDrivers are loaded drivers which are skipped at Start
+	disabledDrivers map[string]struct{}
+	// traceDrivers are the drivers used for the trace, in the order they are tried
+	traceDrivers []string
+	// logLevel is the minimum level a message needs to be passed to the drivers
+	logLevel Level
+	// sampler decides whether a transaction is recorded
+	sampler Sampler
+	// samplerSet reports whether SetSampler was called, see Validate
+	samplerSet bool
+	// redactor scrubs attributes and log messages before they are passed to the drivers
+	redactor Redactor
+	// errorBytesSize is the maximum bytes of an error payload
+	errorBytesSize int
+	// infoBytesSize is the maximum bytes of an info, warn and debug payload
+	infoBytesSize int
+	// idGenerator creates the segment ids
+	idGenerator IDGenerator
+	// strict makes Start fail on a missing driver configuration
+	strict bool
+	// maxSegmentAttributes is the maximum number of attributes per segment
+	maxSegmentAttributes int
+	// maxTransactionAttributes is the maximum number of attributes per transaction
+	maxTransactionAttributes int
+	// initRetryAttempts is the number of retries of a failing driver initialization
+	initRetryAttempts int
+	// initRetryBackoff is the wait before the first retry, doubled with every further retry
+	initRetryBackoff time.Duration
+	// startupLogs are the log messages logged before a transaction was started
+	startupLogs []LogSnapshot
+	// startupLogSize is the maximum number of buffered startup log messages
+	startupLogSize int
+	// startupLogsDropped is the number of startup log messages dropped since the buffer was full
+	startupLogsDropped int
+	// processIDFormatter converts the raw process id into the displayed one
+	processIDFormatter ProcessIDFormatter
+	// processIDParser converts the displayed process id back into the raw one
+	processIDParser ProcessIDParser
+	// minSegmentDuration is the duration below which segments ending with StatusOK are dropped
+	minSegmentDuration time.Duration
+	// traceValidator checks the format of traces passed to SetTrace
+	traceValidator TraceValidator
+	// captureCaller makes the log methods add the caller as log fields
+	captureCaller bool
+	// resourceAttributes are added to every started transaction
+	resourceAttributes map[string]any
+	// resourceAttributesErr holds the resource attributes rejected by SetResourceAttributes, see Validate
+	resourceAttributesErr error
+	// inheritTransactionAttributes makes new segments start with the transaction attributes
+	inheritTransactionAttributes bool
+	// logRateLimit is the maximum number of Info, Warn and Error messages per second and transaction
+	logRateLimit int
+	// clock returns the current time, time.Now if nil
+	clock func() time.Time
+	// attributeMergePolicy decides how segment attributes set again are merged
+	attributeMergePolicy AttributeMergePolicy
+	// logCompression is the compression of payloads handed to the drivers
+	logCompression LogCompression
+	// compressionThreshold is the size in bytes above which payloads are compressed
+	compressionThreshold int
+	// logPrefix is the template prepended to every log message, see SetLogPrefix
+	logPrefix string
+	// captureRuntimeStats adds the runtime stats as transaction attributes on start and Done
+	captureRuntimeStats bool
+	// snapshotEnabled retains the log lines, events and attributes of ended segments for Snapshot
+	snapshotEnabled bool
+	// counters count the operations of the telemetry layer itself, see Stats
+	counters counters
+}
+
+// defaultTelemetry is the instance used by the package level functions
+var defaultTelemetry = New()
+
+// New returns a telemetry instance with the built-in drivers registered
+func New() *Telemetry {
+	t := &Telemetry{
+		logLevel:                 LevelDebug,
+		errorBytesSize:           ErrorBytesSize,
+		infoBytesSize:            DebugByteSize,
+		maxSegmentAttributes:     DefaultMaxAttributes,
+		maxTransactionAttributes: DefaultMaxAttributes,
+		startupLogSize:           DefaultStartupLogSize,
+	}
+
+	t.MustRegisterDriver(NoopDriverName, NoopDriver{})
+	t.MustRegisterDriver(StdoutDriverName, NewStdoutDriver(os.Stdout))
+
+	return t
+}
+
+// Default returns the instance used by the package level functions
+func Default() *Telemetry {
+	return defaultTelemetry
+}
+
+// RegisterDriver adds the possibility to add a driver to the driver map of the default instance
+func RegisterDriver(name string, driver Driver) error {
+	return defaultTelemetry.RegisterDriver(name, driver)
+}
+
+// MustRegisterDriver adds a driver to the driver map of the default instance and panics if the name is already registered
+func MustRegisterDriver(name string, driver Driver) {
+	defaultTelemetry.MustRegisterDriver(name, driver)
+}
+
+// RegisteredDrivers returns the sorted names of all drivers registered in the default instance
+func RegisteredDrivers() []string {
+	return defaultTelemetry.RegisteredDrivers()
+}
+
+// SetDriver sets the drivers of the default instance
+func SetDriver(name ...string) {
+	defaultTelemetry.SetDriver(name...)
+}
+
+// EnableDriver enables a previously disabled driver of the default instance
+func EnableDriver(name string) {
+	defaultTelemetry.EnableDriver(name)
+}
+
+// DisableDriver disables a driver of the default instance
+func DisableDriver(name string) {
+	defaultTelemetry.DisableDriver(name)
+}
+
+// ActiveDrivers returns the drivers of the default instance which are activated at Start
+func ActiveDrivers() []string {
+	return defaultTelemetry.ActiveDrivers()
+}
+
+// SetStrict sets the strict mode of the default instance
+func SetStrict(strict bool) {
+	defaultTelemetry.SetStrict(strict)
+}
+
+// SetTraceDriver sets a single driver used for the trace of the default instance
+func SetTraceDriver(name string) {
+	defaultTelemetry.SetTraceDriver(name)
+}
+
+// SetTraceDrivers sets the drivers used for the trace of the default instance
+func SetTraceDrivers(names ...string) {
+	defaultTelemetry.SetTraceDrivers(names...)
+}
+
+// RegisterDriver adds the possibility to add a driver to the driver map.
+// It returns an error if a driver with the same name is already registered
+func (t *Telemetry) RegisterDriver(name string, driver Driver) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.registeredDriver == nil {
+		t.registeredDriver = make(map[string]Driver)
+	}
+
+	if _, ok := t.registeredDriver[name]; ok {
+		return fmt.Errorf("telemetry driver %q already registered", name)
+	}
+
+	t.registeredDriver[name] = driver
+
+	return nil
+}
 
-// loadedDriver is a list of drivers to use for the application
-var loadedDriver []string
+// MustRegisterDriver adds a driver to the driver map and panics if the name is already registered
+func (t *Telemetry) MustRegisterDriver(name string, driver Driver) {
+	err := t.RegisterDriver(name, driver)
+	if err != nil {
+		panic(err)
+	}
+}
 
-// traceDriver is the driver used for the trace
-var traceDriver string
+// RegisteredDrivers returns the sorted names of all registered drivers
+func (t *Telemetry) RegisteredDrivers() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
 
-// RegisterDriver adds the possibility to add a driver to the driver map
-func RegisterDriver(name string, driver Driver) {
-	if registeredDriver == nil {
-		registeredDriver = make(map[string]Driver)
+	names := make([]string, 0, len(t.registeredDriver))
+	for name := range t.registeredDriver {
+		names = append(names, name)
 	}
 
-	registeredDriver[name] = driver
+	sort.Strings(names)
+
+	return names
 }
 
 // getDriver returns the driver based on the provided name
-func getDriver(name string) Driver {
-	val, ok := registeredDriver[name]
+func (t *Telemetry) getDriver(name string) (Driver, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	val, ok := t.registeredDriver[name]
 	if !ok {
-		log.Fatalf("provided telemetry driver is not registered. Driver name: %s", name)
+		return nil, ErrDriverNotRegistered{Name: name}
 	}
 
-	return val
+	return val, nil
 }
 
 // SetDriver ...
-func SetDriver(name ...string) {
-	loadedDriver = name
+func (t *Telemetry) SetDriver(name ...string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.loadedDriver = name
 }
 
-// SetTraceDriver ...
-func SetTraceDriver(name string) {
-	traceDriver = name
+// SetStrict makes Start return ErrNoDriverConfigured if no driver is set and
+// ErrDriverNotRegistered if a set driver is not registered, even if the transaction is not sampled
+func (t *Telemetry) SetStrict(strict bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.strict = strict
+}
+
+// isStrict reports whether the strict mode is enabled
+func (t *Telemetry) isStrict() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.strict
 }
 
-// TransactionContainer ...
+// checkDrivers returns an error if no driver is set or a set driver is not registered.
+// Unlike Start without strict mode, this includes unsampled transactions and disabled drivers
+func (t *Telemetry) checkDrivers() error {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if len(t.loadedDriver) == 0 {
+		return ErrNoDriverConfigured
+	}
+
+	for _, name := range t.loadedDriver {
+		if _, ok := t.registeredDriver[name]; !ok {
+			return ErrDriverNotRegistered{Name: name}
+		}
+	}
+
+	return nil
+}
+
+// EnableDriver enables a previously disabled driver
+func (t *Telemetry) EnableDriver(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.disabledDrivers, name)
+}
+
+// DisableDriver disables a driver at runtime. Start skips disabled drivers completely,
+// transactions which are already started keep their driver
+func (t *Telemetry) DisableDriver(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.disabledDrivers == nil {
+		t.disabledDrivers = make(map[string]struct{})
+	}
+
+	t.disabledDrivers[name] = struct{}{}
+}
+
+// ActiveDrivers returns the drivers which are activated at Start
+func (t *Telemetry) ActiveDrivers() []string {
+	return t.drivers()
+}
+
+// drivers returns the names of the enabled drivers to use
+func (t *Telemetry) drivers() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	names := make([]string, 0, len(t.loadedDriver))
+	for _, name := range t.loadedDriver {
+		if _, ok := t.disabledDrivers[name]; ok {
+			continue
+		}
+
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// SetTraceDriver sets a single driver used for the trace
+func (t *Telemetry) SetTraceDriver(name string) {
+	t.SetTraceDrivers(name)
+}
+
+// SetTraceDrivers sets the drivers used for the trace.
+// The drivers are tried in the provided order and the first one which succeeds is used
+func (t *Telemetry) SetTraceDrivers(names ...string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.traceDrivers = names
+}
+
+// traceDriverNames returns the names of the drivers used for the trace
+func (t *Telemetry) traceDriverNames() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.traceDrivers
+}
+
+// TransactionContainer holds the transactions of all activated drivers.
+// It is safe for concurrent use after Start returns. Copies of a container share the same transactions.
+// The zero value is not usable, containers are only created by Start, StartContext and Clone,
+// which return a usable container even together with an error
 type TransactionContainer struct {
+	mu           *sync.RWMutex
+	telemetry    *Telemetry
 	transactions map[string]Transaction
+	drivers      []string
+	traceDrivers []string
+	segments     *segmentRegistry
+	stack        *segmentStack
+	record       *transactionRecord
+	timing       *transactionTiming
+	baggage      *baggageStore
+	tracestate   *traceState
+	limiter      *logLimiter
+	traces       *traceSet
+	sampled      bool
+}
+
+// Start returns a transaction container with started transactions of all activated drivers of the default instance.
+func Start(name string, opts ...StartOption) (TransactionContainer, error) {
+	return defaultTelemetry.Start(name, opts...)
 }
 
 // Start returns a transaction container with started transactions of all activated drivers.
-func Start(name string) (TransactionContainer, error) {
-	transactionContainer := TransactionContainer{
-		transactions: make(map[string]Transaction, len(loadedDriver)),
-	}
+func (t *Telemetry) Start(name string, opts ...StartOption) (TransactionContainer, error) {
+	_, transactionContainer, err := t.StartContext(context.Background(), name, opts...)
 
-	for _, driverName := range loadedDriver {
-		driver := getDriver(driverName)
-		t, err := driver.InitializeTransaction(name)
+	return transactionContainer, err
+}
+
+// StartWithAttributes starts a transaction like Start on the default instance and adds the attributes before returning
+func StartWithAttributes(name string, attributes map[string]any) (TransactionContainer, error) {
+	return defaultTelemetry.StartWithAttributes(name, attributes)
+}
+
+// StartWithAttributes starts a transaction like Start and adds the attributes before returning,
+// so the transaction never exists without them. It is a shorthand for Start(name, WithAttributes(attributes))
+func (t *Telemetry) StartWithAttributes(name string, attributes map[string]any) (TransactionContainer, error) {
+	return t.Start(name, WithAttributes(attributes))
+}
+
+// start initializes and starts the transactions of all activated drivers
+func (t *Telemetry) start(name string, opts []StartOption) (TransactionContainer, error) {
+	if t.isStrict() {
+		err := t.checkDrivers()
 		if err != nil {
-			return transactionContainer, fmt.Errorf("%s%s - %w", TelemetryDriverError, driverName, err)
+			return t.emptyContainer(name), err
 		}
+	}
 
-		transactionContainer.transactions[driverName] = t
+	transactionContainer, err := t.initialize(name, t.drivers(), t.traceDriverNames(), t.sample(name))
+	if err != nil {
+		return transactionContainer, err
+	}
+
+	t.counters.transactionsStarted.Add(1)
+	if !transactionContainer.sampled {
+		t.counters.transactionsSampledOut.Add(1)
 	}
 
 	processID, err := transactionContainer.CreateProcessID()
@@ -155,51 +493,203 @@ func Start(name string) (TransactionContainer, error) {
 		}
 	}
 
-	for _, transaction := range transactionContainer.transactions {
-		transaction.Start(name)
+	sc := newStartConfig(opts)
+
+	transactionContainer.begin(name)
+	transactionContainer.addResourceAttributes()
+	transactionContainer.addRuntimeStats()
+	for key, value := range sc.attributes {
+		transactionContainer.AddTransactionAttribute(key, value)
+	}
+	t.attachStartupLogs(&transactionContainer)
+
+	for _, link := range sc.links {
+		err = transactionContainer.AddLink(link.trace, link.attributes)
+		if err != nil {
+			log.Print(err)
+		}
 	}
 
 	return transactionContainer, nil
 }
 
-// CreateProcessID creates the process id for all drivers depending on the trace driver
-func (tc *TransactionContainer) CreateProcessID() (string, error) {
-	var processID string
-	val, ok := tc.transactions[traceDriver]
-	if !ok {
-		return processID, fmt.Errorf("provided telemetry trace driver is not registered. Trace driver name: %s", traceDriver)
+// emptyContainer returns a transaction container without drivers, returned together with an error
+// so callers deferring Done on the result do not crash
+func (t *Telemetry) emptyContainer(name string) TransactionContainer {
+	transactionContainer, _ := t.initialize(name, nil, nil, true)
+
+	return transactionContainer
+}
+
+// initialize returns a transaction container with initialized transactions of the provided drivers, see initializeTransactions.
+// If a driver fails, the transactions of the other drivers are ended and the error is returned.
+// If the transaction is not sampled, the container is backed by the noop driver instead
+func (t *Telemetry) initialize(name string, loadedDriver []string, traceDrivers []string, sampled bool) (TransactionContainer, error) {
+	transactionContainer := TransactionContainer{
+		mu:           &sync.RWMutex{},
+		telemetry:    t,
+		transactions: make(map[string]Transaction, len(loadedDriver)),
+		drivers:      append([]string(nil), loadedDriver...),
+		traceDrivers: traceDrivers,
+		segments:     newSegmentRegistry(t.now, t.snapshotsEnabled()),
+		stack:        &segmentStack{},
+		record:       newTransactionRecord(name, t.snapshotsEnabled()),
+		timing:       &transactionTiming{now: t.now},
+		baggage:      newBaggageStore(),
+		tracestate:   &traceState{},
+		limiter:      &logLimiter{now: t.now},
+		traces:       &traceSet{},
+		sampled:      sampled,
 	}
 
-	processID, err := val.CreateProcessID()
-	if err != nil {
-		return processID, fmt.Errorf("%s%s Function: CreateProcessID | Error: %w", TelemetryDriverError, traceDriver, err)
+	if !sampled {
+		transactionContainer.transactions[NoopDriverName] = noopTransaction{name: name}
+		transactionContainer.drivers = []string{NoopDriverName}
+		transactionContainer.traceDrivers = []string{NoopDriverName}
+
+		return transactionContainer, nil
+	}
+
+	drivers := make([]Driver, len(loadedDriver))
+	for i, driverName := range loadedDriver {
+		driver, err := t.getDriver(driverName)
+		if err != nil {
+			return transactionContainer, err
+		}
+
+		drivers[i] = driver
 	}
 
-	return processID, nil
+	transactions, errs := t.initializeTransactions(drivers, name)
+
+	for i, driverName := range loadedDriver {
+		if errs[i] != nil {
+			endTransactions(transactions)
+			return transactionContainer, transactionContainer.driverError(driverName, "InitializeTransaction", errs[i])
+		}
+	}
+
+	for i, driverName := range loadedDriver {
+		transactionContainer.transactions[driverName] = transactions[i]
+	}
+
+	return transactionContainer, nil
 }
 
-// ProcessID returns the process id for all drivers depending on the trace driver
+// begin starts the timing and the transactions of all drivers
+func (tc *TransactionContainer) begin(name string) {
+	tc.timing.begin()
+	for _, driverName := range tc.driverOrder() {
+		transaction := tc.transactions[driverName]
+		err := safeCall(func() error {
+			transaction.Start(name)
+			return nil
+		})
+		if err != nil {
+			tc.logDriverError(driverName, "Start", err)
+		}
+	}
+}
+
+// SetName renames the transaction in the registered driver transactions, e.g. once a request is matched to its route template.
+// It allows starting the transaction with a placeholder name to keep the cardinality of the transaction names low
+func (tc *TransactionContainer) SetName(name string) error {
+	if name == "" {
+		return errors.New("transaction name must not be empty")
+	}
+
+	tc.record.rename(name)
+
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+
+	var ew ErrorWrapper
+
+	for _, driverName := range tc.driverOrder() {
+		transaction := tc.transactions[driverName]
+		err := safeCall(func() error { return transaction.SetName(name) })
+		if err != nil {
+			ew.Add(tc.driverError(driverName, "SetName", err))
+		}
+	}
+
+	return ew.Error()
+}
+
+// CreateProcessID creates the process id for all drivers depending on the trace drivers
+func (tc *TransactionContainer) CreateProcessID() (string, error) {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+
+	var processID string
+	_, err := tc.traceTransaction("CreateProcessID", func(transaction Transaction) error {
+		var err error
+		processID, err = transaction.CreateProcessID()
+
+		return err
+	})
+
+	return processID, err
+}
+
+// ProcessID returns the process id for all drivers depending on the trace drivers.
+// The process id is converted with the formatter set by SetProcessIDFormatter
 func (tc *TransactionContainer) ProcessID() (string, error) {
-	val, ok := tc.transactions[traceDriver]
-	if !ok {
-		return "", fmt.Errorf("provided telemetry trace driver is not registered. Trace driver name: %s", traceDriver)
+	processID, err := tc.rawProcessID()
+
+	return tc.telemetry.formatProcessID(processID), err
+}
+
+// rawProcessID returns the process id of the trace drivers as stored by the drivers
+func (tc *TransactionContainer) rawProcessID() (string, error) {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+
+	var processID string
+	_, err := tc.traceTransaction("ProcessID", func(transaction Transaction) error {
+		var err error
+		processID, err = transaction.ProcessID()
+
+		return err
+	})
+
+	return processID, err
+}
+
+// RegenerateProcessID creates a new process id with the trace drivers and sets it for all drivers.
+// It is meant for containers which are reused for a new unit of work. The formatted process id is returned
+func (tc *TransactionContainer) RegenerateProcessID() (string, error) {
+	processID, err := tc.CreateProcessID()
+	if err != nil {
+		return processID, ErrorProcessID{
+			err: err,
+		}
 	}
 
-	return val.ProcessID()
+	err = tc.SetProcessID(processID)
+	if err != nil {
+		return processID, ErrorProcessID{
+			err: err,
+		}
+	}
+
+	return tc.telemetry.formatProcessID(processID), nil
 }
 
-// StartTracing creates and sets the trace for all drivers depending on the trace driver
+// StartTracing creates and sets the trace for all drivers depending on the trace drivers
 func (tc *TransactionContainer) StartTracing() (string, error) {
 	var trace string
 
-	val, ok := tc.transactions[traceDriver]
-	if !ok {
-		return trace, fmt.Errorf("provided telemetry trace driver is not registered. Trace driver name: %s", traceDriver)
-	}
+	tc.mu.RLock()
+	_, err := tc.traceTransaction("StartTracing", func(transaction Transaction) error {
+		var err error
+		trace, err = transaction.CreateTrace()
 
-	trace, err := val.CreateTrace()
+		return err
+	})
+	tc.mu.RUnlock()
 	if err != nil {
-		return trace, fmt.Errorf("%s%s Function: StartTracing | Error: %w", TelemetryDriverError, traceDriver, err)
+		return trace, err
 	}
 
 	err = tc.SetTrace(trace)
@@ -211,177 +701,698 @@ func (tc *TransactionContainer) StartTracing() (string, error) {
 }
 
 // AddTransactionAttribute adds attributes to the registered driver transactions
+// Attributes with an unsupported type are dropped
 func (tc *TransactionContainer) AddTransactionAttribute(name string, attribute any) {
-	for driverName, transaction := range tc.transactions {
-		err := transaction.AddTransactionAttribute(name, attribute)
-		if err != nil {
-			log.Printf("%s%s Function: AddTransactionAttribute | Error: %v", TelemetryDriverError, driverName, err)
+	attribute = tc.telemetry.redact(name, attribute)
+
+	err := validateAttribute(name, attribute)
+	if err != nil {
+		tc.telemetry.counters.attributesDropped.Add(1)
+		log.Printf("telemetry Function: AddTransactionAttribute | Error: %v", err)
+		return
+	}
+
+	_, limit := tc.telemetry.attributeLimits()
+	attributes, limitExceeded := tc.record.addAttribute(name, attribute, limit)
+	tc.telemetry.countDroppedAttributes(map[string]any{name: attribute}, attributes)
+	if limitExceeded {
+		log.Printf("telemetry Function: AddTransactionAttribute | Warning: limit of %d attributes reached, further attributes are dropped", limit)
+	}
+
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+
+	for _, driverName := range tc.driverOrder() {
+		transaction := tc.transactions[driverName]
+		for name, attribute := range attributes {
+			err := safeCall(func() error { return transaction.AddTransactionAttribute(name, attribute) })
+			if err != nil {
+				tc.logDriverError(driverName, "AddTransactionAttribute", err)
+			}
 		}
 	}
 }
 
 // SegmentStart starts a segment in the registered driver transactions
 func (tc *TransactionContainer) SegmentStart(name string) string {
-	segmentID := uuid.NewString()
+	segmentID, err := tc.SegmentStartE(name)
+	if err != nil {
+		log.Print(err)
+	}
 
-	for driverName, transaction := range tc.transactions {
-		err := transaction.SegmentStart(segmentID, name)
+	return segmentID
+}
+
+// SegmentStartE starts a segment in the registered driver transactions.
+// The segmentID is returned even if some drivers failed to start the segment
+func (tc *TransactionContainer) SegmentStartE(name string) (string, error) {
+	segmentID := tc.telemetry.newID()
+
+	return segmentID, tc.SegmentStartWithID(segmentID, name)
+}
+
+// SegmentStartWithID starts a segment with a caller supplied segmentID in the registered driver transactions.
+// It returns an error if the segmentID is empty or already in use
+func (tc *TransactionContainer) SegmentStartWithID(segmentID string, name string) error {
+	var ew ErrorWrapper
+
+	err := tc.startSegment(segmentID, "", name)
+	if err != nil {
+		return err
+	}
+
+	defaults := tc.segmentDefaults()
+	tc.segments.inherit(segmentID, defaults)
+
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+
+	for _, driverName := range tc.driverOrder() {
+		transaction := tc.transactions[driverName]
+		err := safeCall(func() error { return transaction.SegmentStart(segmentID, name) })
+		if err != nil {
+			ew.Add(tc.driverError(driverName, "SegmentStart", err))
+			continue
+		}
+
+		err = tc.applySegmentDefaults(driverName, transaction, segmentID, defaults)
 		if err != nil {
-			log.Printf("%s%s Function: SegmentStart | Error: %v", TelemetryDriverError, driverName, err)
+			ew.Add(err)
 		}
 	}
 
-	return segmentID
+	return ew.Error()
+}
+
+// SegmentStartChild starts a segment inside the provided parent segment in the registered driver transactions.
+// It returns an error if the parent segment is unknown or already ended
+func (tc *TransactionContainer) SegmentStartChild(parentSegmentID string, name string) (string, error) {
+	var ew ErrorWrapper
+
+	err := tc.segments.active(parentSegmentID)
+	if err != nil {
+		return "", fmt.Errorf("invalid parent segment: %w", err)
+	}
+
+	segmentID := tc.telemetry.newID()
+	err = tc.startSegment(segmentID, parentSegmentID, name)
+	if err != nil {
+		return "", err
+	}
+
+	defaults := tc.segmentDefaults()
+	tc.segments.inherit(segmentID, defaults)
+
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+
+	for _, driverName := range tc.driverOrder() {
+		transaction := tc.transactions[driverName]
+		err := safeCall(func() error { return transaction.SegmentStartChild(parentSegmentID, segmentID, name) })
+		if err != nil {
+			ew.Add(tc.driverError(driverName, "SegmentStartChild", err))
+			continue
+		}
+
+		err = tc.applySegmentDefaults(driverName, transaction, segmentID, defaults)
+		if err != nil {
+			ew.Add(err)
+		}
+	}
+
+	return segmentID, ew.Error()
 }
 
 // AddSegmentAttribute adds attributes to a segment for all driver
+// Attributes with an unsupported type are dropped
 func (tc *TransactionContainer) AddSegmentAttribute(segmentID string, name string, attribute any) {
-	for driverName, transaction := range tc.transactions {
-		err := transaction.AddSegmentAttribute(segmentID, name, attribute)
+	attribute = tc.telemetry.redact(name, attribute)
+
+	err := validateAttribute(name, attribute)
+	if err != nil {
+		tc.telemetry.counters.attributesDropped.Add(1)
+		log.Printf("telemetry Function: AddSegmentAttribute | Error: %v", err)
+		return
+	}
+
+	attributes := tc.limitSegmentAttributes(segmentID, map[string]any{name: attribute})
+	if tc.deferSegmentAttributes(segmentID, attributes) {
+		return
+	}
+
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+
+	for _, driverName := range tc.driverOrder() {
+		transaction := tc.transactions[driverName]
+		for name, attribute := range attributes {
+			err := safeCall(func() error { return transaction.AddSegmentAttribute(segmentID, name, attribute) })
+			if err != nil {
+				tc.logDriverError(driverName, "AddSegmentAttribute", err)
+			}
+		}
+	}
+}
+
+// AddSegmentAttributes adds multiple attributes to a segment for all driver with one call per driver
+// Attributes with an unsupported type are dropped
+func (tc *TransactionContainer) AddSegmentAttributes(segmentID string, attributes map[string]any) {
+	attributes, err := tc.validAttributes(attributes)
+	if err != nil {
+		log.Printf("telemetry Function: AddSegmentAttributes | Error: %v", err)
+	}
+
+	attributes = tc.limitSegmentAttributes(segmentID, attributes)
+	if tc.deferSegmentAttributes(segmentID, attributes) {
+		return
+	}
+
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+
+	for _, driverName := range tc.driverOrder() {
+		transaction := tc.transactions[driverName]
+		err := safeCall(func() error { return transaction.AddSegmentAttributes(segmentID, attributes) })
 		if err != nil {
-			log.Printf("%s%s Function: AddSegmentAttribute | Error: %v", TelemetryDriverError, driverName, err)
+			tc.logDriverError(driverName, "AddSegmentAttributes", err)
 		}
 	}
 }
 
-// SegmentEnd ends a segment in the registered driver transactions
+// limitSegmentAttributes retains the attributes of the segment and returns the attributes within the limit
+// which are passed to the drivers under the attribute merge policy
+func (tc *TransactionContainer) limitSegmentAttributes(segmentID string, attributes map[string]any) map[string]any {
+	limit, _ := tc.telemetry.attributeLimits()
+	policy := tc.telemetry.mergePolicy()
+
+	limited, limitExceeded, duplicates := tc.segments.addAttributes(segmentID, attributes, limit, policy)
+	logDuplicateAttributes(segmentID, duplicates, policy)
+	tc.telemetry.countDroppedAttributes(attributes, limited)
+	if limitExceeded {
+		log.Printf("telemetry segment %s | Warning: limit of %d attributes reached, further attributes are dropped", segmentID, limit)
+	}
+
+	return limited
+}
+
+// SegmentEnd ends a segment with StatusOK in the registered driver transactions.
+// A segment ending faster than the minimum segment duration is ended with StatusDropped instead.
+// An unknown or already ended segment, e.g. one cancelled by SegmentStartWithContext, is not ended again
 func (tc *TransactionContainer) SegmentEnd(segmentID string) {
-	for driverName, transaction := range tc.transactions {
-		err := transaction.SegmentEnd(segmentID)
+	status, active, _ := tc.endSegment(segmentID, StatusOK)
+	if !active {
+		return
+	}
+
+	pending := tc.segments.takePending(segmentID)
+	tc.segments.release(segmentID)
+
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+
+	for _, driverName := range tc.driverOrder() {
+		transaction := tc.transactions[driverName]
+		if status == StatusDropped {
+			err := safeCall(func() error { return transaction.SegmentEndWithStatus(segmentID, StatusDropped) })
+			if err != nil {
+				tc.logDriverError(driverName, "SegmentEndWithStatus", err)
+			}
+
+			continue
+		}
+
+		if len(pending) > 0 {
+			err := safeCall(func() error { return transaction.AddSegmentAttributes(segmentID, pending) })
+			if err != nil {
+				tc.logDriverError(driverName, "AddSegmentAttributes", err)
+			}
+		}
+
+		err := safeCall(func() error { return transaction.SegmentEnd(segmentID) })
 		if err != nil {
-			log.Printf("%s%s Function: SegmentEnd | Error: %v", TelemetryDriverError, driverName, err)
+			tc.logDriverError(driverName, "SegmentEnd", err)
 		}
 	}
 }
 
 // SetProcessID sets the trace for all transactions
+// The process id is passed to the drivers unchanged, convert a formatted process id with ParseProcessID first.
+// An empty process id returns ErrEmptyProcessID without calling any driver
 func (tc *TransactionContainer) SetProcessID(processID string) error {
+	if processID == "" {
+		return ErrEmptyProcessID
+	}
+
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+
 	var ew ErrorWrapper
 
-	for driverName, transaction := range tc.transactions {
-		err := transaction.SetProcessID(processID)
+	for _, driverName := range tc.driverOrder() {
+		transaction := tc.transactions[driverName]
+		err := safeCall(func() error { return transaction.SetProcessID(processID) })
 		if err != nil {
-			ew.Add(fmt.Errorf("%s%s Function: SetProcessID | Error: %w", TelemetryDriverError, driverName, err))
+			ew.Add(tc.driverError(driverName, "SetProcessID", err))
 		}
 	}
 
 	return ew.Error()
 }
 
-// SetTrace sets the traceID for each driver based on the traceDriver
+// SetTrace sets the trace on the first trace driver accepting it and its traceID on every other driver.
+// An empty trace or one rejected by the trace validator is returned as error without calling any driver
 func (tc *TransactionContainer) SetTrace(trace string) error {
+	err := tc.telemetry.validateTrace(trace)
+	if err != nil {
+		return err
+	}
+
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+
 	var ew ErrorWrapper
 
-	val, ok := tc.transactions[traceDriver]
-	if !ok {
-		return fmt.Errorf("provided telemetry trace driver is not registered. Trace driver name: %s", traceDriver)
-	}
+	var traceID string
+	traceDriverName, err := tc.traceTransaction("SetTrace", func(transaction Transaction) error {
+		err := transaction.SetTrace(trace)
+		if err != nil {
+			return err
+		}
 
-	err := val.SetTrace(trace)
+		traceID, err = transaction.TraceID()
+
+		return err
+	})
 	if err != nil {
 		return err
 	}
 
-	traceID, err := val.TraceID()
-
-	for driverName, transaction := range tc.transactions {
-		if driverName == traceDriver {
+	for _, driverName := range tc.driverOrder() {
+		transaction := tc.transactions[driverName]
+		if driverName == traceDriverName {
 			continue
 		}
 
-		err := transaction.SetTraceID(traceID)
+		err := safeCall(func() error { return transaction.SetTraceID(traceID) })
 		if err != nil {
-			ew.Add(fmt.Errorf("%s%s Function: setTraceID | Error: %w", TelemetryDriverError, driverName, err))
+			ew.Add(tc.driverError(driverName, "setTraceID", err))
 		}
 	}
 
 	return ew.Error()
 }
 
-// Trace gets the trace of the transaction used for trace
+// Trace gets the trace of the first trace driver providing one
 func (tc *TransactionContainer) Trace() (string, error) {
-	val, ok := tc.transactions[traceDriver]
-	if !ok {
-		return "", fmt.Errorf("provided telemetry trace driver is not registered. Trace driver name: %s", traceDriver)
-	}
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
 
-	trace, err := val.Trace()
-	if err != nil {
-		return "", fmt.Errorf("%s%s Function: Trace | Error: %w", TelemetryDriverError, traceDriver, err)
-	}
+	var trace string
+	_, err := tc.traceTransaction("Trace", func(transaction Transaction) error {
+		var err error
+		trace, err = transaction.Trace()
 
-	return trace, nil
+		return err
+	})
+
+	return trace, err
 }
 
 // setTraceID sets the trace for all transactions
 func (tc *TransactionContainer) setTraceID(traceID string) error {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+
 	var ew ErrorWrapper
 
-	for driverName, transaction := range tc.transactions {
-		err := transaction.SetTraceID(traceID)
+	for _, driverName := range tc.driverOrder() {
+		transaction := tc.transactions[driverName]
+		err := safeCall(func() error { return transaction.SetTraceID(traceID) })
 		if err != nil {
-			ew.Add(fmt.Errorf("%s%s Function: setTraceID | Error: %w", TelemetryDriverError, driverName, err))
+			ew.Add(tc.driverError(driverName, "setTraceID", err))
 		}
 	}
 
 	return ew.Error()
 }
 
-// TraceID returns the traceID from transaction container
+// TraceID returns the traceID of the first trace driver providing one
 func (tc *TransactionContainer) TraceID() (string, error) {
-	val, ok := tc.transactions[traceDriver]
-	if !ok {
-		return "", fmt.Errorf("provided telemetry trace driver is not registered. Trace driver name: %s", traceDriver)
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+
+	var traceID string
+	_, err := tc.traceTransaction("TraceID", func(transaction Transaction) error {
+		var err error
+		traceID, err = transaction.TraceID()
+
+		return err
+	})
+
+	return traceID, err
+}
+
+// driverOrder returns the names of the transactions in the order the drivers were set with SetDriver,
+// so every fan-out reaches the drivers in the same order. The caller must hold the read lock
+func (tc *TransactionContainer) driverOrder() []string {
+	driverNames := make([]string, 0, len(tc.transactions))
+	for _, driverName := range tc.drivers {
+		if _, ok := tc.transactions[driverName]; ok {
+			driverNames = append(driverNames, driverName)
+		}
 	}
 
-	return val.TraceID()
+	return driverNames
 }
 
-// Done ends the transactions for the registered driver
-func (tc *TransactionContainer) Done() {
-	for driverName, transaction := range tc.transactions {
-		err := transaction.Done()
+// traceTransaction calls fn with the transaction of each trace driver in order until it succeeds.
+// It returns the name of the succeeding trace driver or the errors of all trace drivers.
+// The caller must hold the read lock
+func (tc *TransactionContainer) traceTransaction(function string, fn func(Transaction) error) (string, error) {
+	var ew ErrorWrapper
+
+	if len(tc.traceDrivers) == 0 {
+		return "", ErrTraceDriverNotSet
+	}
+
+	for _, driverName := range tc.traceDrivers {
+		transaction, ok := tc.transactions[driverName]
+		if !ok {
+			ew.Add(ErrDriverNotRegistered{Name: driverName})
+			continue
+		}
+
+		err := safeCall(func() error { return fn(transaction) })
 		if err != nil {
-			log.Printf("%s%s Function: Done | Error: %v", TelemetryDriverError, driverName, err)
+			ew.Add(tc.driverError(driverName, function, err))
+			continue
 		}
-		transaction.Erase()
+
+		return driverName, nil
 	}
+
+	return "", ew.Error()
 }
 
-// Info logs informations in the registered driver transactions
-// If segmentID is empty, the info will be logged directly on the transaction
-func (tc *TransactionContainer) Info(segmentID string, msg *string) {
-	for driverName, transaction := range tc.transactions {
-		rc := io.NopCloser(strings.NewReader(*msg))
-		err := transaction.Info(segmentID, rc)
+// Flush tells every driver to push buffered data immediately while keeping the transactions open
+func (tc *TransactionContainer) Flush() error {
+	var ew ErrorWrapper
+
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+
+	for _, driverName := range tc.driverOrder() {
+		transaction := tc.transactions[driverName]
+		err := safeCall(transaction.Flush)
+		if err != nil {
+			ew.Add(tc.driverError(driverName, "Flush", err))
+		}
+	}
+
+	return ew.Error()
+}
+
+// Done ends the transactions for the registered driver and returns the joined driver errors.
+// Every transaction is erased regardless of errors. Calling Done more than once is a no-op
+func (tc *TransactionContainer) Done() error {
+	err := tc.DoneContext(context.Background())
+	if err != nil {
+		log.Print(err)
+	}
+
+	return err
+}
+
+// DoneContext ends the transactions for the registered driver concurrently.
+// If ctx expires before a driver finished, DoneContext stops waiting and returns the context error for each pending driver.
+// Transactions of drivers which finished in time are erased. Calling DoneContext more than once is a no-op.
+// Segments which are still open are ended with StatusError first, see OpenSegments.
+// Log messages suppressed by the log rate limit since the last summary are reported first, see SetLogRateLimit.
+// Before ending, the duration of the transaction is added as DurationAttribute
+func (tc *TransactionContainer) DoneContext(ctx context.Context) error {
+	var ew ErrorWrapper
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	tc.logSuppressed(tc.limiter.flush())
+
+	err := tc.doneTraces(ctx)
+	if err != nil {
+		ew.Add(err)
+	}
+
+	tc.addRuntimeStatsDelta()
+
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	type doneResult struct {
+		driverName string
+		err        error
+	}
+
+	if len(tc.transactions) > 0 {
+		err := tc.endOpenSegments()
 		if err != nil {
-			log.Printf("%s%s | Function: Info | Error: %v", TelemetryDriverError, driverName, err)
+			ew.Add(err)
+		}
+
+		durationMs := tc.timing.stop().Milliseconds()
+		tc.record.addAttribute(DurationAttribute, durationMs, 0)
+		for _, driverName := range tc.driverOrder() {
+			transaction := tc.transactions[driverName]
+			err := safeCall(func() error { return transaction.AddTransactionAttribute(DurationAttribute, durationMs) })
+			if err != nil {
+				ew.Add(tc.driverError(driverName, "AddTransactionAttribute", err))
+			}
 		}
+
+		tc.record.finish(tc.snapshot())
+	}
+
+	results := make(chan doneResult, len(tc.transactions))
+	pending := make(map[string]Transaction, len(tc.transactions))
+
+	for _, driverName := range tc.driverOrder() {
+		transaction := tc.transactions[driverName]
+		pending[driverName] = transaction
+
+		go func(driverName string, transaction Transaction) {
+			results <- doneResult{
+				driverName: driverName,
+				err:        safeCall(transaction.Done),
+			}
+		}(driverName, transaction)
+	}
+
+	for len(pending) > 0 {
+		select {
+		case result := <-results:
+			if result.err != nil {
+				ew.Add(tc.driverError(result.driverName, "Done", result.err))
+			}
+
+			err := safeCall(func() error {
+				pending[result.driverName].Erase()
+				return nil
+			})
+			if err != nil {
+				ew.Add(tc.driverError(result.driverName, "Erase", err))
+			}
+			delete(pending, result.driverName)
+		case <-ctx.Done():
+			for _, driverName := range tc.driverOrder() {
+				if _, ok := pending[driverName]; ok {
+					ew.Add(tc.driverError(driverName, "Done", ctx.Err()))
+				}
+			}
+
+			clear(pending)
+		}
+	}
+
+	clear(tc.transactions)
+	tc.segments.clear()
+	tc.stack.clear()
+
+	return ew.Error()
+}
+
+// Info logs informations in the registered driver transactions
+// If segmentID is empty, the info will be logged directly on the transaction. A nil msg is logged as empty message
+func (tc *TransactionContainer) Info(segmentID string, msg *string) {
+	if !tc.telemetry.logEnabled(LevelInfo) || !tc.allowLog() {
+		return
+	}
+
+	message := tc.telemetry.truncateInfo(tc.telemetry.redactMessage(FieldMessage, messageString(msg)))
+	err := tc.logMessage(nil, "Info", segmentID, LevelInfo, message)
+	if err != nil {
+		log.Print(err)
 	}
 }
 
 // Error logs errors in the registered driver transactions
-// If segmentID is empty, the error will be logged directly on the transaction
+// If segmentID is empty, the error will be logged directly on the transaction. A nil error is logged as NilErrorMessage
 func (tc *TransactionContainer) Error(segmentID string, err *error) {
-	for driverName, transaction := range tc.transactions {
-		rc := io.NopCloser(strings.NewReader((*err).Error()))
-		err := transaction.Error(segmentID, rc)
-		if err != nil {
-			log.Printf("%s%s Function: Error | Error: %v", TelemetryDriverError, driverName, err)
-		}
+	if !tc.telemetry.logEnabled(LevelError) || !tc.allowLog() {
+		return
+	}
+
+	message := tc.telemetry.truncateError(tc.telemetry.redactMessage(FieldError, errorString(err)))
+	logErr := tc.logMessage(nil, "Error", segmentID, LevelError, message)
+	if logErr != nil {
+		log.Print(logErr)
+	}
+}
+
+// Warn logs warnings in the registered driver transactions
+// If segmentID is empty, the warning will be logged directly on the transaction
+func (tc *TransactionContainer) Warn(segmentID string, msg *string) {
+	if !tc.telemetry.logEnabled(LevelWarn) || !tc.allowLog() {
+		return
+	}
+
+	message := tc.telemetry.truncateInfo(tc.telemetry.redactMessage(FieldMessage, messageString(msg)))
+	err := tc.logMessage(nil, "Warn", segmentID, LevelWarn, message)
+	if err != nil {
+		log.Print(err)
 	}
 }
 
 // Debug logs debug in the registered driver transactions
 // If segmentID is empty, the info will be logged directly on the transaction
 func (tc *TransactionContainer) Debug(segmentID string, msg *string) {
-	for driverName, transaction := range tc.transactions {
-		rc := io.NopCloser(strings.NewReader(*msg))
-		err := transaction.Debug(segmentID, rc)
+	if !tc.telemetry.logEnabled(LevelDebug) {
+		return
+	}
+
+	message := tc.telemetry.truncateInfo(tc.telemetry.redactMessage(FieldMessage, messageString(msg)))
+	err := tc.logMessage(nil, "Debug", segmentID, LevelDebug, message)
+	if err != nil {
+		log.Print(err)
+	}
+}
+
+// logMessage records the message and logs it with the log prefix in the registered driver transactions,
+// with the driver method of its level. If the baggage or the caller is added to the log, the message is logged
+// with ErrorFields under FieldError or with InfoFields under FieldMessage instead, like slog records, together
+// with the OTel severity of the level under FieldSeverity. Compressed messages, see SetLogCompression, are logged
+// as fields as well with the encoding under FieldContentEncoding. Drivers implementing ContextTransaction receive a
+// non-nil ctx for info and error messages, every other driver is skipped once ctx is done
+func (tc *TransactionContainer) logMessage(ctx context.Context, function string, segmentID string, level Level, message string) error {
+	var ew ErrorWrapper
+
+	fields := tc.logFields()
+	tc.recordLog(segmentID, level, message, fields)
+	message, encoding := tc.telemetry.compressMessage(tc.prefixMessage(message))
+
+	if fields != nil || encoding != "" {
+		fields = maps.Clone(fields)
+		if fields == nil {
+			fields = make(map[string]any, 3)
+		}
+
+		if level == LevelError {
+			fields[FieldError] = message
+		} else {
+			fields[FieldMessage] = message
+		}
+		fields[FieldSeverity] = level.OTelSeverity()
+
+		if encoding != "" {
+			fields[FieldContentEncoding] = encoding
+		}
+	}
+
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+
+	for _, driverName := range tc.driverOrder() {
+		transaction := tc.transactions[driverName]
+
+		var err error
+		if ctx != nil {
+			err = ctx.Err()
+		}
+
+		if err == nil {
+			err = safeCall(func() error { return logTransaction(ctx, transaction, segmentID, level, message, fields) })
+		}
+
 		if err != nil {
-			log.Printf("%s%s | Function: Debug | Error: %v", TelemetryDriverError, driverName, err)
+			ew.Add(tc.driverError(driverName, function, err))
 		}
 	}
+
+	return ew.Error()
+}
+
+// logFields returns the baggage and the caller as fields of a log message or nil if there are none
+func (tc *TransactionContainer) logFields() map[string]any {
+	fields := tc.withBaggage(tc.callerFields())
+	if len(fields) == 0 {
+		return nil
+	}
+
+	return tc.telemetry.redactFields(normalizeFields(fields))
+}
+
+// logTransaction logs the message on the transaction with the method matching the level and the fields
+func logTransaction(ctx context.Context, transaction Transaction, segmentID string, level Level, message string, fields map[string]any) error {
+	if fields != nil {
+		if level == LevelError {
+			return transaction.ErrorFields(segmentID, fields)
+		}
+
+		return transaction.InfoFields(segmentID, fields)
+	}
+
+	rc := io.NopCloser(strings.NewReader(message))
+	ct, withContext := transaction.(ContextTransaction)
+	withContext = withContext && ctx != nil
+
+	switch level {
+	case LevelDebug:
+		return transaction.Debug(segmentID, rc)
+	case LevelWarn:
+		return transaction.Warn(segmentID, rc)
+	case LevelError:
+		if withContext {
+			return ct.ErrorContext(ctx, segmentID, rc)
+		}
+
+		return transaction.Error(segmentID, rc)
+	default:
+		if withContext {
+			return ct.InfoContext(ctx, segmentID, rc)
+		}
+
+		return transaction.Info(segmentID, rc)
+	}
+}
+
+// NilErrorMessage is logged if Error is called with a nil error
+const NilErrorMessage = "<nil error>"
+
+// messageString returns the message or an empty string if msg is nil
+func messageString(msg *string) string {
+	if msg == nil {
+		return ""
+	}
+
+	return *msg
+}
+
+// errorString returns the error message or NilErrorMessage if there is no error
+func errorString(err *error) string {
+	if err == nil || *err == nil {
+		return NilErrorMessage
+	}
+
+	return (*err).Error()
 }
 
 // Error ...
@@ -0,0 +1,250 @@
+package telemetry_test
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/telemetrytest"
+)
+
+// newNoopTelemetry returns a telemetry instance with the noop driver as driver and trace driver, e.g. for benchmarks
+func newNoopTelemetry() *telemetry.Telemetry {
+	tel := telemetry.New()
+	tel.SetDriver(telemetry.NoopDriverName)
+	tel.SetTraceDriver(telemetry.NoopDriverName)
+
+	return tel
+}
+
+// start starts a transaction and fails the test on an error
+func start(t testing.TB, tel *telemetry.Telemetry, name string) telemetry.TransactionContainer {
+	t.Helper()
+
+	transaction, err := tel.Start(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return transaction
+}
+
+func TestStartStrictWithoutDriverReturnsUsableContainer(t *testing.T) {
+	tel := telemetry.New()
+	tel.SetStrict(true)
+
+	transaction, err := tel.Start("strict")
+	if !errors.Is(err, telemetry.ErrNoDriverConfigured) {
+		t.Fatalf("expected ErrNoDriverConfigured, got %v", err)
+	}
+
+	msg := "message"
+	transaction.Info("", &msg)
+	transaction.Error("", &err)
+	transaction.SegmentEnd(transaction.SegmentStart("segment"))
+
+	err = transaction.Done()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestContainerConcurrentSegments(t *testing.T) {
+	tel, recorder := telemetrytest.NewTelemetry(t)
+	transaction := start(t, tel, "concurrent")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 20; j++ {
+				segmentID := transaction.SegmentStart("segment")
+				transaction.AddSegmentAttribute(segmentID, "iteration", j)
+				transaction.SegmentEnd(segmentID)
+			}
+		}()
+	}
+	wg.Wait()
+
+	err := transaction.Done()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = transaction.Done()
+	if err != nil {
+		t.Fatalf("second Done: %v", err)
+	}
+
+	if n := len(recorder.Segments()); n != 1000 {
+		t.Fatalf("expected 1000 segments, got %d", n)
+	}
+}
+
+func TestStartWithUnregisteredDriverReturnsError(t *testing.T) {
+	tel := telemetry.New()
+	tel.SetDriver("missing")
+
+	transaction, err := tel.Start("missing")
+	var notRegistered telemetry.ErrDriverNotRegistered
+	if !errors.As(err, &notRegistered) || notRegistered.Name != "missing" {
+		t.Fatalf("expected ErrDriverNotRegistered for missing, got %v", err)
+	}
+
+	err = transaction.Done()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// untracedDriver is a recording driver whose transactions fail to create traces and process ids
+type untracedDriver struct {
+	*telemetrytest.RecordingDriver
+}
+
+// untracedTransaction fails to create traces and process ids
+type untracedTransaction struct {
+	telemetry.Transaction
+}
+
+// InitializeTransaction returns a recording transaction failing to create traces and process ids
+func (d untracedDriver) InitializeTransaction(name string) (telemetry.Transaction, error) {
+	transaction, err := d.RecordingDriver.InitializeTransaction(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return untracedTransaction{Transaction: transaction}, nil
+}
+
+// CreateTrace returns an error
+func (untracedTransaction) CreateTrace() (string, error) {
+	return "", errors.New("trace unavailable")
+}
+
+// CreateProcessID returns an error
+func (untracedTransaction) CreateProcessID() (string, error) {
+	return "", errors.New("process id unavailable")
+}
+
+func TestTraceDriversFallBackInOrder(t *testing.T) {
+	recorder := telemetrytest.New()
+
+	tel := telemetry.New()
+	for name, driver := range map[string]telemetry.Driver{"untraced": untracedDriver{RecordingDriver: telemetrytest.New()}, "recorder": recorder} {
+		err := tel.RegisterDriver(name, driver)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tel.SetDriver("untraced", "recorder")
+	tel.SetTraceDrivers("untraced", "recorder")
+	transaction := start(t, tel, "fallback")
+
+	trace, err := transaction.StartTracing()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if trace == "" || !slices.Contains(recorder.Calls(), "CreateTrace") {
+		t.Fatalf("expected the trace of the second trace driver, got %q", trace)
+	}
+
+	processID, err := transaction.ProcessID()
+	if err != nil || processID == "" {
+		t.Fatalf("expected the process id of the second trace driver, got %q, %v", processID, err)
+	}
+
+	err = transaction.Done()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTraceDriversFailTogether(t *testing.T) {
+	tel := telemetry.New()
+	err := tel.RegisterDriver("untraced", untracedDriver{RecordingDriver: telemetrytest.New()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tel.SetDriver("untraced")
+	tel.SetTraceDrivers("untraced")
+
+	_, err = tel.Start("untraced")
+	if err == nil {
+		t.Fatal("expected an error if no trace driver creates a trace")
+	}
+}
+
+func TestNilMessageAndError(t *testing.T) {
+	tel, recorder := telemetrytest.NewTelemetry(t)
+	transaction := start(t, tel, "nil")
+
+	transaction.Info("", nil)
+	transaction.Error("", nil)
+
+	var err error
+	transaction.Error("", &err)
+
+	recorder.AssertLog(t, "info", "")
+	recorder.AssertLog(t, "error", telemetry.NilErrorMessage)
+
+	if logs := recorder.Logs(); len(logs) != 3 {
+		t.Fatalf("expected 3 logs, got %d", len(logs))
+	}
+}
+
+// newRecordingTelemetry returns a telemetry instance with a recording driver for every name, loaded in that order.
+// The first driver is the trace driver
+func newRecordingTelemetry(t testing.TB, names ...string) (*telemetry.Telemetry, []*telemetrytest.RecordingDriver) {
+	t.Helper()
+
+	tel := telemetry.New()
+	recorders := make([]*telemetrytest.RecordingDriver, 0, len(names))
+	for _, name := range names {
+		recorder := telemetrytest.New()
+		err := tel.RegisterDriver(name, recorder)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		recorders = append(recorders, recorder)
+	}
+
+	tel.SetDriver(names...)
+	tel.SetTraceDriver(names[0])
+
+	return tel, recorders
+}
+
+func TestSetNameRenamesAllDrivers(t *testing.T) {
+	tel, recorders := newRecordingTelemetry(t, "first", "second")
+	transaction := start(t, tel, "/users/42")
+
+	err := transaction.SetName("/users/:id")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, recorder := range recorders {
+		names := recorder.Names()
+		if len(names) != 1 || names[0] != "/users/:id" {
+			t.Errorf("driver %d: expected the rename, got %v", i, names)
+		}
+	}
+
+	if name := transaction.Snapshot().Name; name != "/users/:id" {
+		t.Fatalf("expected the new name in the snapshot, got %q", name)
+	}
+
+	err = transaction.SetName("")
+	if err == nil {
+		t.Fatal("expected an error for an empty name")
+	}
+}
@@ -0,0 +1,611 @@
+// Package telemetrytest provides a recording telemetry driver for tests
+package telemetrytest
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+)
+
+// RecordingDriver is a driver which records every call for later inspection
+type RecordingDriver struct {
+	mu                    sync.Mutex
+	calls                 []string
+	transactions          []string
+	names                 []string
+	transactionAttributes []Attribute
+	segments              []Segment
+	segmentAttributes     []Attribute
+	events                []Event
+	logs                  []Log
+	links                 []Link
+	statuses              []telemetry.TransactionStatus
+	payloads              []Payload
+	traces                []string
+	traceIDs              []string
+	processIDs            []string
+}
+
+// Attribute is a recorded transaction or segment attribute
+type Attribute struct {
+	SegmentID string
+	Key       string
+	Value     any
+}
+
+// Segment is a recorded segment
+type Segment struct {
+	ID       string
+	ParentID string
+	Name     string
+	Ended    bool
+	Status   telemetry.SegmentStatus
+}
+
+// Event is a recorded segment event
+type Event struct {
+	SegmentID  string
+	Name       string
+	Attributes map[string]any
+}
+
+// Payload is a recorded payload attachment
+type Payload struct {
+	SegmentID string
+	telemetry.Payload
+}
+
+// Link is a recorded link to another trace
+type Link struct {
+	Trace      string
+	Attributes map[string]any
+}
+
+// Log is a recorded log message
+type Log struct {
+	Level     string
+	SegmentID string
+	Message   string
+	Fields    map[string]any
+}
+
+// recordingTransaction is the transaction of the recording driver
+type recordingTransaction struct {
+	driver    *RecordingDriver
+	mu        sync.Mutex
+	trace     string
+	traceID   string
+	processID string
+}
+
+var _ telemetry.Driver = (*RecordingDriver)(nil)
+var _ telemetry.Transaction = (*recordingTransaction)(nil)
+var _ telemetry.PayloadAttacher = (*recordingTransaction)(nil)
+
+// RecorderName is the name NewTelemetry registers the recording driver with
+const RecorderName = "recorder"
+
+// New returns an empty recording driver
+func New() *RecordingDriver {
+	return &RecordingDriver{}
+}
+
+// NewTelemetry returns a telemetry instance with a new recording driver registered as RecorderName,
+// activated as driver and trace driver
+func NewTelemetry(t testing.TB) (*telemetry.Telemetry, *RecordingDriver) {
+	t.Helper()
+
+	recorder := New()
+	tel := telemetry.New()
+	err := tel.RegisterDriver(RecorderName, recorder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tel.SetDriver(RecorderName)
+	tel.SetTraceDriver(RecorderName)
+
+	return tel, recorder
+}
+
+// InitializeTransaction returns a transaction recording into the driver
+func (d *RecordingDriver) InitializeTransaction(name string) (telemetry.Transaction, error) {
+	d.record("InitializeTransaction", func() {
+		d.transactions = append(d.transactions, name)
+	})
+
+	return &recordingTransaction{driver: d}, nil
+}
+
+// record adds the call and runs fn while holding the lock
+func (d *RecordingDriver) record(call string, fn func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.calls = append(d.calls, call)
+	if fn != nil {
+		fn()
+	}
+}
+
+// Calls returns the names of all recorded calls in order
+func (d *RecordingDriver) Calls() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]string(nil), d.calls...)
+}
+
+// Transactions returns the names of all initialized transactions
+func (d *RecordingDriver) Transactions() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]string(nil), d.transactions...)
+}
+
+// Names returns all names set with SetName in order
+func (d *RecordingDriver) Names() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]string(nil), d.names...)
+}
+
+// TransactionAttributes returns all recorded transaction attributes
+func (d *RecordingDriver) TransactionAttributes() []Attribute {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]Attribute(nil), d.transactionAttributes...)
+}
+
+// Segments returns all recorded segments
+func (d *RecordingDriver) Segments() []Segment {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]Segment(nil), d.segments...)
+}
+
+// SegmentAttributes returns all recorded segment attributes
+func (d *RecordingDriver) SegmentAttributes() []Attribute {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]Attribute(nil), d.segmentAttributes...)
+}
+
+// Events returns all recorded segment events in the order they were added
+func (d *RecordingDriver) Events() []Event {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]Event(nil), d.events...)
+}
+
+// Statuses returns all transaction statuses set with SetStatus in order
+func (d *RecordingDriver) Statuses() []telemetry.TransactionStatus {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]telemetry.TransactionStatus(nil), d.statuses...)
+}
+
+// Payloads returns all recorded payload attachments
+func (d *RecordingDriver) Payloads() []Payload {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]Payload(nil), d.payloads...)
+}
+
+// Logs returns all recorded log messages
+func (d *RecordingDriver) Logs() []Log {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]Log(nil), d.logs...)
+}
+
+// Links returns all recorded links
+func (d *RecordingDriver) Links() []Link {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]Link(nil), d.links...)
+}
+
+// Traces returns all traces which were set
+func (d *RecordingDriver) Traces() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]string(nil), d.traces...)
+}
+
+// TraceIDs returns all trace ids which were set
+func (d *RecordingDriver) TraceIDs() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]string(nil), d.traceIDs...)
+}
+
+// ProcessIDs returns all process ids which were set
+func (d *RecordingDriver) ProcessIDs() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]string(nil), d.processIDs...)
+}
+
+// Reset removes everything recorded so far
+func (d *RecordingDriver) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.calls = nil
+	d.transactions = nil
+	d.names = nil
+	d.transactionAttributes = nil
+	d.segments = nil
+	d.segmentAttributes = nil
+	d.events = nil
+	d.logs = nil
+	d.links = nil
+	d.statuses = nil
+	d.payloads = nil
+	d.traces = nil
+	d.traceIDs = nil
+	d.processIDs = nil
+}
+
+// AssertSegment fails the test if no segment with the provided name was started
+func (d *RecordingDriver) AssertSegment(t testing.TB, name string) {
+	t.Helper()
+
+	for _, segment := range d.Segments() {
+		if segment.Name == name {
+			return
+		}
+	}
+
+	t.Errorf("telemetrytest: segment %q was not started", name)
+}
+
+// AssertAttribute fails the test if no transaction or segment attribute with the provided key and value was added
+func (d *RecordingDriver) AssertAttribute(t testing.TB, key string, value any) {
+	t.Helper()
+
+	attributes := append(d.TransactionAttributes(), d.SegmentAttributes()...)
+	var found []any
+	for _, attribute := range attributes {
+		if attribute.Key != key {
+			continue
+		}
+
+		if reflect.DeepEqual(attribute.Value, value) {
+			return
+		}
+
+		found = append(found, attribute.Value)
+	}
+
+	if len(found) == 0 {
+		t.Errorf("telemetrytest: attribute %q was not added", key)
+		return
+	}
+
+	t.Errorf("telemetrytest: attribute %q has values %v, want %v", key, found, value)
+}
+
+// AssertLog fails the test if no message with the provided level and message was logged
+func (d *RecordingDriver) AssertLog(t testing.TB, level string, message string) {
+	t.Helper()
+
+	for _, log := range d.Logs() {
+		if log.Level == level && log.Message == message {
+			return
+		}
+	}
+
+	t.Errorf("telemetrytest: %s message %q was not logged", level, message)
+}
+
+// Start ...
+func (rt *recordingTransaction) Start(string) {
+	rt.driver.record("Start", nil)
+}
+
+// SetName ...
+func (rt *recordingTransaction) SetName(name string) error {
+	rt.driver.record("SetName", func() {
+		rt.driver.names = append(rt.driver.names, name)
+	})
+
+	return nil
+}
+
+// AddTransactionAttribute ...
+func (rt *recordingTransaction) AddTransactionAttribute(key string, value any) error {
+	rt.driver.record("AddTransactionAttribute", func() {
+		rt.driver.transactionAttributes = append(rt.driver.transactionAttributes, Attribute{Key: key, Value: value})
+	})
+
+	return nil
+}
+
+// SegmentStart ...
+func (rt *recordingTransaction) SegmentStart(segmentID string, name string) error {
+	rt.driver.record("SegmentStart", func() {
+		rt.driver.segments = append(rt.driver.segments, Segment{ID: segmentID, Name: name})
+	})
+
+	return nil
+}
+
+// SegmentStartChild ...
+func (rt *recordingTransaction) SegmentStartChild(parentID string, segmentID string, name string) error {
+	rt.driver.record("SegmentStartChild", func() {
+		rt.driver.segments = append(rt.driver.segments, Segment{ID: segmentID, ParentID: parentID, Name: name})
+	})
+
+	return nil
+}
+
+// AddSegmentAttribute ...
+func (rt *recordingTransaction) AddSegmentAttribute(segmentID string, key string, value any) error {
+	rt.driver.record("AddSegmentAttribute", func() {
+		rt.driver.segmentAttributes = append(rt.driver.segmentAttributes, Attribute{SegmentID: segmentID, Key: key, Value: value})
+	})
+
+	return nil
+}
+
+// AddSegmentAttributes ...
+func (rt *recordingTransaction) AddSegmentAttributes(segmentID string, attributes map[string]any) error {
+	rt.driver.record("AddSegmentAttributes", func() {
+		for key, value := range attributes {
+			rt.driver.segmentAttributes = append(rt.driver.segmentAttributes, Attribute{SegmentID: segmentID, Key: key, Value: value})
+		}
+	})
+
+	return nil
+}
+
+// AddSegmentEvent ...
+func (rt *recordingTransaction) AddSegmentEvent(segmentID string, name string, attributes map[string]any) error {
+	rt.driver.record("AddSegmentEvent", func() {
+		rt.driver.events = append(rt.driver.events, Event{SegmentID: segmentID, Name: name, Attributes: attributes})
+	})
+
+	return nil
+}
+
+// SegmentEnd ...
+func (rt *recordingTransaction) SegmentEnd(segmentID string) error {
+	return rt.segmentEnd("SegmentEnd", segmentID, telemetry.StatusOK)
+}
+
+// SegmentEndWithStatus ...
+func (rt *recordingTransaction) SegmentEndWithStatus(segmentID string, status telemetry.SegmentStatus) error {
+	return rt.segmentEnd("SegmentEndWithStatus", segmentID, status)
+}
+
+// segmentEnd marks the recorded segment as ended
+func (rt *recordingTransaction) segmentEnd(call string, segmentID string, status telemetry.SegmentStatus) error {
+	var err error
+	rt.driver.record(call, func() {
+		for i := range rt.driver.segments {
+			if rt.driver.segments[i].ID == segmentID {
+				rt.driver.segments[i].Ended = true
+				rt.driver.segments[i].Status = status
+				return
+			}
+		}
+
+		err = fmt.Errorf("segment %s not found", segmentID)
+	})
+
+	return err
+}
+
+// AddLink ...
+func (rt *recordingTransaction) AddLink(trace string, attributes map[string]any) error {
+	rt.driver.record("AddLink", func() {
+		rt.driver.links = append(rt.driver.links, Link{Trace: trace, Attributes: attributes})
+	})
+
+	return nil
+}
+
+// AttachPayload ...
+func (rt *recordingTransaction) AttachPayload(segmentID string, payload telemetry.Payload) error {
+	rt.driver.record("AttachPayload", func() {
+		rt.driver.payloads = append(rt.driver.payloads, Payload{SegmentID: segmentID, Payload: payload})
+	})
+
+	return nil
+}
+
+// SetStatus ...
+func (rt *recordingTransaction) SetStatus(status telemetry.TransactionStatus) error {
+	rt.driver.record("SetStatus", func() {
+		rt.driver.statuses = append(rt.driver.statuses, status)
+	})
+
+	return nil
+}
+
+// Flush ...
+func (rt *recordingTransaction) Flush() error {
+	rt.driver.record("Flush", nil)
+
+	return nil
+}
+
+// Done ...
+func (rt *recordingTransaction) Done() error {
+	rt.driver.record("Done", nil)
+
+	return nil
+}
+
+// Info ...
+func (rt *recordingTransaction) Info(segmentID string, rc io.ReadCloser) error {
+	return rt.log("Info", "info", segmentID, rc)
+}
+
+// Warn ...
+func (rt *recordingTransaction) Warn(segmentID string, rc io.ReadCloser) error {
+	return rt.log("Warn", "warn", segmentID, rc)
+}
+
+// Error ...
+func (rt *recordingTransaction) Error(segmentID string, rc io.ReadCloser) error {
+	return rt.log("Error", "error", segmentID, rc)
+}
+
+// Debug ...
+func (rt *recordingTransaction) Debug(segmentID string, rc io.ReadCloser) error {
+	return rt.log("Debug", "debug", segmentID, rc)
+}
+
+// log drains the message and records it
+func (rt *recordingTransaction) log(call string, level string, segmentID string, rc io.ReadCloser) error {
+	defer rc.Close()
+
+	msg, err := io.ReadAll(rc)
+	if err != nil {
+		return err
+	}
+
+	rt.driver.record(call, func() {
+		rt.driver.logs = append(rt.driver.logs, Log{Level: level, SegmentID: segmentID, Message: string(msg)})
+	})
+
+	return nil
+}
+
+// InfoFields ...
+func (rt *recordingTransaction) InfoFields(segmentID string, fields map[string]any) error {
+	rt.driver.record("InfoFields", func() {
+		rt.driver.logs = append(rt.driver.logs, Log{Level: "info", SegmentID: segmentID, Message: fieldMessage(fields, telemetry.FieldMessage), Fields: fields})
+	})
+
+	return nil
+}
+
+// ErrorFields ...
+func (rt *recordingTransaction) ErrorFields(segmentID string, fields map[string]any) error {
+	rt.driver.record("ErrorFields", func() {
+		rt.driver.logs = append(rt.driver.logs, Log{Level: "error", SegmentID: segmentID, Message: fieldMessage(fields, telemetry.FieldError), Fields: fields})
+	})
+
+	return nil
+}
+
+// fieldMessage returns the string under the key, the message of logs with fields
+func fieldMessage(fields map[string]any, key string) string {
+	message, _ := fields[key].(string)
+
+	return message
+}
+
+// CreateTrace ...
+func (rt *recordingTransaction) CreateTrace() (string, error) {
+	rt.driver.record("CreateTrace", nil)
+
+	return uuid.NewString(), nil
+}
+
+// SetTrace ...
+func (rt *recordingTransaction) SetTrace(trace string) error {
+	rt.driver.record("SetTrace", func() {
+		rt.driver.traces = append(rt.driver.traces, trace)
+	})
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	rt.trace = trace
+	rt.traceID = trace
+
+	return nil
+}
+
+// Trace ...
+func (rt *recordingTransaction) Trace() (string, error) {
+	rt.driver.record("Trace", nil)
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	return rt.trace, nil
+}
+
+// TraceID ...
+func (rt *recordingTransaction) TraceID() (string, error) {
+	rt.driver.record("TraceID", nil)
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	return rt.traceID, nil
+}
+
+// SetTraceID ...
+func (rt *recordingTransaction) SetTraceID(traceID string) error {
+	rt.driver.record("SetTraceID", func() {
+		rt.driver.traceIDs = append(rt.driver.traceIDs, traceID)
+	})
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	rt.traceID = traceID
+
+	return nil
+}
+
+// Erase ...
+func (rt *recordingTransaction) Erase() {
+	rt.driver.record("Erase", nil)
+}
+
+// CreateProcessID ...
+func (rt *recordingTransaction) CreateProcessID() (string, error) {
+	rt.driver.record("CreateProcessID", nil)
+
+	return uuid.NewString(), nil
+}
+
+// SetProcessID ...
+func (rt *recordingTransaction) SetProcessID(processID string) error {
+	rt.driver.record("SetProcessID", func() {
+		rt.driver.processIDs = append(rt.driver.processIDs, processID)
+	})
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	rt.processID = processID
+
+	return nil
+}
+
+// ProcessID ...
+func (rt *recordingTransaction) ProcessID() (string, error) {
+	rt.driver.record("ProcessID", nil)
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	return rt.processID, nil
+}
@@ -0,0 +1,90 @@
+package telemetrytest_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/telemetrytest"
+)
+
+// failureRecorder records the failures of the assertions instead of failing the test
+type failureRecorder struct {
+	testing.TB
+	failures []string
+}
+
+// Helper ...
+func (fr *failureRecorder) Helper() {}
+
+// Errorf records the failure
+func (fr *failureRecorder) Errorf(format string, args ...any) {
+	fr.failures = append(fr.failures, fmt.Sprintf(format, args...))
+}
+
+func TestRecordingDriver(t *testing.T) {
+	tel, recorder := telemetrytest.NewTelemetry(t)
+
+	transaction, err := tel.Start("transaction")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transaction.AddTransactionAttribute("tenant", "42")
+	segmentID := transaction.SegmentStart("segment")
+	transaction.AddSegmentAttribute(segmentID, "rows", 3)
+	msg := "message"
+	transaction.Info(segmentID, &msg)
+	transaction.SegmentEnd(segmentID)
+
+	err = transaction.Done()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder.AssertSegment(t, "segment")
+	recorder.AssertAttribute(t, "tenant", "42")
+	recorder.AssertAttribute(t, "rows", 3)
+	recorder.AssertLog(t, "info", msg)
+
+	segments := recorder.Segments()
+	if len(segments) != 1 || segments[0].ID != segmentID || !segments[0].Ended || segments[0].Status != telemetry.StatusOK {
+		t.Fatalf("unexpected segments %+v", segments)
+	}
+
+	if transactions := recorder.Transactions(); len(transactions) != 1 || transactions[0] != "transaction" {
+		t.Fatalf("unexpected transactions %v", transactions)
+	}
+
+	if processIDs := recorder.ProcessIDs(); len(processIDs) != 1 || processIDs[0] == "" {
+		t.Fatalf("unexpected process ids %v", processIDs)
+	}
+
+	recorder.Reset()
+	if calls := recorder.Calls(); len(calls) != 0 {
+		t.Fatalf("expected no calls after Reset, got %v", calls)
+	}
+}
+
+func TestRecordingDriverAssertionsFail(t *testing.T) {
+	recorder := telemetrytest.New()
+	transaction, err := recorder.InitializeTransaction("transaction")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = transaction.AddTransactionAttribute("tenant", "42")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fr := &failureRecorder{TB: t}
+	recorder.AssertSegment(fr, "segment")
+	recorder.AssertAttribute(fr, "tenant", "43")
+	recorder.AssertAttribute(fr, "missing", "value")
+	recorder.AssertLog(fr, "info", "message")
+
+	if len(fr.failures) != 4 {
+		t.Fatalf("expected 4 failures, got %v", fr.failures)
+	}
+}
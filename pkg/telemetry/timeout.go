@@ -0,0 +1,342 @@
+package telemetry
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultDriverTimeout is used by NewTimeoutDriver if the provided timeout is not positive
+const DefaultDriverTimeout = time.Second
+
+// ErrDriverTimeout is returned by the transactions of a TimeoutDriver if a call exceeds the timeout
+type ErrDriverTimeout struct {
+	Function string
+	Timeout  time.Duration
+}
+
+// Error returns the message with function name and timeout
+func (e ErrDriverTimeout) Error() string {
+	return fmt.Sprintf("driver call %s exceeded timeout of %s", e.Function, e.Timeout)
+}
+
+// TimeoutDriver wraps a driver and bounds every call of its transactions by a timeout.
+// Unlike the AsyncDriver the calls are still applied synchronously, but a call exceeding the timeout
+// returns ErrDriverTimeout and lets the caller proceed. The call itself is not cancelled, it may still
+// complete in the background after the timeout fired, concurrently to the following calls
+type TimeoutDriver struct {
+	inner    Driver
+	timeout  time.Duration
+	timedOut atomic.Uint64
+}
+
+// timeoutTransaction bounds the calls of the transaction of the wrapped driver
+type timeoutTransaction struct {
+	inner  Transaction
+	driver *TimeoutDriver
+}
+
+// NewTimeoutDriver returns a *TimeoutDriver which bounds the calls to inner by timeout
+func NewTimeoutDriver(inner Driver, timeout time.Duration) Driver {
+	if timeout <= 0 {
+		timeout = DefaultDriverTimeout
+	}
+
+	return &TimeoutDriver{
+		inner:   inner,
+		timeout: timeout,
+	}
+}
+
+// TimedOut returns the number of calls which exceeded the timeout
+func (d *TimeoutDriver) TimedOut() uint64 {
+	return d.timedOut.Load()
+}
+
+// InitializeTransaction initializes the transaction of the wrapped driver within the timeout
+func (d *TimeoutDriver) InitializeTransaction(name string) (Transaction, error) {
+	transaction, err := timeoutCall(d, "InitializeTransaction", func() (Transaction, error) {
+		return d.inner.InitializeTransaction(name)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &timeoutTransaction{
+		inner:  transaction,
+		driver: d,
+	}, nil
+}
+
+// timeoutCall runs op on its own goroutine and returns ErrDriverTimeout if it does not return within the timeout.
+// In synchronous mode op runs on the caller without a timeout
+func timeoutCall[T any](d *TimeoutDriver, function string, op func() (T, error)) (T, error) {
+	type result struct {
+		value T
+		err   error
+	}
+
+	if Synchronous() {
+		var r result
+		r.err = safeCall(func() (err error) {
+			r.value, err = op()
+			return err
+		})
+
+		return r.value, r.err
+	}
+
+	results := make(chan result, 1)
+	go func() {
+		var r result
+		r.err = safeCall(func() (err error) {
+			r.value, err = op()
+			return err
+		})
+		results <- r
+	}()
+
+	timer := time.NewTimer(d.timeout)
+	defer timer.Stop()
+
+	select {
+	case r := <-results:
+		return r.value, r.err
+	case <-timer.C:
+		d.timedOut.Add(1)
+
+		var zero T
+		return zero, ErrDriverTimeout{Function: function, Timeout: d.timeout}
+	}
+}
+
+// call runs op within the timeout
+func (tt *timeoutTransaction) call(function string, op func() error) error {
+	_, err := timeoutCall(tt.driver, function, func() (struct{}, error) {
+		return struct{}{}, op()
+	})
+
+	return err
+}
+
+// Start starts the wrapped transaction with the name within the timeout
+func (tt *timeoutTransaction) Start(name string) {
+	tt.call("Start", func() error {
+		tt.inner.Start(name)
+		return nil
+	})
+}
+
+// SetName renames the wrapped transaction within the timeout
+func (tt *timeoutTransaction) SetName(name string) error {
+	return tt.call("SetName", func() error {
+		return tt.inner.SetName(name)
+	})
+}
+
+// AddTransactionAttribute adds the attribute to the wrapped transaction within the timeout
+func (tt *timeoutTransaction) AddTransactionAttribute(key string, value any) error {
+	return tt.call("AddTransactionAttribute", func() error {
+		return tt.inner.AddTransactionAttribute(key, value)
+	})
+}
+
+// SegmentStart starts the segment within the timeout
+func (tt *timeoutTransaction) SegmentStart(segmentID string, name string) error {
+	return tt.call("SegmentStart", func() error {
+		return tt.inner.SegmentStart(segmentID, name)
+	})
+}
+
+// SegmentStartChild starts the segment as child of the parent segment within the timeout
+func (tt *timeoutTransaction) SegmentStartChild(parentID string, segmentID string, name string) error {
+	return tt.call("SegmentStartChild", func() error {
+		return tt.inner.SegmentStartChild(parentID, segmentID, name)
+	})
+}
+
+// AddSegmentAttribute adds the attribute to the segment within the timeout
+func (tt *timeoutTransaction) AddSegmentAttribute(segmentID string, key string, value any) error {
+	return tt.call("AddSegmentAttribute", func() error {
+		return tt.inner.AddSegmentAttribute(segmentID, key, value)
+	})
+}
+
+// AddSegmentAttributes adds a copy of the attributes to the segment within the timeout
+func (tt *timeoutTransaction) AddSegmentAttributes(segmentID string, attributes map[string]any) error {
+	attributes = maps.Clone(attributes)
+
+	return tt.call("AddSegmentAttributes", func() error {
+		return tt.inner.AddSegmentAttributes(segmentID, attributes)
+	})
+}
+
+// AddSegmentEvent adds the event with a copy of the attributes to the segment within the timeout
+func (tt *timeoutTransaction) AddSegmentEvent(segmentID string, name string, attributes map[string]any) error {
+	attributes = maps.Clone(attributes)
+
+	return tt.call("AddSegmentEvent", func() error {
+		return tt.inner.AddSegmentEvent(segmentID, name, attributes)
+	})
+}
+
+// SegmentEnd ends the segment within the timeout
+func (tt *timeoutTransaction) SegmentEnd(segmentID string) error {
+	return tt.call("SegmentEnd", func() error {
+		return tt.inner.SegmentEnd(segmentID)
+	})
+}
+
+// SegmentEndWithStatus ends the segment with the status within the timeout
+func (tt *timeoutTransaction) SegmentEndWithStatus(segmentID string, status SegmentStatus) error {
+	return tt.call("SegmentEndWithStatus", func() error {
+		return tt.inner.SegmentEndWithStatus(segmentID, status)
+	})
+}
+
+// AddLink links the trace with a copy of the attributes within the timeout
+func (tt *timeoutTransaction) AddLink(trace string, attributes map[string]any) error {
+	attributes = maps.Clone(attributes)
+
+	return tt.call("AddLink", func() error {
+		return tt.inner.AddLink(trace, attributes)
+	})
+}
+
+// SetStatus sets the status of the wrapped transaction within the timeout
+func (tt *timeoutTransaction) SetStatus(status TransactionStatus) error {
+	return tt.call("SetStatus", func() error {
+		return tt.inner.SetStatus(status)
+	})
+}
+
+// AttachPayload attaches the payload to the segment within the timeout
+func (tt *timeoutTransaction) AttachPayload(segmentID string, payload Payload) error {
+	return tt.call("AttachPayload", func() error {
+		return attachPayload(tt.inner, segmentID, payload)
+	})
+}
+
+// Flush flushes the wrapped transaction within the timeout
+func (tt *timeoutTransaction) Flush() error {
+	return tt.call("Flush", tt.inner.Flush)
+}
+
+// Done finishes the wrapped transaction within the timeout
+func (tt *timeoutTransaction) Done() error {
+	return tt.call("Done", tt.inner.Done)
+}
+
+// Info logs the message within the timeout
+func (tt *timeoutTransaction) Info(segmentID string, rc io.ReadCloser) error {
+	return tt.call("Info", func() error {
+		return tt.inner.Info(segmentID, rc)
+	})
+}
+
+// Warn logs the message as warning within the timeout
+func (tt *timeoutTransaction) Warn(segmentID string, rc io.ReadCloser) error {
+	return tt.call("Warn", func() error {
+		return tt.inner.Warn(segmentID, rc)
+	})
+}
+
+// Error logs the message as error within the timeout
+func (tt *timeoutTransaction) Error(segmentID string, rc io.ReadCloser) error {
+	return tt.call("Error", func() error {
+		return tt.inner.Error(segmentID, rc)
+	})
+}
+
+// Debug logs the message as debug message within the timeout
+func (tt *timeoutTransaction) Debug(segmentID string, rc io.ReadCloser) error {
+	return tt.call("Debug", func() error {
+		return tt.inner.Debug(segmentID, rc)
+	})
+}
+
+// InfoFields logs a copy of the fields within the timeout
+func (tt *timeoutTransaction) InfoFields(segmentID string, fields map[string]any) error {
+	fields = maps.Clone(fields)
+
+	return tt.call("InfoFields", func() error {
+		return tt.inner.InfoFields(segmentID, fields)
+	})
+}
+
+// ErrorFields logs a copy of the fields as error within the timeout
+func (tt *timeoutTransaction) ErrorFields(segmentID string, fields map[string]any) error {
+	fields = maps.Clone(fields)
+
+	return tt.call("ErrorFields", func() error {
+		return tt.inner.ErrorFields(segmentID, fields)
+	})
+}
+
+// CreateTrace creates the trace of the wrapped transaction within the timeout
+func (tt *timeoutTransaction) CreateTrace() (string, error) {
+	return timeoutCall(tt.driver, "CreateTrace", tt.inner.CreateTrace)
+}
+
+// SetTrace sets the trace of the wrapped transaction within the timeout
+func (tt *timeoutTransaction) SetTrace(trace string) error {
+	return tt.call("SetTrace", func() error {
+		return tt.inner.SetTrace(trace)
+	})
+}
+
+// Trace returns the trace of the wrapped transaction within the timeout
+func (tt *timeoutTransaction) Trace() (string, error) {
+	return timeoutCall(tt.driver, "Trace", tt.inner.Trace)
+}
+
+// TraceID returns the trace id of the wrapped transaction within the timeout
+func (tt *timeoutTransaction) TraceID() (string, error) {
+	return timeoutCall(tt.driver, "TraceID", tt.inner.TraceID)
+}
+
+// SetTraceID sets the trace id of the wrapped transaction within the timeout
+func (tt *timeoutTransaction) SetTraceID(traceID string) error {
+	return tt.call("SetTraceID", func() error {
+		return tt.inner.SetTraceID(traceID)
+	})
+}
+
+// Erase erases the wrapped transaction within the timeout
+func (tt *timeoutTransaction) Erase() {
+	tt.call("Erase", func() error {
+		tt.inner.Erase()
+		return nil
+	})
+}
+
+// CreateProcessID creates the process id of the wrapped transaction within the timeout
+func (tt *timeoutTransaction) CreateProcessID() (string, error) {
+	return timeoutCall(tt.driver, "CreateProcessID", tt.inner.CreateProcessID)
+}
+
+// SetProcessID sets the process id of the wrapped transaction within the timeout
+func (tt *timeoutTransaction) SetProcessID(processID string) error {
+	return tt.call("SetProcessID", func() error {
+		return tt.inner.SetProcessID(processID)
+	})
+}
+
+// ProcessID returns the process id of the wrapped transaction within the timeout
+func (tt *timeoutTransaction) ProcessID() (string, error) {
+	return timeoutCall(tt.driver, "ProcessID", tt.inner.ProcessID)
+}
+
+// SpanID returns the span id of the wrapped transaction within the timeout, see SpanIDProvider
+func (tt *timeoutTransaction) SpanID(segmentID string) (string, error) {
+	provider, ok := tt.inner.(SpanIDProvider)
+	if !ok {
+		return "", ErrNoSpanID
+	}
+
+	return timeoutCall(tt.driver, "SpanID", func() (string, error) {
+		return provider.SpanID(segmentID)
+	})
+}
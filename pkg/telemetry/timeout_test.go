@@ -0,0 +1,93 @@
+package telemetry_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/telemetrytest"
+)
+
+// slowDriver is a recording driver whose AddSegmentAttributes and InfoFields wait for release before reading the map
+type slowDriver struct {
+	*telemetrytest.RecordingDriver
+	release chan struct{}
+	done    chan struct{}
+}
+
+// slowTransaction reads the maps after the timeout fired
+type slowTransaction struct {
+	telemetry.Transaction
+	driver *slowDriver
+}
+
+// InitializeTransaction returns a slow recording transaction
+func (d *slowDriver) InitializeTransaction(name string) (telemetry.Transaction, error) {
+	transaction, err := d.RecordingDriver.InitializeTransaction(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &slowTransaction{Transaction: transaction, driver: d}, nil
+}
+
+// AddSegmentAttributes waits for release and reads the attributes
+func (st *slowTransaction) AddSegmentAttributes(segmentID string, attributes map[string]any) error {
+	defer func() { st.driver.done <- struct{}{} }()
+	<-st.driver.release
+
+	return st.Transaction.AddSegmentAttributes(segmentID, attributes)
+}
+
+// InfoFields waits for release and reads the fields
+func (st *slowTransaction) InfoFields(segmentID string, fields map[string]any) error {
+	defer func() { st.driver.done <- struct{}{} }()
+	<-st.driver.release
+
+	return st.Transaction.InfoFields(segmentID, fields)
+}
+
+func TestTimeoutDriverClonesMaps(t *testing.T) {
+	inner := &slowDriver{
+		RecordingDriver: telemetrytest.New(),
+		release:         make(chan struct{}),
+		done:            make(chan struct{}, 2),
+	}
+
+	transaction, err := telemetry.NewTimeoutDriver(inner, time.Millisecond).InitializeTransaction("timeout")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = transaction.SegmentStart("segment", "segment")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	attributes := map[string]any{"key": "before"}
+	fields := map[string]any{telemetry.FieldMessage: "before"}
+
+	var timeoutErr telemetry.ErrDriverTimeout
+	err = transaction.AddSegmentAttributes("segment", attributes)
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("expected ErrDriverTimeout, got %v", err)
+	}
+
+	err = transaction.InfoFields("segment", fields)
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("expected ErrDriverTimeout, got %v", err)
+	}
+
+	close(inner.release)
+	for i := 0; i < 100; i++ {
+		attributes["key"] = i
+		fields[telemetry.FieldMessage] = i
+	}
+
+	<-inner.done
+	<-inner.done
+
+	inner.AssertAttribute(t, "key", "before")
+	inner.AssertLog(t, "info", "before")
+}
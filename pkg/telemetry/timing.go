@@ -0,0 +1,79 @@
+package telemetry
+
+import (
+	"sync"
+	"time"
+)
+
+// DurationAttribute is the transaction attribute holding the duration in milliseconds, added on Done
+const DurationAttribute = "duration_ms"
+
+// SetMinSegmentDuration sets the minimum segment duration of the default instance
+func SetMinSegmentDuration(d time.Duration) {
+	defaultTelemetry.SetMinSegmentDuration(d)
+}
+
+// SetMinSegmentDuration drops segments which end with StatusOK faster than d, e.g. trivial segments in hot loops.
+// The drivers receive SegmentEndWithStatus with StatusDropped instead of SegmentEnd and discard the segment
+// with its attributes and logs, the segment is left out of the snapshot. Segments ending with another status are kept.
+// Streaming drivers like stdout have written the segment already and only mark it as dropped. The default zero records every segment
+func (t *Telemetry) SetMinSegmentDuration(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.minSegmentDuration = d
+}
+
+// segmentDurationThreshold returns the minimum segment duration
+func (t *Telemetry) segmentDurationThreshold() time.Duration {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.minSegmentDuration
+}
+
+// transactionTiming holds the start and end of a transaction
+type transactionTiming struct {
+	mu    sync.Mutex
+	start time.Time
+	end   time.Time
+	now   func() time.Time
+}
+
+// begin records the start of the transaction
+func (tt *transactionTiming) begin() {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	tt.start = tt.now()
+}
+
+// stop records the end of the transaction once and returns the elapsed time
+func (tt *transactionTiming) stop() time.Duration {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	if tt.end.IsZero() {
+		tt.end = tt.now()
+	}
+
+	return tt.end.Sub(tt.start)
+}
+
+// elapsed returns the time since the start or the duration until the end if the transaction is done
+func (tt *transactionTiming) elapsed() time.Duration {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	if tt.end.IsZero() {
+		return tt.now().Sub(tt.start)
+	}
+
+	return tt.end.Sub(tt.start)
+}
+
+// Elapsed returns the time since the transaction started.
+// After Done it returns the duration of the transaction
+func (tc *TransactionContainer) Elapsed() time.Duration {
+	return tc.timing.elapsed()
+}
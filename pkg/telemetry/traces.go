@@ -0,0 +1,175 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// TraceKeyAttribute is the transaction attribute holding the key of a trace created with NewTrace
+const TraceKeyAttribute = "trace.key"
+
+// ErrTraceNotFound is returned by the ForTrace methods for a key without a trace created by NewTrace
+var ErrTraceNotFound = errors.New("telemetry trace not found")
+
+// traceSet holds the containers of the named traces of a transaction container
+type traceSet struct {
+	mu         sync.Mutex
+	keys       []string
+	containers map[string]*TransactionContainer
+}
+
+// get returns the container of the trace with the key
+func (ts *traceSet) get(key string) (*TransactionContainer, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	container, ok := ts.containers[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrTraceNotFound, key)
+	}
+
+	return container, nil
+}
+
+// take removes and returns the containers of all traces in the order they were created
+func (ts *traceSet) take() []*TransactionContainer {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	containers := make([]*TransactionContainer, 0, len(ts.keys))
+	for _, key := range ts.keys {
+		containers = append(containers, ts.containers[key])
+	}
+
+	ts.keys = nil
+	ts.containers = nil
+
+	return containers
+}
+
+// NewTrace creates a trace under the key, e.g. for every item of a batch, and returns it. A key which already has
+// a trace returns the existing one. Every trace is backed by its own transactions on the drivers of the container,
+// which share the name, the process id and the resource attributes of the container and carry the key as
+// TraceKeyAttribute. Use the ForTrace methods or TraceContainer to record onto the trace.
+// Done of the container ends the transactions of all its traces. The traces are not part of the snapshot
+func (tc *TransactionContainer) NewTrace(key string) (string, error) {
+	tc.traces.mu.Lock()
+	defer tc.traces.mu.Unlock()
+
+	if container, ok := tc.traces.containers[key]; ok {
+		return container.Trace()
+	}
+
+	processID, err := tc.rawProcessID()
+	if err != nil {
+		return "", ErrorProcessID{
+			err: err,
+		}
+	}
+
+	tc.record.mu.Lock()
+	name := tc.record.name
+	tc.record.mu.Unlock()
+
+	tc.mu.RLock()
+	driverNames := tc.driverOrder()
+	tc.mu.RUnlock()
+
+	container, err := tc.telemetry.initialize(name, driverNames, tc.traceDrivers, tc.sampled)
+	if err != nil {
+		return "", fmt.Errorf("could not create trace %s: %w", key, err)
+	}
+
+	err = container.SetProcessID(processID)
+	if err != nil {
+		return "", ErrorProcessID{
+			err: err,
+		}
+	}
+
+	trace, err := container.StartTracing()
+	if err != nil {
+		return "", err
+	}
+
+	container.begin(name)
+	container.addResourceAttributes()
+	container.AddTransactionAttribute(TraceKeyAttribute, key)
+
+	if tc.traces.containers == nil {
+		tc.traces.containers = make(map[string]*TransactionContainer)
+	}
+
+	tc.traces.keys = append(tc.traces.keys, key)
+	tc.traces.containers[key] = &container
+
+	return trace, nil
+}
+
+// TraceContainer returns the container of the trace created with NewTrace under the key,
+// for the methods without a ForTrace variant
+func (tc *TransactionContainer) TraceContainer(key string) (*TransactionContainer, bool) {
+	container, err := tc.traces.get(key)
+
+	return container, err == nil
+}
+
+// SegmentStartForTrace starts a segment on the trace with the key and returns its id
+func (tc *TransactionContainer) SegmentStartForTrace(key string, name string) (string, error) {
+	container, err := tc.traces.get(key)
+	if err != nil {
+		return "", err
+	}
+
+	return container.SegmentStartE(name)
+}
+
+// SegmentEndForTrace ends a segment of the trace with the key with StatusOK
+func (tc *TransactionContainer) SegmentEndForTrace(key string, segmentID string) error {
+	container, err := tc.traces.get(key)
+	if err != nil {
+		return err
+	}
+
+	return container.SegmentEndWithStatus(segmentID, StatusOK)
+}
+
+// InfoForTrace logs the message on the trace with the key. If segmentID is empty, it is logged on the transaction of the trace
+func (tc *TransactionContainer) InfoForTrace(key string, segmentID string, msg *string) error {
+	container, err := tc.traces.get(key)
+	if err != nil {
+		return err
+	}
+
+	container.Info(segmentID, msg)
+
+	return nil
+}
+
+// ErrorForTrace logs the error on the trace with the key. If segmentID is empty, it is logged on the transaction of the trace
+func (tc *TransactionContainer) ErrorForTrace(key string, segmentID string, err *error) error {
+	container, traceErr := tc.traces.get(key)
+	if traceErr != nil {
+		return traceErr
+	}
+
+	container.Error(segmentID, err)
+
+	return nil
+}
+
+// doneTraces ends the transactions of all traces created with NewTrace
+func (tc *TransactionContainer) doneTraces(ctx context.Context) error {
+	var ew ErrorWrapper
+
+	for _, container := range tc.traces.take() {
+		err := container.DoneContext(ctx)
+		if err != nil {
+			ew.Add(err)
+		}
+	}
+
+	return ew.Error()
+}
@@ -0,0 +1,96 @@
+package telemetry
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrEmptyTrace is returned by SetTrace for an empty trace
+var ErrEmptyTrace = errors.New("telemetry trace is empty")
+
+// ErrEmptyProcessID is returned by SetProcessID for an empty process id
+var ErrEmptyProcessID = errors.New("telemetry process id is empty")
+
+// TraceValidator returns an error if the trace has an invalid format for the trace drivers
+type TraceValidator func(trace string) error
+
+// SetTraceValidator sets the trace validator of the default instance
+func SetTraceValidator(validator TraceValidator) {
+	defaultTelemetry.SetTraceValidator(validator)
+}
+
+// SetTraceValidator sets the validator SetTrace of the transaction containers checks the trace with
+// before passing it to the drivers, e.g. oteldriver.ValidateTrace. A nil validator only rejects empty traces
+func (t *Telemetry) SetTraceValidator(validator TraceValidator) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.traceValidator = validator
+}
+
+// validateTrace returns ErrEmptyTrace for an empty trace and the error of the trace validator otherwise
+func (t *Telemetry) validateTrace(trace string) error {
+	if trace == "" {
+		return ErrEmptyTrace
+	}
+
+	t.mu.RLock()
+	validator := t.traceValidator
+	t.mu.RUnlock()
+
+	if validator == nil {
+		return nil
+	}
+
+	return validator(trace)
+}
+
+// Validate checks the configuration of the default instance
+func Validate() error {
+	return defaultTelemetry.Validate()
+}
+
+// Validate checks that the configuration is coherent without starting a transaction, meant to be called at startup
+// after the configuration. It returns all problems joined together:
+//   - ErrNoDriverConfigured if no driver is loaded
+//   - ErrDriverNotRegistered for every loaded driver which is not registered
+//   - ErrTraceDriverNotSet if no trace driver is set and ErrTraceDriverNotLoaded for every trace driver not loaded
+//   - ErrNilSampler if SetSampler was called with a nil sampler
+//   - the errors of the resource attributes rejected by SetResourceAttributes
+func (t *Telemetry) Validate() error {
+	var ew ErrorWrapper
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if len(t.loadedDriver) == 0 {
+		ew.Add(ErrNoDriverConfigured)
+	}
+
+	for _, name := range t.loadedDriver {
+		if _, ok := t.registeredDriver[name]; !ok {
+			ew.Add(ErrDriverNotRegistered{Name: name})
+		}
+	}
+
+	if len(t.traceDrivers) == 0 {
+		ew.Add(ErrTraceDriverNotSet)
+	}
+
+	for _, name := range t.traceDrivers {
+		if !slices.Contains(t.loadedDriver, name) {
+			ew.Add(ErrTraceDriverNotLoaded{Name: name})
+		}
+	}
+
+	if t.samplerSet && t.sampler == nil {
+		ew.Add(ErrNilSampler)
+	}
+
+	if t.resourceAttributesErr != nil {
+		ew.Add(fmt.Errorf("invalid resource attributes: %w", t.resourceAttributesErr))
+	}
+
+	return ew.Error()
+}
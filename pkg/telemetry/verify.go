@@ -0,0 +1,173 @@
+package telemetry
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrEmptyID is the violation of CreateTrace and CreateProcessID returning an empty id without an error
+var ErrEmptyID = errors.New("empty id returned")
+
+// ErrDriverContract is returned by VerifyDriver for a driver method violating its contract
+type ErrDriverContract struct {
+	Function string
+	Err      error
+}
+
+// Error returns the message with the function name
+func (e ErrDriverContract) Error() string {
+	return fmt.Sprintf("telemetry driver contract violated Function: %s | Error: %v", e.Function, e.Err)
+}
+
+// Unwrap returns the violation
+func (e ErrDriverContract) Unwrap() error {
+	return e.Err
+}
+
+// VerifyDriver initializes throwaway transactions of d and calls every method of the Transaction interface,
+// so custom drivers can check in their tests that they behave as the transaction container expects:
+//   - InitializeTransaction returns a transaction without an error
+//   - no method panics
+//   - every method returning an error returns nil for valid input, i.e. segment ids of started segments
+//     and the empty segment id for logs of the transaction
+//   - CreateTrace and CreateProcessID return non-empty values. Drivers which are never used as trace driver,
+//     e.g. the promdriver, may ignore a violation of CreateTrace matching ErrEmptyID
+//   - SetTrace, SetProcessID and AddLink accept the values returned by CreateTrace and CreateProcessID of another transaction
+//   - Erase may be called after Done
+//
+// All violations are returned together, each as ErrDriverContract
+func VerifyDriver(d Driver) error {
+	var ew ErrorWrapper
+
+	transaction, err := verifyTransaction(d)
+	if err != nil {
+		return err
+	}
+
+	other, err := verifyTransaction(d)
+	if err != nil {
+		return err
+	}
+
+	check := func(function string, fn func() error) {
+		err := safeCall(fn)
+		if err != nil {
+			ew.Add(ErrDriverContract{Function: function, Err: err})
+		}
+	}
+
+	checkID := func(function string, fn func() (string, error)) string {
+		var id string
+		check(function, func() (err error) {
+			id, err = fn()
+			if err == nil && id == "" {
+				err = ErrEmptyID
+			}
+
+			return err
+		})
+
+		return id
+	}
+
+	checkLog := func(function string, fn func(io.ReadCloser) error) {
+		check(function, func() error {
+			return fn(io.NopCloser(strings.NewReader("telemetry driver verification")))
+		})
+	}
+
+	trace := checkID("CreateTrace", other.CreateTrace)
+	processID := checkID("CreateProcessID", other.CreateProcessID)
+
+	check("Start", func() error {
+		transaction.Start("verify")
+		return nil
+	})
+	check("SetName", func() error { return transaction.SetName("verify.renamed") })
+	check("AddTransactionAttribute", func() error { return transaction.AddTransactionAttribute("verify.attribute", "value") })
+
+	if trace != "" {
+		check("SetTrace", func() error { return transaction.SetTrace(trace) })
+		check("AddLink", func() error { return transaction.AddLink(trace, map[string]any{"verify.attribute": "value"}) })
+	}
+
+	if processID != "" {
+		check("SetProcessID", func() error { return transaction.SetProcessID(processID) })
+	}
+
+	check("Trace", func() error {
+		_, err := transaction.Trace()
+		return err
+	})
+	check("TraceID", func() error {
+		_, err := transaction.TraceID()
+		return err
+	})
+	check("ProcessID", func() error {
+		_, err := transaction.ProcessID()
+		return err
+	})
+
+	const (
+		parentID = "verify-parent"
+		childID  = "verify-child"
+	)
+
+	check("SegmentStart", func() error { return transaction.SegmentStart(parentID, "verify.parent") })
+	check("SegmentStartChild", func() error { return transaction.SegmentStartChild(parentID, childID, "verify.child") })
+	check("AddSegmentAttribute", func() error { return transaction.AddSegmentAttribute(childID, "verify.attribute", 1) })
+	check("AddSegmentAttributes", func() error {
+		return transaction.AddSegmentAttributes(childID, map[string]any{"verify.bool": true, "verify.float": 1.5})
+	})
+	check("AddSegmentEvent", func() error {
+		return transaction.AddSegmentEvent(childID, "verify.event", map[string]any{"verify.attribute": "value"})
+	})
+
+	for _, segmentID := range []string{childID, ""} {
+		checkLog("Debug", func(rc io.ReadCloser) error { return transaction.Debug(segmentID, rc) })
+		checkLog("Info", func(rc io.ReadCloser) error { return transaction.Info(segmentID, rc) })
+		checkLog("Warn", func(rc io.ReadCloser) error { return transaction.Warn(segmentID, rc) })
+		checkLog("Error", func(rc io.ReadCloser) error { return transaction.Error(segmentID, rc) })
+		check("InfoFields", func() error {
+			return transaction.InfoFields(segmentID, map[string]any{FieldMessage: "verify", "verify.attribute": "value"})
+		})
+		check("ErrorFields", func() error {
+			return transaction.ErrorFields(segmentID, map[string]any{FieldMessage: "verify", "verify.attribute": "value"})
+		})
+	}
+
+	check("SegmentEnd", func() error { return transaction.SegmentEnd(childID) })
+	check("SegmentEndWithStatus", func() error { return transaction.SegmentEndWithStatus(parentID, StatusError) })
+	check("SetStatus", func() error { return transaction.SetStatus(TransactionStatusOK) })
+	check("Flush", transaction.Flush)
+	check("Done", transaction.Done)
+	check("Erase", func() error {
+		transaction.Erase()
+		return nil
+	})
+
+	check("Done", other.Done)
+
+	return ew.Error()
+}
+
+// verifyTransaction initializes a throwaway transaction of d for VerifyDriver
+func verifyTransaction(d Driver) (Transaction, error) {
+	var transaction Transaction
+
+	err := safeCall(func() (err error) {
+		transaction, err = d.InitializeTransaction("verify")
+		if err == nil && transaction == nil {
+			err = errors.New("nil transaction returned")
+		}
+
+		return err
+	})
+	if err != nil {
+		return nil, ErrDriverContract{Function: "InitializeTransaction", Err: err}
+	}
+
+	return transaction, nil
+}
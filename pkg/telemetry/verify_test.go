@@ -0,0 +1,26 @@
+package telemetry_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/telemetrytest"
+)
+
+func TestVerifyDriver(t *testing.T) {
+	drivers := map[string]telemetry.Driver{
+		"noop":      telemetry.NoopDriver{},
+		"stdout":    telemetry.NewStdoutDriver(io.Discard),
+		"recording": telemetrytest.New(),
+	}
+
+	for name, driver := range drivers {
+		t.Run(name, func(t *testing.T) {
+			err := telemetry.VerifyDriver(driver)
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
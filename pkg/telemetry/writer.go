@@ -0,0 +1,58 @@
+package telemetry
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// logWriter logs every written line as info
+type logWriter struct {
+	mu        sync.Mutex
+	tc        *TransactionContainer
+	segmentID string
+	buf       bytes.Buffer
+}
+
+// LogWriter returns a writer which logs every written line as info in the registered driver transactions.
+// Lines are split at newlines, a partial last line is logged on Close.
+// If segmentID is empty, the lines will be logged directly on the transaction
+func (tc *TransactionContainer) LogWriter(segmentID string) io.WriteCloser {
+	return &logWriter{
+		tc:        tc,
+		segmentID: segmentID,
+	}
+}
+
+// Write buffers p and logs every complete line
+func (lw *logWriter) Write(p []byte) (int, error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+
+	lw.buf.Write(p)
+
+	for {
+		i := bytes.IndexByte(lw.buf.Bytes(), '\n')
+		if i < 0 {
+			break
+		}
+
+		line := string(bytes.TrimSuffix(lw.buf.Next(i + 1)[:i], []byte{'\r'}))
+		lw.tc.InfoString(lw.segmentID, line)
+	}
+
+	return len(p), nil
+}
+
+// Close logs the buffered partial line
+func (lw *logWriter) Close() error {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+
+	if lw.buf.Len() > 0 {
+		lw.tc.InfoString(lw.segmentID, lw.buf.String())
+		lw.buf.Reset()
+	}
+
+	return nil
+}
@@ -0,0 +1,658 @@
+// Package zipkindriver provides a telemetry driver reporting spans to a Zipkin collector.
+// Traces are represented in the B3 single header format
+package zipkindriver
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/openzipkin/zipkin-go"
+	"github.com/openzipkin/zipkin-go/model"
+	"github.com/openzipkin/zipkin-go/propagation/b3"
+	"github.com/openzipkin/zipkin-go/reporter"
+	reporterhttp "github.com/openzipkin/zipkin-go/reporter/http"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+)
+
+// B3 headers used by Zipkin instrumented services to propagate the trace
+const (
+	SingleHeader  = "b3"
+	TraceIDHeader = "X-B3-TraceId"
+	SpanIDHeader  = "X-B3-SpanId"
+	SampledHeader = "X-B3-Sampled"
+)
+
+// Span tags set by the driver
+const (
+	TagProcessID  = "mc.process_id"
+	TagTrace      = "mc.trace"
+	TagTraceID    = "mc.trace_id"
+	TagLinkPrefix = "link."
+)
+
+// ErrInvalidTrace is returned if a trace is not in the B3 single header format
+var ErrInvalidTrace = errors.New("invalid b3 trace")
+
+// Driver reports the spans of its transactions to a Zipkin collector
+type Driver struct {
+	tracer   *zipkin.Tracer
+	reporter reporter.Reporter
+}
+
+// transaction maps a telemetry transaction to a root span and its segments to child spans
+type transaction struct {
+	mu     sync.Mutex
+	tracer *zipkin.Tracer
+	name   string
+	span   zipkin.Span
+	// tags of the root span are retained to restart it as child of a remote trace
+	tags      map[string]string
+	segments  map[string]zipkin.Span
+	segmented bool
+	links     int
+	trace     string
+	traceID   string
+	processID string
+}
+
+// New returns a *Driver reporting the spans of localEndpointName to the collector URL,
+// e.g. http://localhost:9411/api/v2/spans
+func New(collectorURL string, localEndpointName string) (telemetry.Driver, error) {
+	return NewWithReporter(reporterhttp.NewReporter(collectorURL), localEndpointName)
+}
+
+// NewWithReporter returns a *Driver reporting the spans of localEndpointName to rep, e.g. a Kafka reporter
+// or the recorder of the zipkin-go reporter/recorder package in tests. The reporter is closed on errors
+func NewWithReporter(rep reporter.Reporter, localEndpointName string) (telemetry.Driver, error) {
+	return newDriver(rep, localEndpointName)
+}
+
+// Factory creates a driver for telemetry.RegisterDriverFactory reporting the spans of the configured service name
+// to the configured collector URL. A sample rate below 1 samples the fraction of new traces, the sampling decision
+// of an extracted trace is kept
+func Factory(settings telemetry.DriverSettings) (telemetry.Driver, error) {
+	rep := reporterhttp.NewReporter(settings.Endpoint)
+	if settings.SampleRate >= 1 {
+		return newDriver(rep, settings.ServiceName)
+	}
+
+	sampler, err := zipkin.NewBoundarySampler(settings.SampleRate, time.Now().UnixNano())
+	if err != nil {
+		rep.Close()
+		return nil, err
+	}
+
+	return newDriver(rep, settings.ServiceName, zipkin.WithSampler(sampler))
+}
+
+// newDriver returns a *Driver reporting the spans of localEndpointName to rep with the additional tracer options.
+// The reporter is closed on errors
+func newDriver(rep reporter.Reporter, localEndpointName string, opts ...zipkin.TracerOption) (telemetry.Driver, error) {
+	endpoint, err := zipkin.NewEndpoint(localEndpointName, "")
+	if err != nil {
+		rep.Close()
+		return nil, err
+	}
+
+	opts = append([]zipkin.TracerOption{zipkin.WithLocalEndpoint(endpoint), zipkin.WithTraceID128Bit(true)}, opts...)
+
+	tracer, err := zipkin.NewTracer(rep, opts...)
+	if err != nil {
+		rep.Close()
+		return nil, err
+	}
+
+	return &Driver{
+		tracer:   tracer,
+		reporter: rep,
+	}, nil
+}
+
+// Close reports all pending spans and stops the reporter
+func (d *Driver) Close() error {
+	return d.reporter.Close()
+}
+
+// InitializeTransaction starts the root span of the transaction
+func (d *Driver) InitializeTransaction(name string) (telemetry.Transaction, error) {
+	return &transaction{
+		tracer:   d.tracer,
+		name:     name,
+		span:     d.tracer.StartSpan(name),
+		tags:     make(map[string]string),
+		segments: make(map[string]zipkin.Span),
+	}, nil
+}
+
+// Start renames the root span
+func (t *transaction) Start(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.name = name
+	t.span.SetName(name)
+}
+
+// SetName renames the root span
+func (t *transaction) SetName(name string) error {
+	t.Start(name)
+
+	return nil
+}
+
+// AddTransactionAttribute sets the attribute as tag of the root span
+func (t *transaction) AddTransactionAttribute(key string, value any) error {
+	t.tag(key, tagValue(value))
+
+	return nil
+}
+
+// SegmentStart starts a child span of the root span
+func (t *transaction) SegmentStart(segmentID string, name string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.segmented = true
+	t.segments[segmentID] = t.tracer.StartSpan(name, zipkin.Parent(t.span.Context()))
+
+	return nil
+}
+
+// SegmentStartChild starts a child span of the parent segment span
+func (t *transaction) SegmentStartChild(parentID string, segmentID string, name string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	parent, ok := t.segments[parentID]
+	if !ok {
+		return fmt.Errorf("segment %s not found", parentID)
+	}
+
+	t.segmented = true
+	t.segments[segmentID] = t.tracer.StartSpan(name, zipkin.Parent(parent.Context()))
+
+	return nil
+}
+
+// AddSegmentAttribute sets the attribute as tag of the segment span
+func (t *transaction) AddSegmentAttribute(segmentID string, key string, value any) error {
+	span, err := t.segmentSpan(segmentID)
+	if err != nil {
+		return err
+	}
+
+	span.Tag(key, tagValue(value))
+
+	return nil
+}
+
+// AddSegmentAttributes sets all attributes as tags of the segment span
+func (t *transaction) AddSegmentAttributes(segmentID string, attributes map[string]any) error {
+	span, err := t.segmentSpan(segmentID)
+	if err != nil {
+		return err
+	}
+
+	for key, value := range attributes {
+		span.Tag(key, tagValue(value))
+	}
+
+	return nil
+}
+
+// AddSegmentEvent adds the event with the attributes as annotation to the segment span
+func (t *transaction) AddSegmentEvent(segmentID string, name string, attributes map[string]any) error {
+	span, err := t.segmentSpan(segmentID)
+	if err != nil {
+		return err
+	}
+
+	value := name
+	if len(attributes) > 0 {
+		value += ": " + formatFields(attributes)
+	}
+
+	span.Annotate(time.Now(), value)
+
+	return nil
+}
+
+// SegmentEnd finishes the segment span
+func (t *transaction) SegmentEnd(segmentID string) error {
+	return t.SegmentEndWithStatus(segmentID, telemetry.StatusOK)
+}
+
+// SegmentEndWithStatus finishes the segment span and sets the error tag if the status is not StatusOK.
+// A span with StatusDropped is discarded without finishing it, so it is never reported
+func (t *transaction) SegmentEndWithStatus(segmentID string, status telemetry.SegmentStatus) error {
+	t.mu.Lock()
+	span, ok := t.segments[segmentID]
+	delete(t.segments, segmentID)
+	t.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("segment %s not found", segmentID)
+	}
+
+	if status == telemetry.StatusDropped {
+		return nil
+	}
+
+	if status != telemetry.StatusOK {
+		zipkin.TagError.Set(span, status.String())
+	}
+
+	span.Finish()
+
+	return nil
+}
+
+// AddLink sets the linked trace and its attributes as tags of the root span, e.g. link.1.trace.
+// Zipkin does not support span links
+func (t *transaction) AddLink(trace string, attributes map[string]any) error {
+	t.mu.Lock()
+	t.links++
+	prefix := fmt.Sprintf("%s%d.", TagLinkPrefix, t.links)
+	t.mu.Unlock()
+
+	t.tag(prefix+"trace", trace)
+	for key, value := range attributes {
+		t.tag(prefix+key, tagValue(value))
+	}
+
+	return nil
+}
+
+// SetStatus sets the error tag of the root span for TransactionStatusError.
+// Zipkin has no ok status, so TransactionStatusOK leaves the span unchanged
+func (t *transaction) SetStatus(status telemetry.TransactionStatus) error {
+	if status == telemetry.TransactionStatusOK {
+		return nil
+	}
+
+	t.tag(string(zipkin.TagError), status.String())
+
+	return nil
+}
+
+// Flush ...
+func (t *transaction) Flush() error {
+	return nil
+}
+
+// Done finishes all open segment spans and the root span.
+// The finished spans are sent in batches by the reporter, Close of the driver reports them immediately
+func (t *transaction) Done() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, span := range t.segments {
+		span.Finish()
+	}
+
+	t.span.Finish()
+
+	return nil
+}
+
+// Info adds the message as annotation to the span
+func (t *transaction) Info(segmentID string, rc io.ReadCloser) error {
+	return t.log("info", segmentID, rc)
+}
+
+// Warn adds the message as annotation to the span
+func (t *transaction) Warn(segmentID string, rc io.ReadCloser) error {
+	return t.log("warn", segmentID, rc)
+}
+
+// Debug adds the message as annotation to the span
+func (t *transaction) Debug(segmentID string, rc io.ReadCloser) error {
+	return t.log("debug", segmentID, rc)
+}
+
+// Error adds the message as annotation to the span and sets the error tag
+func (t *transaction) Error(segmentID string, rc io.ReadCloser) error {
+	defer rc.Close()
+
+	msg, err := io.ReadAll(rc)
+	if err != nil {
+		return err
+	}
+
+	span, err := t.logSpan(segmentID)
+	if err != nil {
+		return err
+	}
+
+	span.Annotate(time.Now(), "error: "+string(msg))
+	zipkin.TagError.Set(span, string(msg))
+
+	return nil
+}
+
+// InfoFields adds the fields as annotation to the span
+func (t *transaction) InfoFields(segmentID string, fields map[string]any) error {
+	span, err := t.logSpan(segmentID)
+	if err != nil {
+		return err
+	}
+
+	span.Annotate(time.Now(), "info: "+formatFields(fields))
+
+	return nil
+}
+
+// ErrorFields adds the fields as annotation to the span and sets the error tag
+func (t *transaction) ErrorFields(segmentID string, fields map[string]any) error {
+	span, err := t.logSpan(segmentID)
+	if err != nil {
+		return err
+	}
+
+	span.Annotate(time.Now(), "error: "+formatFields(fields))
+	zipkin.TagError.Set(span, fmt.Sprint(fields[telemetry.FieldError]))
+
+	return nil
+}
+
+// CreateTrace returns the root span in the B3 single header format
+func (t *transaction) CreateTrace() (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return b3.BuildSingleHeader(t.span.Context()), nil
+}
+
+// SetTrace sets the trace in the B3 single header format. Its trace id is passed on to the other drivers.
+// If no segment was started yet the root span is restarted as child of the span of the trace,
+// otherwise the trace is added as tag
+func (t *transaction) SetTrace(trace string) error {
+	sc, err := parseSpanContext(trace)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.segmented {
+		t.span.Tag(TagTrace, trace)
+	} else {
+		t.span = t.tracer.StartSpan(t.name, zipkin.Parent(sc), zipkin.Tags(t.tags))
+	}
+
+	t.trace = trace
+	t.traceID = sc.TraceID.String()
+
+	return nil
+}
+
+// Trace returns the trace set with SetTrace or the root span in the B3 single header format
+func (t *transaction) Trace() (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.trace == "" {
+		return b3.BuildSingleHeader(t.span.Context()), nil
+	}
+
+	return t.trace, nil
+}
+
+// TraceID returns the trace id set with SetTrace or SetTraceID or the Zipkin trace id
+func (t *transaction) TraceID() (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.traceID == "" {
+		return t.span.Context().TraceID.String(), nil
+	}
+
+	return t.traceID, nil
+}
+
+// SetTraceID sets the trace id of the trace driver as tag of the root span
+func (t *transaction) SetTraceID(traceID string) error {
+	t.mu.Lock()
+	t.traceID = traceID
+	t.mu.Unlock()
+
+	t.tag(TagTraceID, traceID)
+
+	return nil
+}
+
+// Erase removes all segments
+func (t *transaction) Erase() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	clear(t.segments)
+}
+
+// CreateProcessID creates a new random process id
+func (t *transaction) CreateProcessID() (string, error) {
+	return uuid.NewString(), nil
+}
+
+// SetProcessID sets the process id as tag of the root span
+func (t *transaction) SetProcessID(processID string) error {
+	t.mu.Lock()
+	t.processID = processID
+	t.mu.Unlock()
+
+	t.tag(TagProcessID, processID)
+
+	return nil
+}
+
+// ProcessID returns the process id
+func (t *transaction) ProcessID() (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.processID, nil
+}
+
+// SpanID returns the span id of the segment span, or of the root span for an empty segmentID
+func (t *transaction) SpanID(segmentID string) (string, error) {
+	span, err := t.logSpan(segmentID)
+	if err != nil {
+		return "", err
+	}
+
+	return span.Context().ID.String(), nil
+}
+
+// tag sets and retains a tag of the root span
+func (t *transaction) tag(key string, value string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.tags[key] = value
+	t.span.Tag(key, value)
+}
+
+// log drains the message, already limited by the container, and adds it as annotation to the span
+func (t *transaction) log(level string, segmentID string, rc io.ReadCloser) error {
+	defer rc.Close()
+
+	msg, err := io.ReadAll(rc)
+	if err != nil {
+		return err
+	}
+
+	span, err := t.logSpan(segmentID)
+	if err != nil {
+		return err
+	}
+
+	span.Annotate(time.Now(), level+": "+string(msg))
+
+	return nil
+}
+
+// segmentSpan returns the span of the segment
+func (t *transaction) segmentSpan(segmentID string) (zipkin.Span, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	span, ok := t.segments[segmentID]
+	if !ok {
+		return nil, fmt.Errorf("segment %s not found", segmentID)
+	}
+
+	return span, nil
+}
+
+// logSpan returns the root span for an empty segmentID and the segment span otherwise
+func (t *transaction) logSpan(segmentID string) (zipkin.Span, error) {
+	if segmentID == "" {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		return t.span, nil
+	}
+
+	return t.segmentSpan(segmentID)
+}
+
+// FormatTrace returns the trace in the B3 single header format {trace-id}-{span-id}-{sampled}
+func FormatTrace(traceID string, spanID string, sampled bool) string {
+	flag := "0"
+	if sampled {
+		flag = "1"
+	}
+
+	return fmt.Sprintf("%s-%s-%s", traceID, spanID, flag)
+}
+
+// ParseTrace splits a trace in the B3 single header format.
+// A missing sampling decision is reported as sampled
+func ParseTrace(trace string) (traceID string, spanID string, sampled bool, err error) {
+	sc, err := parseSpanContext(trace)
+	if err != nil {
+		return "", "", false, err
+	}
+
+	return sc.TraceID.String(), sc.ID.String(), sc.Sampled == nil || *sc.Sampled || sc.Debug, nil
+}
+
+// InjectHTTP sets the trace of the transaction as B3 single header
+func InjectHTTP(h http.Header, tc *telemetry.TransactionContainer) error {
+	trace, err := tc.Trace()
+	if err != nil {
+		return err
+	}
+
+	_, err = parseSpanContext(trace)
+	if err != nil {
+		return err
+	}
+
+	h.Set(SingleHeader, trace)
+
+	return nil
+}
+
+// InjectHTTPMulti sets the trace of the transaction as X-B3-TraceId, X-B3-SpanId and X-B3-Sampled headers
+func InjectHTTPMulti(h http.Header, tc *telemetry.TransactionContainer) error {
+	trace, err := tc.Trace()
+	if err != nil {
+		return err
+	}
+
+	traceID, spanID, sampled, err := ParseTrace(trace)
+	if err != nil {
+		return err
+	}
+
+	h.Set(TraceIDHeader, traceID)
+	h.Set(SpanIDHeader, spanID)
+	h.Set(SampledHeader, "0")
+	if sampled {
+		h.Set(SampledHeader, "1")
+	}
+
+	return nil
+}
+
+// ExtractHTTP returns the trace of the B3 single header or the X-B3-* headers in the B3 single header format
+// to be used with SetTrace. It returns telemetry.ErrNoTraceContext if the headers are missing
+func ExtractHTTP(h http.Header) (string, error) {
+	if trace := h.Get(SingleHeader); trace != "" {
+		_, err := parseSpanContext(trace)
+		if err != nil {
+			return "", err
+		}
+
+		return trace, nil
+	}
+
+	if h.Get(TraceIDHeader) == "" && h.Get(SpanIDHeader) == "" {
+		return "", telemetry.ErrNoTraceContext
+	}
+
+	sc, err := b3.ParseHeaders(h.Get(TraceIDHeader), h.Get(SpanIDHeader), "", h.Get(SampledHeader), "")
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidTrace, err)
+	}
+
+	return b3.BuildSingleHeader(*sc), nil
+}
+
+// parseSpanContext parses a trace in the B3 single header format which contains a trace and span id
+func parseSpanContext(trace string) (model.SpanContext, error) {
+	sc, err := b3.ParseSingleHeader(trace)
+	if err != nil {
+		return model.SpanContext{}, fmt.Errorf("%w: %v", ErrInvalidTrace, err)
+	}
+
+	if sc.TraceID.Empty() || sc.ID == 0 {
+		return model.SpanContext{}, ErrInvalidTrace
+	}
+
+	return *sc, nil
+}
+
+// formatFields joins the fields sorted by key, e.g. key1=value1 key2=value2
+func formatFields(fields map[string]any) string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for i, key := range keys {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+
+		fmt.Fprintf(&sb, "%s=%s", key, tagValue(fields[key]))
+	}
+
+	return sb.String()
+}
+
+// tagValue converts a value into the string representation of a Zipkin tag
+func tagValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case time.Duration:
+		return v.String()
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+
+	return fmt.Sprint(value)
+}
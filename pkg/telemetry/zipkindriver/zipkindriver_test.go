@@ -0,0 +1,147 @@
+package zipkindriver_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/openzipkin/zipkin-go/model"
+	"github.com/openzipkin/zipkin-go/reporter/recorder"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry"
+	"github.com/plentymarkets/mc-telemetry/pkg/telemetry/zipkindriver"
+)
+
+// newTelemetry returns a telemetry instance with a driver reporting to an in-memory recorder as driver and trace driver
+func newTelemetry(t *testing.T) (*telemetry.Telemetry, *recorder.ReporterRecorder) {
+	t.Helper()
+
+	rep := recorder.NewReporter()
+	driver, err := zipkindriver.NewWithReporter(rep, "checkout-service")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = driver.(*zipkindriver.Driver).Close() })
+
+	tel := telemetry.New()
+	err = tel.RegisterDriver("zipkin", driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tel.SetDriver("zipkin")
+	tel.SetTraceDriver("zipkin")
+
+	return tel, rep
+}
+
+// reportedSpans returns the reported spans by name
+func reportedSpans(rep *recorder.ReporterRecorder) map[string]model.SpanModel {
+	spans := make(map[string]model.SpanModel)
+	for _, span := range rep.Flush() {
+		spans[span.Name] = span
+	}
+
+	return spans
+}
+
+func TestSegmentsBecomeSpans(t *testing.T) {
+	tel, rep := newTelemetry(t)
+
+	transaction, err := tel.Start("checkout")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transaction.AddTransactionAttribute("customer.id", 7)
+
+	segmentID := transaction.SegmentStart("load cart")
+	transaction.AddSegmentAttribute(segmentID, "cart.items", 3)
+
+	childID, err := transaction.SegmentStartChild(segmentID, "query")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transaction.SegmentEnd(childID)
+	transaction.SegmentEnd(segmentID)
+
+	err = transaction.Done()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spans := reportedSpans(rep)
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 reported spans, got %d", len(spans))
+	}
+
+	root, segment, child := spans["checkout"], spans["load cart"], spans["query"]
+	if segment.ParentID == nil || *segment.ParentID != root.ID || child.ParentID == nil || *child.ParentID != segment.ID {
+		t.Error("expected the segment spans to be children of their parents")
+	}
+
+	if segment.TraceID != root.TraceID || root.TraceID.Empty() || root.TraceID.High == 0 {
+		t.Errorf("expected all spans in one 128 bit trace, got %s and %s", root.TraceID, segment.TraceID)
+	}
+
+	if root.Tags["customer.id"] != "7" || segment.Tags["cart.items"] != "3" {
+		t.Errorf("expected the attributes as tags, got %v and %v", root.Tags, segment.Tags)
+	}
+
+	if root.LocalEndpoint == nil || root.LocalEndpoint.ServiceName != "checkout-service" {
+		t.Errorf("expected the local endpoint checkout-service, got %v", root.LocalEndpoint)
+	}
+
+	if _, ok := segment.Tags["error"]; ok {
+		t.Errorf("expected no error tag, got %v", segment.Tags)
+	}
+}
+
+func TestErrorsSetTag(t *testing.T) {
+	tel, rep := newTelemetry(t)
+
+	transaction, err := tel.Start("checkout")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failedID := transaction.SegmentStart("payment")
+	err = transaction.SegmentEndWithStatus(failedID, telemetry.StatusError)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	droppedID := transaction.SegmentStart("cache")
+	err = transaction.SegmentEndWithStatus(droppedID, telemetry.StatusDropped)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loggedID := transaction.SegmentStart("shipping")
+	segmentErr := errors.New("carrier unavailable")
+	transaction.Error(loggedID, &segmentErr)
+	transaction.SegmentEnd(loggedID)
+
+	err = transaction.Done()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spans := reportedSpans(rep)
+
+	if spans["payment"].Tags["error"] != telemetry.StatusError.String() {
+		t.Errorf("expected StatusError to set the error tag, got %v", spans["payment"].Tags)
+	}
+
+	if _, ok := spans["cache"]; ok {
+		t.Error("expected a dropped segment not to be reported")
+	}
+
+	shipping := spans["shipping"]
+	if shipping.Tags["error"] != "carrier unavailable" {
+		t.Errorf("expected the logged error as error tag, got %v", shipping.Tags)
+	}
+
+	if len(shipping.Annotations) != 1 || shipping.Annotations[0].Value != "error: carrier unavailable" {
+		t.Errorf("expected the logged error as annotation, got %v", shipping.Annotations)
+	}
+}